@@ -0,0 +1,258 @@
+package envx
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// AdminOption configures AdminHandler.
+type AdminOption func(*adminOptions)
+
+type adminOptions struct {
+	authorize func(*http.Request) (user string, ok bool)
+}
+
+// AdminAuthorize enables edits for requests fn accepts. The user it returns
+// is recorded as the Actor of the override event. Without it the console
+// is read-only.
+func AdminAuthorize(fn func(r *http.Request) (user string, ok bool)) AdminOption {
+	return func(o *adminOptions) {
+		o.authorize = fn
+	}
+}
+
+// AdminHandler serves a minimal config console for l: the current
+// configuration, secrets masked, grouped by group tag or section, with the
+// recent override events. With AdminAuthorize, a field can be set through
+// Override and all overrides cleared, each edit logged with its user.
+// Viewing is not authorized by the handler, so mount it behind the
+// application's own access control.
+func AdminHandler[T any](l *Loader[T], opts ...AdminOption) http.Handler {
+	var o adminOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			renderAdmin(w, l, o, "", http.StatusOK)
+		case http.MethodPost:
+			handleAdminEdit(w, r, l, o)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func handleAdminEdit[T any](w http.ResponseWriter, r *http.Request, l *Loader[T], o adminOptions) {
+	if o.authorize == nil {
+		http.Error(w, "envx: admin console is read-only", http.StatusForbidden)
+		return
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "envx: cross-origin request rejected", http.StatusForbidden)
+		return
+	}
+	user, ok := o.authorize(r)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch r.PostForm.Get("action") {
+	case "clear":
+		err = l.clearOverrides(user)
+	case "set":
+		key := r.PostForm.Get("key")
+		if !adminKnownKey(l, key) {
+			http.Error(w, "envx: unknown key "+key, http.StatusBadRequest)
+			return
+		}
+		err = l.override(user, key, r.PostForm.Get("value"))
+	default:
+		http.Error(w, "envx: unknown action", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		renderAdmin(w, l, o, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+}
+
+// sameOrigin rejects form posts from other sites, which browsers flag with
+// Sec-Fetch-Site or an Origin header naming another host.
+func sameOrigin(r *http.Request) bool {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" && site != "same-origin" && site != "none" {
+		return false
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		return err == nil && u.Host == r.Host
+	}
+	return true
+}
+
+func adminKnownKey[T any](l *Loader[T], key string) bool {
+	for _, d := range Docs[T](l.baseOptions()...) {
+		if d.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// baseOptions returns the options the Loader was created with.
+func (l *Loader[T]) baseOptions() []Option {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]Option(nil), l.opts...)
+}
+
+type adminPage struct {
+	Version     int64
+	Fingerprint string
+	Loaded      bool
+	Editable    bool
+	Error       string
+	Groups      []adminGroup
+	Overrides   int
+	Events      []Event
+}
+
+type adminGroup struct {
+	Name   string
+	Fields []adminField
+}
+
+type adminField struct {
+	FieldDoc
+	Value      string
+	Overridden bool
+}
+
+const adminEventCount = 20
+
+func renderAdmin[T any](w http.ResponseWriter, l *Loader[T], o adminOptions, errMsg string, status int) {
+	opts := l.baseOptions()
+	prefix := prepareOptions[T](opts).prefix
+	snap := l.Pin()
+	overrides := l.Overrides()
+
+	page := adminPage{
+		Version:   snap.Version(),
+		Loaded:    snap.Config() != nil,
+		Editable:  o.authorize != nil,
+		Error:     errMsg,
+		Overrides: len(overrides),
+	}
+
+	values := make(map[string]string)
+	if cfg := snap.Config(); cfg != nil {
+		page.Fingerprint = Fingerprint(cfg)
+		v := reflect.ValueOf(cfg).Elem()
+		if v.Kind() == reflect.Struct {
+			for _, kv := range flattenConfig(v, v.Type(), "") {
+				key := kv.key
+				if prefix != "" {
+					key = prefix + "_" + key
+				}
+				if isSecret(kv.field) && kv.value != "" {
+					kv.value = maskSecretValue(kv.value)
+				}
+				values[key] = kv.value
+			}
+		}
+	}
+
+	index := make(map[string]int)
+	for _, d := range Docs[T](opts...) {
+		name := adminGroupName(d)
+		i, ok := index[name]
+		if !ok {
+			i = len(page.Groups)
+			index[name] = i
+			page.Groups = append(page.Groups, adminGroup{Name: name})
+		}
+		_, overridden := overrides[d.Key]
+		page.Groups[i].Fields = append(page.Groups[i].Fields, adminField{FieldDoc: d, Value: values[d.Key], Overridden: overridden})
+	}
+
+	events := l.Events()
+	for i := len(events) - 1; i >= 0 && len(page.Events) < adminEventCount; i-- {
+		if events[i].Kind == EventOverride {
+			page.Events = append(page.Events, events[i])
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	adminTemplate.Execute(w, page)
+}
+
+// adminGroupName is the group tag, or else the section the field is in.
+func adminGroupName(d FieldDoc) string {
+	if d.Group != "" {
+		return d.Group
+	}
+	if i := strings.LastIndex(d.Field, "."); i >= 0 {
+		return d.Field[:i]
+	}
+	return "General"
+}
+
+var adminTemplate = template.Must(template.New("admin").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Configuration</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { border-bottom: 1px solid #ddd; padding: .4em; text-align: left; vertical-align: top; }
+.error { color: #b00; }
+.badge { background: #fd3; border-radius: 3px; padding: 0 .3em; font-size: 80%; }
+.muted { color: #777; }
+</style>
+</head>
+<body>
+<h1>Configuration</h1>
+{{if .Loaded}}<p class="muted">Version {{.Version}} &middot; fingerprint <code>{{.Fingerprint}}</code></p>{{else}}<p class="error">Not loaded yet.</p>{{end}}
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{range .Groups}}
+<h2>{{.Name}}</h2>
+<table>
+<tr><th>Variable</th><th>Value</th><th>Default</th><th>Description</th>{{if $.Editable}}<th>Override</th>{{end}}</tr>
+{{range .Fields}}<tr>
+<td><code>{{.Key}}</code>{{if .Overridden}} <span class="badge">override</span>{{end}}{{if .Secret}} <span class="muted">secret</span>{{end}}</td>
+<td><code>{{.Value}}</code></td>
+<td><code>{{.Default}}</code></td>
+<td>{{.Description}}</td>
+{{if $.Editable}}<td><form method="post"><input type="hidden" name="action" value="set"><input type="hidden" name="key" value="{{.Key}}"><input name="value" {{if .Secret}}type="password" autocomplete="off"{{end}}> <button>Set</button></form></td>{{end}}
+</tr>
+{{end}}</table>
+{{end}}
+{{if and .Editable .Overrides}}<form method="post"><input type="hidden" name="action" value="clear"><button>Clear {{.Overrides}} override(s)</button></form>{{end}}
+<h2>Recent overrides</h2>
+{{if .Events}}<table>
+<tr><th>Time</th><th>User</th><th>Version</th><th>Changes</th></tr>
+{{range .Events}}<tr>
+<td>{{.Time.Format "2006-01-02 15:04:05 MST"}}</td>
+<td>{{.Actor}}</td>
+<td>{{.Version}}</td>
+<td>{{if .Err}}<span class="error">{{.Err}}</span>{{else}}{{range .Changes}}<code>{{.Key}}</code>: {{.Old}} &rarr; {{.New}}<br>{{else}}<span class="muted">no change</span>{{end}}{{end}}</td>
+</tr>
+{{end}}</table>{{else}}<p class="muted">None.</p>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,116 @@
+package envx
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Change is one field that differs between two configurations. Old or New
+// is empty when the field is missing on that side (e.g. a nil section), and
+// secret values are masked.
+type Change struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// WithApproval stages reloaded configurations instead of applying them:
+// Loader.Pending lists what would change and Loader.Approve applies it, so a
+// human or a policy engine can sign off on sensitive settings. A newer
+// change replaces the staged one. The initial Load is not gated.
+func WithApproval() Option {
+	return func(o *options) {
+		o.approval = true
+	}
+}
+
+// Pending returns the changes of the staged configuration relative to the
+// current one, or nil if nothing awaits approval.
+func (l *Loader[T]) Pending() []Change {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.pending == nil {
+		return nil
+	}
+	return diffConfigs(l.config, l.pending)
+}
+
+// Approve applies the staged configuration, as a reload would, and reports
+// whether there was one.
+func (l *Loader[T]) Approve() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.pending == nil {
+		return false
+	}
+	oldConfig, newConfig := l.config, l.pending
+	l.pending = nil
+
+	l.config = newConfig
+	l.sources = l.pendingSources
+	l.removalState = l.pendingRemovals
+	l.version++
+	l.recordEvent(EventApproved, diffConfigs(oldConfig, newConfig), nil)
+	l.triggerOnReload(prepareOptions[T](l.opts), oldConfig, newConfig)
+	return true
+}
+
+// Reject discards the staged configuration and reports whether there was
+// one. The same change is staged again only if a source changes again.
+func (l *Loader[T]) Reject() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.pending == nil {
+		return false
+	}
+	l.recordEvent(EventRejected, diffConfigs(l.config, l.pending), nil)
+	l.pending = nil
+	return true
+}
+
+func diffConfigs[T any](old, new *T) []Change {
+	flatten := func(cfg *T) map[string]keyValue {
+		out := make(map[string]keyValue)
+		if cfg == nil {
+			return out
+		}
+		v := reflect.ValueOf(cfg).Elem()
+		for _, kv := range flattenConfig(v, v.Type(), "") {
+			out[kv.key] = kv
+		}
+		return out
+	}
+	before, after := flatten(old), flatten(new)
+
+	keys := make(map[string]bool)
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+
+	var changes []Change
+	for k := range keys {
+		o, n := before[k], after[k]
+		if o.value == n.value {
+			continue
+		}
+		if isSecret(o.field) || isSecret(n.field) {
+			o.value, n.value = maskChange(o.value), maskChange(n.value)
+		}
+		changes = append(changes, Change{Key: k, Old: o.value, New: n.value})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+func maskChange(val string) string {
+	if val == "" {
+		return ""
+	}
+	return maskSecretValue(val)
+}
@@ -0,0 +1,179 @@
+//go:build !envx_minimal && !envx_no_secretsmanager
+
+package envx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type awsCredentials struct {
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	expires      time.Time
+}
+
+// awsCredentialChain looks credentials up the way the AWS SDKs do, minus
+// shared config files: the environment, then the ECS container endpoint,
+// then the EC2 instance role through IMDSv2. The environment is read on
+// every call so rotated keys are picked up; credentials from an endpoint
+// are cached until shortly before they expire.
+type awsCredentialChain struct {
+	client *http.Client
+
+	mu     sync.Mutex
+	cached awsCredentials
+}
+
+// awsCredentialRefresh is how long before expiry cached credentials are
+// fetched again.
+const awsCredentialRefresh = 5 * time.Minute
+
+func (c *awsCredentialChain) retrieve(ctx context.Context) (awsCredentials, error) {
+	if key, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); key != "" && secret != "" {
+		return awsCredentials{accessKey: key, secretKey: secret, sessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached.accessKey != "" && time.Until(c.cached.expires) > awsCredentialRefresh {
+		return c.cached, nil
+	}
+
+	var (
+		creds awsCredentials
+		err   error
+	)
+	switch {
+	case os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI") != "":
+		creds, err = c.container(ctx, "http://169.254.170.2"+os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"))
+	case os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI") != "":
+		creds, err = c.container(ctx, os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI"))
+	case strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true"):
+		err = errors.New("no credentials in the environment and the instance metadata service is disabled")
+	default:
+		creds, err = c.instance(ctx)
+	}
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("aws credentials: %w", err)
+	}
+	c.cached = creds
+	return creds, nil
+}
+
+// container reads credentials from the ECS or EKS Pod Identity endpoint.
+func (c *awsCredentialChain) container(ctx context.Context, endpoint string) (awsCredentials, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if path := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return awsCredentials{}, err
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	data, err := c.get(req)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("container endpoint: %w", err)
+	}
+	return parseAWSCredentials(data)
+}
+
+// instance reads the instance role's credentials through IMDSv2.
+// AWS_EC2_METADATA_SERVICE_ENDPOINT overrides the metadata address.
+func (c *awsCredentialChain) instance(ctx context.Context) (awsCredentials, error) {
+	endpoint := os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "http://169.254.169.254"
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+	token, err := c.get(req)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("instance metadata: %w", err)
+	}
+
+	fetch := func(path string) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", endpoint+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-aws-ec2-metadata-token", string(token))
+		data, err := c.get(req)
+		if err != nil {
+			return nil, fmt.Errorf("instance metadata: %w", err)
+		}
+		return data, nil
+	}
+	const rolePath = "/latest/meta-data/iam/security-credentials/"
+	roles, err := fetch(rolePath)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	role, _, _ := strings.Cut(strings.TrimSpace(string(roles)), "\n")
+	if role == "" {
+		return awsCredentials{}, errors.New("instance metadata: no instance role")
+	}
+	data, err := fetch(rolePath + role)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	return parseAWSCredentials(data)
+}
+
+func (c *awsCredentialChain) get(req *http.Request) ([]byte, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return data, nil
+}
+
+func parseAWSCredentials(data []byte) (awsCredentials, error) {
+	var out struct {
+		AccessKeyID     string    `json:"AccessKeyId"`
+		SecretAccessKey string    `json:"SecretAccessKey"`
+		Token           string    `json:"Token"`
+		Expiration      time.Time `json:"Expiration"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return awsCredentials{}, err
+	}
+	if out.AccessKeyID == "" || out.SecretAccessKey == "" {
+		return awsCredentials{}, errors.New("response has no access key")
+	}
+	return awsCredentials{
+		accessKey:    out.AccessKeyID,
+		secretKey:    out.SecretAccessKey,
+		sessionToken: out.Token,
+		expires:      out.Expiration,
+	}, nil
+}
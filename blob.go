@@ -0,0 +1,246 @@
+//go:build !envx_minimal && !envx_no_blob
+
+package envx
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+func init() { registerSubsystem("blob") }
+
+type blobProvider struct {
+	rawURL    string
+	endpoint  string
+	region    string
+	client    *http.Client
+	authorize func(*http.Request) error
+
+	mu      sync.Mutex
+	version blobVersion
+}
+
+// blobVersion identifies the object last loaded: by ETag, by Last-Modified
+// when the store sends no ETag, and by a content hash when it sends
+// neither.
+type blobVersion struct {
+	etag         string
+	lastModified string
+	sum          [sha256.Size]byte
+}
+
+// BlobOption configures a Blob provider.
+type BlobOption func(*blobProvider)
+
+// BlobAuthorizer sets a hook that signs each request before it is sent, e.g.
+// with AWS SigV4, a GCS OAuth token or an Azure SAS query. Without one,
+// objects must be publicly readable or the URL pre-signed.
+func BlobAuthorizer(fn func(*http.Request) error) BlobOption {
+	return func(p *blobProvider) {
+		p.authorize = fn
+	}
+}
+
+// BlobHTTPClient sets the HTTP client used to fetch objects. The default
+// client times out after 30 seconds.
+func BlobHTTPClient(c *http.Client) BlobOption {
+	return func(p *blobProvider) {
+		p.client = c
+	}
+}
+
+// BlobEndpoint replaces the storage service's base URL, for S3-compatible
+// stores such as MinIO or for emulators. The object path is appended to it.
+func BlobEndpoint(endpoint string) BlobOption {
+	return func(p *blobProvider) {
+		p.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// BlobRegion sets the S3 region, which selects the regional endpoint.
+func BlobRegion(region string) BlobOption {
+	return func(p *blobProvider) {
+		p.region = region
+	}
+}
+
+// Blob returns a provider that reads a config object from cloud storage:
+// s3://bucket/key, gs://bucket/key or az://account/container/blob (https
+// URLs are fetched as is). The object is decoded by its extension like File.
+// Object keys are taken literally and escaped for the request, so they may
+// contain spaces, '#' or '?'. The provider implements ChangeDetector by
+// comparing the object's ETag, or its Last-Modified time or content when
+// the store sends no ETag.
+func Blob(rawURL string, opts ...BlobOption) Provider {
+	p := &blobProvider{rawURL: rawURL, client: &http.Client{Timeout: remoteTimeout}}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *blobProvider) Name() string { return "blob" }
+
+func (p *blobProvider) StrictKeys() bool { return true }
+
+func (p *blobProvider) Describe() ProviderInfo {
+	return ProviderInfo{Source: displayURL(p.rawURL)}
+}
+
+// location splits a storage URL into its scheme, bucket and object key.
+// Unlike url.Parse it keeps the key verbatim, so '#', '?' and '%' in it
+// are part of the object name.
+func (p *blobProvider) location() (scheme, bucket, key string) {
+	scheme, rest, ok := strings.Cut(p.rawURL, "://")
+	if !ok {
+		return "", "", ""
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	return strings.ToLower(scheme), bucket, key
+}
+
+// objectURL maps the storage URL to the service's HTTP endpoint.
+func (p *blobProvider) objectURL() (string, error) {
+	scheme, bucket, key := p.location()
+	if scheme == "http" || scheme == "https" {
+		return p.rawURL, nil
+	}
+	if bucket == "" || key == "" {
+		return "", fmt.Errorf("envx: blob %s: missing bucket or object", p.rawURL)
+	}
+	key = escapeObjectKey(key)
+
+	if p.endpoint != "" {
+		return p.endpoint + "/" + url.PathEscape(bucket) + "/" + key, nil
+	}
+	switch scheme {
+	case "s3":
+		if p.region != "" {
+			return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, p.region, key), nil
+		}
+		return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", bucket, key), nil
+	case "gs":
+		return fmt.Sprintf("https://storage.googleapis.com/%s/%s", bucket, key), nil
+	case "az":
+		return fmt.Sprintf("https://%s.blob.core.windows.net/%s", bucket, key), nil
+	}
+	return "", fmt.Errorf("envx: blob %s: unsupported scheme %q", p.rawURL, scheme)
+}
+
+// escapeObjectKey percent-encodes each segment of an object key, keeping
+// the slashes that separate them.
+func escapeObjectKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+func (p *blobProvider) Values() (map[string]any, error) {
+	return p.ValuesContext(context.Background())
+}
+
+func (p *blobProvider) ValuesContext(ctx context.Context) (map[string]any, error) {
+	resp, err := p.request(ctx, "GET")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("envx: blob %s: %w", p.rawURL, err)
+	}
+	sum := sha256.Sum256(data)
+	var ext string
+	if scheme, _, key := p.location(); scheme != "http" && scheme != "https" {
+		ext = strings.ToLower(path.Ext(key))
+	} else if u, err := url.Parse(p.rawURL); err == nil {
+		ext = strings.ToLower(path.Ext(u.Path))
+	}
+	if err := checkEncoding(data, ext == ".properties"); err != nil {
+		return nil, &Error{Field: p.rawURL, Err: err}
+	}
+	data, _ = normalizeText(data)
+	values, err := decodeFile(data, ext)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.version = blobVersion{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		sum:          sum,
+	}
+	p.mu.Unlock()
+	return values, nil
+}
+
+// Changed reports whether the object differs from the one last loaded. It
+// compares ETags when the store sends them, then Last-Modified; failing
+// both, it downloads the object and compares its hash.
+func (p *blobProvider) Changed() (bool, error) {
+	return p.ChangedContext(context.Background())
+}
+
+func (p *blobProvider) ChangedContext(ctx context.Context) (bool, error) {
+	p.mu.Lock()
+	last := p.version
+	p.mu.Unlock()
+
+	resp, err := p.request(ctx, "HEAD")
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	if etag := resp.Header.Get("ETag"); etag != "" && last.etag != "" {
+		return etag != last.etag, nil
+	}
+	if modified := resp.Header.Get("Last-Modified"); modified != "" && last.lastModified != "" {
+		return modified != last.lastModified, nil
+	}
+
+	resp, err = p.request(ctx, "GET")
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return false, fmt.Errorf("envx: blob %s: %w", p.rawURL, err)
+	}
+	return [sha256.Size]byte(h.Sum(nil)) != last.sum, nil
+}
+
+func (p *blobProvider) request(ctx context.Context, method string) (*http.Response, error) {
+	u, err := p.objectURL()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("envx: blob %s: %w", p.rawURL, err)
+	}
+	if p.authorize != nil {
+		if err := p.authorize(req); err != nil {
+			return nil, fmt.Errorf("envx: blob %s: authorize: %w", p.rawURL, err)
+		}
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("envx: blob %s: %w", p.rawURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("envx: blob %s: unexpected status %s", p.rawURL, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,106 @@
+package envx
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Bootstrap describes where a program's configuration comes from. It is
+// read from ENVX_* variables by LoadBootstrap, so a deployment can switch
+// profiles, files or remote sources without a code change:
+//
+//	boot, err := envx.LoadBootstrap()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	cfg, err := envx.Load[Config](boot.Options()...)
+type Bootstrap struct {
+	// Profile is passed to WithProfile (ENVX_PROFILE).
+	Profile string
+	// Prefix is passed to WithPrefix (ENVX_PREFIX).
+	Prefix string
+	// ConfigFiles are read in order, later files winning
+	// (ENVX_CONFIG_FILES, comma-separated).
+	ConfigFiles []string
+	// HTTPURL adds an HTTP provider above the files (ENVX_HTTP_URL).
+	HTTPURL string `env:"HTTP_URL"`
+	// EtcdEndpoint and EtcdPrefix add an Etcd provider above the files
+	// (ENVX_ETCD_ENDPOINT, ENVX_ETCD_PREFIX).
+	EtcdEndpoint string
+	EtcdPrefix   string
+	// WatchInterval watches the files and remote sources when a Loader
+	// starts watching (ENVX_WATCH_INTERVAL). Zero disables watching.
+	WatchInterval time.Duration
+	// Strict enables WithStrict (ENVX_STRICT).
+	Strict bool
+}
+
+// bootstrapRemotes builds the remote providers a Bootstrap names, keyed by
+// subsystem. Each subsystem registers itself when compiled in.
+var bootstrapRemotes = map[string]func(b *Bootstrap) Provider{}
+
+// LoadBootstrap reads a Bootstrap from the ENVX_* environment variables.
+func LoadBootstrap() (*Bootstrap, error) {
+	return Load[Bootstrap](WithProvider(Env()), WithPrefix("ENVX"), WithoutEnvSettings())
+}
+
+// Options returns the options b describes, in precedence order: struct
+// defaults, the config files, the remote sources, then the environment.
+func (b *Bootstrap) Options() []Option {
+	var opts []Option
+	if b.Profile != "" {
+		opts = append(opts, WithProfile(b.Profile))
+	}
+	if b.Prefix != "" {
+		opts = append(opts, WithPrefix(b.Prefix))
+	}
+	if b.Strict {
+		opts = append(opts, WithStrict())
+	}
+
+	opts = append(opts, WithLayer(targetDefaults{}, LayerDefaults))
+	for _, path := range b.ConfigFiles {
+		opts = append(opts, WithProvider(File(path)))
+		if b.WatchInterval > 0 {
+			opts = append(opts, WithWatch(path, b.WatchInterval))
+		}
+	}
+	if b.HTTPURL != "" {
+		opts = append(opts, b.remote("http", "ENVX_HTTP_URL"))
+	}
+	if b.EtcdEndpoint != "" {
+		opts = append(opts, b.remote("etcd", "ENVX_ETCD_ENDPOINT"))
+	}
+	return append(opts, WithLayer(Env(), LayerEnv))
+}
+
+// remote registers the subsystem's provider, watched if b asks for it, or
+// reports that the subsystem was left out of the build.
+func (b *Bootstrap) remote(subsystem, setting string) Option {
+	newProvider, ok := bootstrapRemotes[subsystem]
+	if !ok {
+		return func(o *options) {
+			o.errs = append(o.errs, &Error{Field: setting, Err: fmt.Errorf("%w: built without the %s subsystem", ErrInvalidOptions, subsystem)})
+		}
+	}
+	p := newProvider(b)
+	if b.WatchInterval > 0 {
+		return WithWatchProvider(p, b.WatchInterval)
+	}
+	return WithProvider(p)
+}
+
+// targetDefaults provides the default tags of whatever type is loaded, so
+// options built without knowing it can still include struct defaults.
+type targetDefaults struct{}
+
+func (targetDefaults) Name() string { return defaultsProviderName }
+
+func (targetDefaults) PrefixAware() bool { return true }
+
+func (targetDefaults) Values() (map[string]any, error) { return nil, nil }
+
+func (targetDefaults) resolve(t reflect.Type, _ map[string]any, o *options) (map[string]any, error) {
+	return (&defaultsProvider{typ: t, prefix: o.prefix}).Values()
+}
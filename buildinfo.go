@@ -0,0 +1,43 @@
+package envx
+
+import (
+	"runtime/debug"
+)
+
+var readBuildInfo = debug.ReadBuildInfo
+
+type buildInfoProvider struct{}
+
+// BuildInfo returns a provider exposing the binary's build information as
+// APP_VERSION (main module version), GIT_SHA, GIT_TIME, GIT_DIRTY and
+// GO_VERSION, so fields like AppVersion and GitSHA bind without ldflags.
+// VCS keys are only set when the binary was built from a checkout.
+func BuildInfo() Provider {
+	return &buildInfoProvider{}
+}
+
+func (p *buildInfoProvider) Name() string { return "buildinfo" }
+
+func (p *buildInfoProvider) Values() (map[string]any, error) {
+	values := make(map[string]any)
+	info, ok := readBuildInfo()
+	if !ok {
+		return values, nil
+	}
+
+	values["GO_VERSION"] = info.GoVersion
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		values["APP_VERSION"] = v
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			values["GIT_SHA"] = s.Value
+		case "vcs.time":
+			values["GIT_TIME"] = s.Value
+		case "vcs.modified":
+			values["GIT_DIRTY"] = s.Value
+		}
+	}
+	return values, nil
+}
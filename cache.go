@@ -0,0 +1,144 @@
+package envx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// errUnchanged reports that a reload was skipped because the merged values
+// are the same as those of the last successful load.
+var errUnchanged = errors.New("configuration unchanged")
+
+// valueCache carries a Loader's buffers from one load to the next, so that
+// polling an unchanged configuration neither rebuilds the merged map nor
+// parses it again.
+type valueCache struct {
+	values map[string]any
+	keys   []string
+	intern map[string]string
+	// set holds the keys that sources other than struct defaults and
+	// overrides provided, for onRemove.
+	set map[string]bool
+	// kept holds the keys onRemove:"keep" held at their last value.
+	kept map[string]bool
+	// origin labels the source of each key, for Loader.Sources.
+	origin map[string]string
+
+	hash  uint64
+	valid bool
+}
+
+// reset empties the merged map for reuse, dropping interned keys once most
+// of them are no longer read.
+func (c *valueCache) reset() map[string]any {
+	if c.values == nil {
+		c.values = make(map[string]any)
+		c.intern = make(map[string]string)
+		c.set = make(map[string]bool)
+		c.kept = make(map[string]bool)
+		c.origin = make(map[string]string)
+	}
+	clear(c.set)
+	clear(c.kept)
+	clear(c.origin)
+	if len(c.intern) > 2*len(c.values)+64 {
+		clear(c.intern)
+	}
+	clear(c.values)
+	return c.values
+}
+
+// key returns the interned copy of k, so the merged map holds the same key
+// strings across reloads instead of each provider's fresh ones.
+func (c *valueCache) key(k string) string {
+	if s, ok := c.intern[k]; ok {
+		return s
+	}
+	c.intern[k] = k
+	return k
+}
+
+// sum hashes values in key order with FNV-1a.
+func (c *valueCache) sum(values map[string]any) uint64 {
+	c.keys = c.keys[:0]
+	for k := range values {
+		c.keys = append(c.keys, k)
+	}
+	slices.Sort(c.keys)
+
+	h := uint64(fnvOffset)
+	for _, k := range c.keys {
+		h = fnvString(h, k)
+		h = fnvValue(h, values[k])
+	}
+	return h
+}
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+func fnvByte(h uint64, b byte) uint64 {
+	return (h ^ uint64(b)) * fnvPrime
+}
+
+func fnvString(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h = fnvByte(h, s[i])
+	}
+	return fnvByte(h, 0)
+}
+
+func fnvUint(h uint64, n uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h = fnvByte(h, byte(n>>(8*i)))
+	}
+	return h
+}
+
+// fnvValue hashes a provider value, tagging each kind so that e.g. the
+// string "1" and the number 1 differ.
+func fnvValue(h uint64, v any) uint64 {
+	switch v := v.(type) {
+	case nil:
+		return fnvByte(h, 'n')
+	case string:
+		return fnvString(fnvByte(h, 's'), v)
+	case bool:
+		if v {
+			return fnvByte(h, 't')
+		}
+		return fnvByte(h, 'f')
+	case float64:
+		return fnvUint(fnvByte(h, 'd'), math.Float64bits(v))
+	case int:
+		return fnvUint(fnvByte(h, 'i'), uint64(v))
+	case []any:
+		h = fnvUint(fnvByte(h, 'a'), uint64(len(v)))
+		for _, item := range v {
+			h = fnvValue(h, item)
+		}
+		return h
+	case []string:
+		h = fnvUint(fnvByte(h, 'l'), uint64(len(v)))
+		for _, item := range v {
+			h = fnvString(h, item)
+		}
+		return h
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		h = fnvUint(fnvByte(h, 'm'), uint64(len(v)))
+		for _, k := range keys {
+			h = fnvValue(fnvString(h, k), v[k])
+		}
+		return h
+	}
+	return fnvString(fnvByte(h, 'x'), fmt.Sprintf("%T:%v", v, v))
+}
@@ -0,0 +1,48 @@
+package envx
+
+import (
+	"reflect"
+	"sort"
+)
+
+// CompletionWords lists every variable name T reads, honoring WithPrefix,
+// each followed by "=" so shell completion can offer KEY=VALUE overrides.
+func CompletionWords[T any](opts ...Option) []string {
+	t, err := resolveStructType[T]()
+	if err != nil {
+		return nil
+	}
+	o := prepareOptions[T](opts)
+
+	keys := configKeys(t, "")
+	words := make([]string, len(keys))
+	for i, k := range keys {
+		if o.prefix != "" {
+			k = o.prefix + "_" + k
+		}
+		words[i] = k + "="
+	}
+	sort.Strings(words)
+	return words
+}
+
+func configKeys(t reflect.Type, path string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if isSection(field.Type) {
+			keys = append(keys, configKeys(field.Type, path+fieldName(field)+"_")...)
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			keys = append(keys, configKeys(field.Type.Elem(), path+fieldName(field)+"_")...)
+			continue
+		}
+		keys = append(keys, path+fieldName(field))
+	}
+	return keys
+}
@@ -0,0 +1,18 @@
+package envx
+
+import "context"
+
+type contextKey[T any] struct{}
+
+// NewContext returns a copy of ctx carrying cfg. Loaders replace the config
+// pointer on reload instead of mutating it, so cfg stays a consistent
+// snapshot for the lifetime of ctx.
+func NewContext[T any](ctx context.Context, cfg *T) context.Context {
+	return context.WithValue(ctx, contextKey[T]{}, cfg)
+}
+
+// FromContext returns the config of type T stored in ctx by NewContext.
+func FromContext[T any](ctx context.Context) (*T, bool) {
+	cfg, ok := ctx.Value(contextKey[T]{}).(*T)
+	return cfg, ok && cfg != nil
+}
@@ -0,0 +1,88 @@
+package envx
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"reflect"
+	"sync"
+)
+
+// devFillProfile is the only profile in which WithDevFill generates values.
+const devFillProfile = "local"
+
+// WithDevFill generates random placeholder values for required secret
+// string fields that are still empty when the active profile is "local".
+// Every generated value is logged so it is never mistaken for real config.
+// A Loader keeps the placeholders it generated across reloads.
+func WithDevFill() Option {
+	placeholders := &devPlaceholders{values: make(map[string]string)}
+	return func(o *options) {
+		o.devFill = placeholders
+	}
+}
+
+// devPlaceholders holds the values generated for one WithDevFill option, so
+// reloads reuse them rather than rotate the secrets.
+type devPlaceholders struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// get returns the placeholder for key, reporting whether it was generated
+// by this call.
+func (p *devPlaceholders) get(key string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if val, ok := p.values[key]; ok {
+		return val, false
+	}
+	val := randomToken()
+	p.values[key] = val
+	return val, true
+}
+
+func fillDevSecrets(cfg any, o *options) {
+	if o.devFill == nil || o.profile != devFillProfile {
+		return
+	}
+	v := reflect.ValueOf(cfg).Elem()
+	fillSecrets(v, v.Type(), "", o)
+}
+
+func fillSecrets(v reflect.Value, t reflect.Type, path string, o *options) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+
+		if isSection(field.Type) {
+			fillSecrets(fv, field.Type, path+fieldName(field)+"_", o)
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			if !fv.IsNil() {
+				fillSecrets(fv.Elem(), field.Type.Elem(), path+fieldName(field)+"_", o)
+			}
+			continue
+		}
+
+		if fv.Kind() != reflect.String || !fv.CanSet() || !fv.IsZero() {
+			continue
+		}
+		if !isRequired(field.Tag.Get("required"), o.profile) || !isSecret(field) {
+			continue
+		}
+
+		key := path + fieldName(field)
+		val, generated := o.devFill.get(key)
+		fv.SetString(val)
+		if generated {
+			o.logger.Printf("envx: WARNING: generated placeholder for %s (WithDevFill, profile %q)\n", key, o.profile)
+		}
+	}
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "dev-" + hex.EncodeToString(b)
+}
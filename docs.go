@@ -0,0 +1,208 @@
+package envx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldDoc documents one variable a configuration type reads.
+type FieldDoc struct {
+	Key         string   // variable name, e.g. "APP_DATABASE_URL"
+	Field       string   // Go field path, e.g. "Database.URL"
+	Type        string   // Go type, e.g. "time.Duration"
+	Default     string   // default tag
+	Required    bool     // required:"true"
+	RequiredIn  []string // profiles named by required:"prod,staging"
+	RequiredAny string   // requiredAny group
+	Secret      bool     // masked by Print
+	Optional    bool     // inside an optional section
+	Description string   // desc tag
+	Group       string   // group tag of the field or its section
+	Order       int      // order tag, 0 if unset
+
+	badOrder string // order tag that is not an integer
+}
+
+// Docs lists every variable T reads, with its type, default, required and
+// secret flags and desc tag. Options such as WithPrefix shape the keys as
+// they would for a load. It returns nil if T is not a struct.
+//
+// Fields come in declaration order unless tagged for a curated layout:
+// ungrouped fields first, then each group tag in order of first appearance,
+// a group tag on a section applying to its fields. Within a group, fields
+// with an order tag come first, ascending. An order tag that is not an
+// integer counts as unset here; WriteMarkdown reports it.
+func Docs[T any](opts ...Option) []FieldDoc {
+	t, err := resolveStructType[T]()
+	if err != nil {
+		return nil
+	}
+	o := prepareOptions[T](opts)
+	return layoutDocs(collectDocs(t, "", "", o.prefix, "", false))
+}
+
+// layoutDocs sorts docs by group and order tag, keeping declaration order
+// otherwise.
+func layoutDocs(docs []FieldDoc) []FieldDoc {
+	rank := map[string]int{"": 0}
+	for _, d := range docs {
+		if _, ok := rank[d.Group]; !ok {
+			rank[d.Group] = len(rank)
+		}
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		a, b := docs[i], docs[j]
+		if rank[a.Group] != rank[b.Group] {
+			return rank[a.Group] < rank[b.Group]
+		}
+		if (a.Order != 0) != (b.Order != 0) {
+			return a.Order != 0
+		}
+		return a.Order < b.Order
+	})
+	return docs
+}
+
+func collectDocs(t reflect.Type, path, fieldPath, prefix, group string, optional bool) []FieldDoc {
+	var docs []FieldDoc
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldGroup := group
+		if tag := field.Tag.Get("group"); tag != "" {
+			fieldGroup = tag
+		}
+		if isSection(field.Type) {
+			docs = append(docs, collectDocs(field.Type, path+fieldName(field)+"_", fieldPath+field.Name+".", prefix, fieldGroup, optional)...)
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			docs = append(docs, collectDocs(field.Type.Elem(), path+fieldName(field)+"_", fieldPath+field.Name+".", prefix, fieldGroup, true)...)
+			continue
+		}
+
+		doc := FieldDoc{
+			Key:         path + fieldName(field),
+			Field:       fieldPath + field.Name,
+			Type:        field.Type.String(),
+			Default:     field.Tag.Get("default"),
+			RequiredAny: field.Tag.Get("requiredAny"),
+			Secret:      isSecret(field),
+			Optional:    optional,
+			Description: field.Tag.Get("desc"),
+			Group:       fieldGroup,
+		}
+		if tag := field.Tag.Get("order"); tag != "" {
+			var err error
+			if doc.Order, err = strconv.Atoi(tag); err != nil {
+				doc.badOrder = tag
+			}
+		}
+		if prefix != "" {
+			doc.Key = prefix + "_" + doc.Key
+		}
+		switch tag := field.Tag.Get("required"); tag {
+		case "", "false":
+		case "true":
+			doc.Required = true
+		default:
+			doc.RequiredIn = splitTagList(tag)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// WriteMarkdown renders docs as a Markdown table, e.g. for a runbook kept
+// current by go:generate. Each group gets its own table under a heading.
+// It writes nothing and returns ErrParse errors if an order tag is not an
+// integer, so a typo fails the docs build rather than every Load.
+func WriteMarkdown(w io.Writer, docs []FieldDoc) error {
+	var errs []error
+	for _, d := range docs {
+		if d.badOrder != "" {
+			errs = append(errs, &Error{Field: d.Key, Err: fmt.Errorf("%w: order tag %q is not an integer", ErrParse, d.badOrder)})
+		}
+	}
+	if err := joinErrors(errs); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for i, d := range docs {
+		if i == 0 || d.Group != docs[i-1].Group {
+			if i > 0 {
+				buf.WriteString("\n")
+			}
+			if d.Group != "" {
+				buf.WriteString("### " + d.Group + "\n\n")
+			}
+			buf.WriteString("| Variable | Type | Default | Required | Secret | Description |\n")
+			buf.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+		}
+		writeMarkdownRow(&buf, d)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeMarkdownRow(buf *bytes.Buffer, d FieldDoc) {
+	var required []string
+	switch {
+	case d.Required:
+		required = append(required, "yes")
+	case len(d.RequiredIn) > 0:
+		required = append(required, "in "+strings.Join(d.RequiredIn, ", "))
+	}
+	if d.RequiredAny != "" {
+		required = append(required, "one of "+d.RequiredAny)
+	}
+	secret := ""
+	if d.Secret {
+		secret = "yes"
+	}
+	desc := d.Description
+	if d.Optional {
+		desc = strings.TrimSpace(desc + "\nOptional section.")
+	}
+
+	cells := []string{
+		markdownCode(d.Key),
+		markdownCode(d.Type),
+		markdownCode(d.Default),
+		markdownCell(strings.Join(required, "; ")),
+		secret,
+		markdownCell(desc),
+	}
+	buf.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+}
+
+// markdownCell escapes s for a table cell, joining lines with <br>.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Split(strings.TrimSpace(s), "\n"), "<br>")
+}
+
+// markdownCode wraps s in a code span, widening the backtick fence when s
+// contains backticks.
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + strings.ReplaceAll(s, "|", `\|`) + fence
+}
@@ -0,0 +1,88 @@
+package envx
+
+import (
+	"crypto/sha256"
+	"os"
+	"strings"
+	"sync"
+)
+
+type envFileProvider struct {
+	path string
+
+	mu   sync.Mutex
+	hash [sha256.Size]byte
+}
+
+// EnvFile returns a provider reading a companion environment file, e.g.
+// /etc/myapp/env, with KEY=VALUE lines in .env syntax; an `export ` prefix
+// is ignored. Its keys are variable names, used as-is like Env's, so
+// register it after Env to let the file win. The provider implements
+// ChangeDetector, so with WithWatchProvider edits to the file are merged
+// into the configuration on reload. A missing file provides no values.
+func EnvFile(path string) Provider {
+	return &envFileProvider{path: path}
+}
+
+func (p *envFileProvider) Name() string { return "envfile" }
+
+func (p *envFileProvider) PrefixAware() bool { return true }
+
+func (p *envFileProvider) Describe() ProviderInfo {
+	return ProviderInfo{Source: p.path}
+}
+
+func (p *envFileProvider) Values() (map[string]any, error) {
+	data, err := p.read()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.hash = sha256.Sum256(data)
+	p.mu.Unlock()
+
+	values := make(map[string]any)
+	for k, v := range parseEnvFile(data) {
+		values[k] = v
+	}
+	return values, nil
+}
+
+// Changed reports whether the file's content differs from the last Values
+// call, so touching it without editing does not reload.
+func (p *envFileProvider) Changed() (bool, error) {
+	data, err := p.read()
+	if err != nil {
+		return false, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return sha256.Sum256(data) != p.hash, nil
+}
+
+func (p *envFileProvider) read() ([]byte, error) {
+	data, err := os.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEncoding(data, false); err != nil {
+		return nil, &Error{Field: p.path, Err: err}
+	}
+	data, _ = normalizeText(data)
+	return data, nil
+}
+
+func parseEnvFile(data []byte) map[string]string {
+	values := parseDotEnv(data)
+	for k, v := range values {
+		if name, ok := strings.CutPrefix(k, "export "); ok {
+			delete(values, k)
+			values[strings.TrimSpace(name)] = v
+		}
+	}
+	return values
+}
@@ -0,0 +1,66 @@
+package envx
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// envSetting is an ENVX_* variable that tunes a loader's own behavior, so
+// operators can adjust reloads per environment without a code change.
+type envSetting struct {
+	name string
+	// allowZero accepts "0", which turns the setting off.
+	allowZero bool
+	apply     func(o *options, d time.Duration)
+}
+
+var envSettings = []envSetting{
+	{name: "ENVX_WATCH_INTERVAL", apply: func(o *options, d time.Duration) {
+		for i := range o.watches {
+			o.watches[i].interval = d
+		}
+		for i := range o.watchProviders {
+			o.watchProviders[i].interval = d
+		}
+	}},
+	{name: "ENVX_RELOAD_DEBOUNCE", allowZero: true, apply: func(o *options, d time.Duration) {
+		o.reloadDebounce = d
+	}},
+	{name: "ENVX_FETCH_TIMEOUT", allowZero: true, apply: func(o *options, d time.Duration) {
+		o.fetchTimeout = d
+	}},
+}
+
+// WithoutEnvSettings ignores the ENVX_* variables, keeping the options
+// given in code, e.g. for a library that embeds its own loader.
+func WithoutEnvSettings() Option {
+	return func(o *options) {
+		o.ignoreEnvSettings = true
+	}
+}
+
+// applyEnvSettings overrides the options with the ENVX_* variables that are
+// set. Invalid values are reported as ErrInvalidOptions.
+func applyEnvSettings(o *options) {
+	if o.ignoreEnvSettings {
+		return
+	}
+	for _, s := range envSettings {
+		raw, ok := os.LookupEnv(s.name)
+		if !ok || raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(raw)
+		switch {
+		case err != nil:
+			o.errs = append(o.errs, &Error{Field: s.name, Err: fmt.Errorf("%w: %v", ErrInvalidOptions, err)})
+		case d < 0 && s.allowZero:
+			o.errs = append(o.errs, &Error{Field: s.name, Err: fmt.Errorf("%w: must not be negative, got %s", ErrInvalidOptions, raw)})
+		case d <= 0 && !s.allowZero:
+			o.errs = append(o.errs, &Error{Field: s.name, Err: fmt.Errorf("%w: must be greater than zero, got %s", ErrInvalidOptions, raw)})
+		default:
+			s.apply(o, d)
+		}
+	}
+}
@@ -2,15 +2,28 @@ package envx
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
+	"runtime"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -1116,6 +1129,60 @@ func TestPrintStructNested(t *testing.T) {
 	}
 }
 
+func TestPrintSources(t *testing.T) {
+	type Config struct {
+		Port     int           `default:"8080"`
+		Host     string        `default:"localhost"`
+		Timeout  time.Duration `default:"5s"`
+		Debug    bool
+		Database struct {
+			Name string
+		}
+	}
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("HOST=filehost\nDATABASE_NAME=app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_PORT", "9090")
+
+	loader := NewLoader[Config](
+		WithPrefix("APP"),
+		WithProvider(DefaultsWithPrefix[Config]("APP")),
+		WithProvider(File(path)),
+		WithProvider(Env()),
+	)
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := map[string]string{"PORT": "env", "HOST": ".env", "TIMEOUT": "default", "DATABASE_NAME": ".env"}
+	if got := loader.Sources(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Sources = %v, want %v", got, want)
+	}
+
+	if err := loader.Override("APP_DEBUG", "true"); err != nil {
+		t.Fatalf("Override: %v", err)
+	}
+	var buf bytes.Buffer
+	PrintTo(&buf, loader.Get(), PrintSources(loader.Sources()))
+	for _, line := range []string{
+		"PORT                      = 9090 (env)\n",
+		"HOST                      = filehost (.env)\n",
+		"TIMEOUT                   = 5s (default)\n",
+		"DEBUG                     = true (override)\n",
+		"  NAME                      = app (.env)\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("missing %q in:\n%s", line, buf.String())
+		}
+	}
+
+	buf.Reset()
+	PrintTo(&buf, loader.Get())
+	if strings.Contains(buf.String(), "(env)") {
+		t.Fatalf("sources printed without PrintSources:\n%s", buf.String())
+	}
+}
+
 func TestParseStructPrefixAndRequired(t *testing.T) {
 	type Config struct {
 		Port int `required:"true"`
@@ -1131,7 +1198,7 @@ func TestParseStructPrefixAndRequired(t *testing.T) {
 	}
 
 	cfg = &Config{}
-	if err := validateRequired(cfg); err == nil {
+	if err := validateRequired(cfg, ""); err == nil {
 		t.Fatal("expected required validation error")
 	}
 }
@@ -1169,11 +1236,11 @@ func TestValidateRequiredNested(t *testing.T) {
 	}
 
 	cfg := &Config{}
-	if err := validateRequired(cfg); err == nil {
+	if err := validateRequired(cfg, ""); err == nil {
 		t.Fatal("expected required error for nested field")
 	}
 	cfg.Nest.Token = "ok"
-	if err := validateRequired(cfg); err != nil {
+	if err := validateRequired(cfg, ""); err != nil {
 		t.Fatalf("expected no error for nested required, got %v", err)
 	}
 }
@@ -1359,23 +1426,18 @@ func TestWatchLoopBranches(t *testing.T) {
 	type Config struct{}
 
 	o := defaultOptions()
-	o.watchEvery = time.Millisecond
 	tmpfile := filepath.Join(t.TempDir(), "config.json")
 	if err := os.WriteFile(tmpfile, []byte(`{"port": 1}`), 0644); err != nil {
 		t.Fatalf("write: %v", err)
 	}
-	o.watchPath = tmpfile
+	watch := fileWatch{path: tmpfile, interval: time.Millisecond}
 
 	loader := &Loader[Config]{}
 	stop := make(chan struct{})
 	close(stop)
-	var wg sync.WaitGroup
-	wg.Add(1)
-	newWatchLoop(loader, o, os.Stat).run(stop, &wg)
-	wg.Wait()
+	newWatchLoop(loader, o, watch, os.Stat).run(stop)
 
 	stop = make(chan struct{})
-	wg.Add(1)
 	go func() {
 		time.Sleep(5 * time.Millisecond)
 		close(stop)
@@ -1383,6 +1445,4619 @@ func TestWatchLoopBranches(t *testing.T) {
 	errStat := func(string) (os.FileInfo, error) {
 		return nil, os.ErrNotExist
 	}
-	newWatchLoop(loader, o, errStat).run(stop, &wg)
-	wg.Wait()
+	newWatchLoop(loader, o, watch, errStat).run(stop)
+}
+
+type namedMapProvider struct {
+	name   string
+	values map[string]any
+}
+
+func (p namedMapProvider) Values() (map[string]any, error) { return p.values, nil }
+
+func (p namedMapProvider) Name() string { return p.name }
+
+func TestLoad_FromTagRestrictsProvider(t *testing.T) {
+	type Config struct {
+		Token string `from:"vault" default:"dev-token"`
+		Host  string `from:"vault, file"`
+		Port  int
+	}
+
+	cfg, err := Load[Config](
+		WithProvider(Defaults[Config]()),
+		WithProvider(namedMapProvider{name: "vault", values: map[string]any{"TOKEN": "from-vault"}}),
+		WithProvider(Map(map[string]string{"TOKEN": "from-map", "HOST": "from-map", "PORT": "80"})),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Token != "from-vault" {
+		t.Errorf("Token = %q, want from-vault", cfg.Token)
+	}
+	if cfg.Host != "" {
+		t.Errorf("Host = %q, want empty (map provider not allowed)", cfg.Host)
+	}
+	if cfg.Port != 80 {
+		t.Errorf("Port = %d, want 80", cfg.Port)
+	}
+
+	cfg, err = Load[Config](WithProvider(Defaults[Config]()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Token != "dev-token" {
+		t.Errorf("Token = %q, want default dev-token", cfg.Token)
+	}
+
+	// Only the real struct defaults bypass from, not any provider that
+	// calls itself "defaults".
+	cfg, err = Load[Config](
+		WithProvider(Defaults[Config]()),
+		WithProvider(namedMapProvider{name: "defaults", values: map[string]any{"TOKEN": "spoofed", "HOST": "spoofed"}}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Token != "dev-token" || cfg.Host != "" {
+		t.Errorf("a provider named defaults bypassed from: %+v", *cfg)
+	}
+}
+
+func TestProviderNames(t *testing.T) {
+	type Config struct{}
+
+	tests := map[string]Provider{
+		"env":      Env(),
+		"defaults": Defaults[Config](),
+		"file":     File("config.json"),
+		"map":      Map(nil),
+	}
+	for want, p := range tests {
+		if got := providerName(p); got != want {
+			t.Errorf("providerName(%T) = %q, want %q", p, got, want)
+		}
+	}
+	if got := providerName(failingProvider{}); got != "envx.failingProvider" {
+		t.Errorf("providerName(failingProvider) = %q", got)
+	}
+	if got := providerName(&layeredProvider{Provider: failingProvider{}}); got != "envx.failingProvider" {
+		t.Errorf("providerName(layered failingProvider) = %q", got)
+	}
+}
+
+func TestLoader_ProvidersAndPrecedence(t *testing.T) {
+	type Config struct {
+		Port int `default:"8080"`
+	}
+
+	loader := NewLoader[Config](
+		WithProvider(Defaults[Config]()),
+		WithProvider(Map(map[string]string{"PORT": "1000"})),
+		WithProvider(namedMapProvider{name: "vault", values: map[string]any{"PORT": "2000"}}),
+		WithPrecedence("vault", "map"),
+	)
+
+	got := loader.Providers()
+	want := []ProviderInfo{
+		{Name: "defaults", PrefixAware: true},
+		{Name: "vault"},
+		{Name: "map"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Providers() = %#v, want %#v", got, want)
+	}
+
+	cfg := loader.MustLoad()
+	if cfg.Port != 1000 {
+		t.Errorf("Port = %d, want 1000 from map (highest precedence)", cfg.Port)
+	}
+}
+
+func TestLoadFromEnv_UserProvidersBelowEnv(t *testing.T) {
+	t.Setenv("PORT", "6000")
+
+	type Config struct {
+		Port int    `default:"7000"`
+		Host string `default:"default"`
+	}
+
+	cfg, err := LoadFromEnv[Config](
+		WithProvider(Map(map[string]string{"PORT": "5000", "HOST": "map"})),
+	)
+	if err != nil {
+		t.Fatalf("LoadFromEnv: %v", err)
+	}
+	if cfg.Port != 6000 {
+		t.Errorf("Port = %d, want env 6000 over user provider", cfg.Port)
+	}
+	if cfg.Host != "map" {
+		t.Errorf("Host = %q, want map", cfg.Host)
+	}
+
+	cfg, err = LoadFromEnv[Config](
+		WithLayer(Map(map[string]string{"PORT": "5000"}), LayerOverride),
+	)
+	if err != nil {
+		t.Fatalf("LoadFromEnv: %v", err)
+	}
+	if cfg.Port != 5000 {
+		t.Errorf("Port = %d, want override 5000", cfg.Port)
+	}
+}
+
+func TestWithLayerOrdersProviders(t *testing.T) {
+	type Config struct {
+		Port int `default:"8080"`
+	}
+
+	loader := NewLoader[Config](
+		WithLayer(Map(map[string]string{"PORT": "1"}), LayerOverride),
+		WithProvider(Map(map[string]string{"PORT": "2"})),
+		WithLayer(Defaults[Config](), LayerDefaults),
+	)
+
+	got := loader.Providers()
+	want := []ProviderInfo{
+		{Name: "defaults", PrefixAware: true},
+		{Name: "map"},
+		{Name: "map"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Providers() = %#v, want %#v", got, want)
+	}
+	if cfg := loader.MustLoad(); cfg.Port != 1 {
+		t.Errorf("Port = %d, want 1 from override layer", cfg.Port)
+	}
+}
+
+func TestMapPrefixedAndPrefixAware(t *testing.T) {
+	type Config struct {
+		Port int `default:"8080"`
+		Host string
+	}
+
+	cfg, err := Load[Config](
+		WithPrefix("APP"),
+		WithProvider(MapPrefixed(map[string]string{"APP_PORT": "9000"})),
+		WithProvider(PrefixAware(Env(), false)),
+		WithProvider(PrefixAware(Map(map[string]string{"HOST": "example"}), false)),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 9000 {
+		t.Errorf("Port = %d, want 9000", cfg.Port)
+	}
+	if cfg.Host != "example" {
+		t.Errorf("Host = %q, want example", cfg.Host)
+	}
+
+	if info := describeProvider(MapPrefixed(nil)); info.Name != "map" || !info.PrefixAware {
+		t.Errorf("describeProvider(MapPrefixed) = %#v", info)
+	}
+}
+
+func TestDefaultsOfAndFor(t *testing.T) {
+	type Config struct {
+		Port int `default:"8080"`
+		DB   struct {
+			Host string `default:"localhost"`
+		}
+	}
+
+	for _, p := range []Provider{DefaultsOf(reflect.TypeOf(Config{})), DefaultsFor(&Config{})} {
+		values, err := p.Values()
+		if err != nil {
+			t.Fatalf("Values: %v", err)
+		}
+		if values["PORT"] != "8080" || values["DB_HOST"] != "localhost" {
+			t.Fatalf("unexpected defaults: %#v", values)
+		}
+	}
+
+	if _, err := DefaultsFor(nil).Values(); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("expected ErrUnsupportedType for nil, got %v", err)
+	}
+	if _, err := DefaultsFor(42).Values(); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("expected ErrUnsupportedType for int, got %v", err)
+	}
+}
+
+func writePlugin(t *testing.T, script string) string {
+	t.Helper()
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("plugin tests need /bin/sh")
+	}
+	path := filepath.Join(t.TempDir(), "envx-plugin")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+	return path
+}
+
+func TestPluginProvider(t *testing.T) {
+	type Config struct {
+		Port int
+		DB   struct {
+			Host string
+		}
+		Token string `from:"envx-plugin"`
+	}
+
+	ok := writePlugin(t, `read req
+case "$req" in
+  *'"version":1'*) echo '{"values":{"port":9000,"db":{"host":"db.local"},"token":"t"}}' ;;
+  *) echo '{"error":"bad request"}' ;;
+esac
+`)
+	cfg, err := Load[Config](WithProvider(Plugin(ok)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 9000 || cfg.DB.Host != "db.local" || cfg.Token != "t" {
+		t.Fatalf("unexpected config: %#v", cfg)
+	}
+
+	failing := writePlugin(t, "echo '{\"error\":\"vault sealed\"}'\n")
+	if _, err := Plugin(failing).Values(); err == nil || !strings.Contains(err.Error(), "vault sealed") {
+		t.Fatalf("expected plugin error, got %v", err)
+	}
+
+	crashing := writePlugin(t, "echo boom >&2\nexit 3\n")
+	if _, err := Plugin(crashing).Values(); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected exit error with stderr, got %v", err)
+	}
+
+	garbage := writePlugin(t, "echo not-json\n")
+	if _, err := Plugin(garbage).Values(); err == nil {
+		t.Fatal("expected invalid response error")
+	}
+}
+
+func TestLoad_ExprTag(t *testing.T) {
+	type Config struct {
+		Port     int    `default:"8080" expr:"Port > 1024 && Port < 65535"`
+		Mode     string `default:"prod" expr:"Mode == \"prod\" || Mode == \"dev\""`
+		Replicas int    `default:"3"`
+		Pool     struct {
+			Min int `default:"2"`
+			Max int `default:"10" expr:"Max >= Min * 2"`
+		}
+		Hosts []string `default:"a,b" expr:"len(Hosts) > 1 && !(Replicas < 1)"`
+	}
+
+	if _, err := Load[Config](); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := Load[Config](WithProvider(Defaults[Config]()), WithProvider(Map(map[string]string{"PORT": "80"})))
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+	var envErr *Error
+	if !errors.As(err, &envErr) || envErr.Field != "PORT" {
+		t.Fatalf("expected PORT field error, got %v", err)
+	}
+
+	_, err = Load[Config](WithProvider(Defaults[Config]()), WithProvider(Map(map[string]string{"POOL_MAX": "3"})))
+	if err == nil || !strings.Contains(err.Error(), "POOL_MAX") {
+		t.Fatalf("expected POOL_MAX error, got %v", err)
+	}
+}
+
+func TestEvalBoolExprErrors(t *testing.T) {
+	type Scope struct {
+		Port  int
+		Name  string
+		On    bool
+		Sub   struct{ X int }
+		Cplx  complex64
+		Hosts []string
+	}
+	scope := reflect.ValueOf(Scope{Port: 10, Name: "a", On: true, Hosts: []string{"a", "b"}})
+
+	valid := []string{
+		`Sub.X == 0`, `-Port < 0`, `Port - 5 == 5`, `Port / 2 == 5`, `Port * 2 == 20`,
+		`Name + "b" == "ab"`, `Name != "b"`, `Name < "b"`, `Name <= "a"`, `Name > ""`, `Name >= "a"`,
+		`On == true`, `On != false`, `Port != 1`, `Port <= 10`, `Port >= 10`, `len(Name) == 1`,
+		`false || On`, `(Port == 10)`, `'a' == Name`, `Hosts > 1`, `Hosts == len(Hosts)`, `len(Hosts) == 2`,
+	}
+	for _, src := range valid {
+		ok, err := evalBoolExpr(src, scope)
+		if err != nil || !ok {
+			t.Errorf("evalBoolExpr(%q) = %v, %v; want true", src, ok, err)
+		}
+	}
+
+	invalid := []string{
+		`Port >`, `Port`, `Missing > 1`, `Nope.X == 1`, `Port / 0 == 1`, `Cplx == 1`,
+		`Name && true`, `true && Name`, `Name - "a" == ""`, `Port % 2 == 0`, `On < true`,
+		`!Port`, `-Name == ""`, `len(On) == 1`, `max(Port) == 1`, `Port == "a"`, `Port[0] == 1`,
+		`Sub.Y == 1`, `0x1p-2i == 1`, `Port.X == 1`, `f().X == 1`, `Sub.X.Y == 1`, `On == 1.5`,
+		`len(Missing) == 1`, `-Missing == 1`, `Missing == 1`, `1 == Missing`, `true && Missing`,
+		`len(Port) == 10`,
+	}
+	for _, src := range invalid {
+		if _, err := evalBoolExpr(src, scope); err == nil {
+			t.Errorf("evalBoolExpr(%q) expected error", src)
+		}
+	}
+
+	type Big struct {
+		ID    int64
+		Limit uint64
+		Ratio float64
+		Cache *struct{ Size int }
+	}
+	wide := reflect.ValueOf(Big{ID: 1<<62 + 1, Limit: math.MaxUint64, Ratio: 0.5})
+	for _, src := range []string{
+		`ID != 4611686018427387904`, `ID == 4611686018427387905`, `ID > 4611686018427387904`,
+		`ID - 1 == 4611686018427387904`, `Limit == 18446744073709551615`, `Limit > ID * 2`,
+		`-ID < 0`, `Ratio < 1`, `Ratio * 2 == 1`, `ID > Ratio`, `ID / 2 > 1`, `3 / 2 == 1.5`,
+	} {
+		if ok, err := evalBoolExpr(src, wide); err != nil || !ok {
+			t.Errorf("evalBoolExpr(%q) = %v, %v; want true", src, ok, err)
+		}
+	}
+	if _, err := evalBoolExpr(`Cache.Size > 1`, wide); err == nil || !strings.Contains(err.Error(), `section "Cache" is unset`) {
+		t.Errorf("expected an unset section error, got %v", err)
+	}
+}
+
+type mutableProvider struct {
+	mu     sync.Mutex
+	values map[string]any
+}
+
+func (p *mutableProvider) Set(key string, value any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.values == nil {
+		p.values = make(map[string]any)
+	}
+	p.values[key] = value
+}
+
+func (p *mutableProvider) Delete(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.values, key)
+}
+
+func (p *mutableProvider) Values() (map[string]any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	values := make(map[string]any, len(p.values))
+	for k, v := range p.values {
+		values[k] = v
+	}
+	return values, nil
+}
+
+func TestLoader_MaxReloadRate(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+
+	mp := &mutableProvider{}
+	mp.Set("PORT", "1")
+
+	loader := NewLoader[Config](
+		WithProvider(mp),
+		WithMaxReloadRate(1, 100*time.Millisecond),
+	)
+	loader.MustLoad()
+	o := prepareOptions[Config](loader.opts)
+
+	mp.Set("PORT", "2")
+	loader.reloadConfig(o)
+	if got := loader.Get().Port; got != 2 {
+		t.Fatalf("Port = %d, want 2 after first reload", got)
+	}
+
+	mp.Set("PORT", "3")
+	loader.reloadConfig(o)
+	mp.Set("PORT", "4")
+	loader.reloadConfig(o)
+	if got := loader.Get().Port; got != 2 {
+		t.Fatalf("Port = %d, want 2 while rate limited", got)
+	}
+
+	deadline := time.After(time.Second)
+	for loader.Get().Port != 4 {
+		select {
+		case <-deadline:
+			t.Fatalf("Port = %d, want to converge to 4", loader.Get().Port)
+		default:
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if v := loader.Version(); v != 3 {
+		t.Errorf("Version = %d, want 3 (intermediate value dropped)", v)
+	}
+}
+
+func TestLoader_ReloadDelayWithoutLimit(t *testing.T) {
+	loader := &Loader[struct{}]{}
+	if d := loader.reloadDelay(defaultOptions(), time.Now()); d != 0 {
+		t.Fatalf("reloadDelay = %v, want 0", d)
+	}
+}
+
+func TestWriteFileAtomicRoundTrip(t *testing.T) {
+	type Config struct {
+		Port        int
+		DatabaseURL string
+		Timeout     time.Duration
+		Hosts       []string
+		Note        string
+		Server      struct {
+			Host  string
+			Debug bool
+		}
+	}
+
+	want := &Config{Port: 9000, DatabaseURL: "postgres://db", Timeout: 5 * time.Second, Hosts: []string{"a", "b,c"}, Note: " padded "}
+	want.Server.Host = "0.0.0.0"
+	want.Server.Debug = true
+
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name   string
+		format Format
+	}{
+		{"config.json", FormatJSON},
+		{".env", FormatDotEnv},
+	} {
+		path := filepath.Join(dir, tc.name)
+		if err := WriteFileAtomic(path, want, tc.format); err != nil {
+			t.Fatalf("WriteFileAtomic(%s): %v", tc.format, err)
+		}
+		got, err := Load[Config](WithProvider(File(path)))
+		if err != nil {
+			t.Fatalf("Load(%s): %v", tc.format, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s round trip = %#v, want %#v", tc.format, got, want)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected no temporary files left behind, got %d entries", len(entries))
+	}
+
+	if err := os.Chmod(filepath.Join(dir, ".env"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(filepath.Join(dir, ".env"), want, FormatDotEnv); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, ".env")); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want existing 0600 preserved", info.Mode().Perm())
+	}
+
+	if err := WriteFileAtomic(filepath.Join(dir, "x"), want, Format("toml")); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected ErrUnsupportedType for unknown format, got %v", err)
+	}
+	n := 1
+	if err := WriteFileAtomic(filepath.Join(dir, "x"), &n, FormatJSON); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("expected ErrUnsupportedType for non-struct, got %v", err)
+	}
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "x.json"), want, FormatJSON); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestLoader_OverridePersistsAndClears(t *testing.T) {
+	type Config struct {
+		Port int    `default:"8080"`
+		Mode string `default:"prod" expr:"Mode != \"broken\""`
+	}
+
+	path := filepath.Join(t.TempDir(), "overrides.json")
+	newLoader := func() *Loader[Config] {
+		return NewLoader[Config](
+			WithProvider(Defaults[Config]()),
+			WithOverridesFile(path),
+		)
+	}
+
+	loader := newLoader()
+	loader.MustLoad()
+	if err := loader.Override("PORT", "9090"); err != nil {
+		t.Fatalf("Override: %v", err)
+	}
+	if got := loader.Get().Port; got != 9090 {
+		t.Fatalf("Port = %d, want 9090", got)
+	}
+	if err := loader.Override("MODE", "broken"); !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation error for bad override, got %v", err)
+	}
+	if got := loader.Overrides(); !reflect.DeepEqual(got, map[string]string{"PORT": "9090"}) {
+		t.Fatalf("Overrides() = %#v, want only PORT", got)
+	}
+	if infos := loader.Providers(); infos[len(infos)-1].Name != "override" {
+		t.Fatalf("expected override provider last, got %#v", infos)
+	}
+
+	if info, err := os.Stat(path); err != nil || runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Fatalf("overrides file: %v, %v; want mode 0600", info, err)
+	}
+
+	restarted := newLoader()
+	if cfg := restarted.MustLoad(); cfg.Port != 9090 {
+		t.Fatalf("Port after restart = %d, want persisted 9090", cfg.Port)
+	}
+
+	if err := restarted.ClearOverrides(); err != nil {
+		t.Fatalf("ClearOverrides: %v", err)
+	}
+	if got := restarted.Get().Port; got != 8080 {
+		t.Fatalf("Port after clear = %d, want 8080", got)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected overrides file removed, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newLoader().Load(); !errors.Is(err, ErrParse) {
+		t.Fatalf("expected ErrParse for corrupt overrides file, got %v", err)
+	}
+}
+
+func TestLoader_OverrideKeepsImplicitChain(t *testing.T) {
+	type Config struct {
+		Port int    `default:"8080"`
+		Host string `default:"localhost"`
+		Mode string `default:"dev"`
+	}
+	t.Setenv("APP_HOST", "fromenv")
+
+	loader := NewLoader[Config](WithPrefix("APP"))
+	loader.MustLoad()
+	if err := loader.Override("APP_PORT", "9090"); err != nil {
+		t.Fatalf("Override: %v", err)
+	}
+	want := Config{Port: 9090, Host: "fromenv", Mode: "dev"}
+	if got := *loader.Get(); got != want {
+		t.Fatalf("after Override = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoader_OverrideInMemory(t *testing.T) {
+	type Config struct {
+		Port int `default:"8080"`
+	}
+
+	reloaded := make(chan int, 1)
+	loader := NewLoader[Config](
+		WithPrefix("APP"),
+		WithOnReload(func(old, new *Config) { reloaded <- new.Port }),
+	)
+	loader.MustLoad()
+
+	if err := loader.Override("APP_PORT", "7000"); err != nil {
+		t.Fatalf("Override: %v", err)
+	}
+	select {
+	case port := <-reloaded:
+		if port != 7000 {
+			t.Fatalf("OnReload port = %d, want 7000", port)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected OnReload after override")
+	}
+	if err := loader.ClearOverrides(); err != nil {
+		t.Fatalf("ClearOverrides: %v", err)
+	}
+	if got := loader.Get().Port; got != 8080 {
+		t.Fatalf("Port = %d, want 8080", got)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	type HTTPConfig struct {
+		Port int `default:"8080"`
+	}
+	type WorkerConfig struct {
+		Concurrency int `required:"true"`
+	}
+
+	reg := NewRegistry(WithPrefix("APP"))
+	httpLoader, err := Register[HTTPConfig](reg, "http")
+	if err != nil {
+		t.Fatalf("Register http: %v", err)
+	}
+	if _, err := Register[WorkerConfig](reg, "worker"); err != nil {
+		t.Fatalf("Register worker: %v", err)
+	}
+	if _, err := Register[WorkerConfig](reg, "worker"); err == nil {
+		t.Fatal("expected duplicate registration error")
+	}
+
+	if got := reg.Names(); !reflect.DeepEqual(got, []string{"http", "worker"}) {
+		t.Fatalf("Names() = %v", got)
+	}
+	if l, ok := Lookup[HTTPConfig](reg, "http"); !ok || l != httpLoader {
+		t.Fatal("expected Lookup to return the http loader")
+	}
+	if _, ok := Lookup[HTTPConfig](reg, "worker"); ok {
+		t.Fatal("expected Lookup with wrong type to fail")
+	}
+
+	health := reg.Health()
+	if !errors.Is(health["http"], ErrNotLoaded) {
+		t.Fatalf("expected ErrNotLoaded before load, got %v", health["http"])
+	}
+
+	err = reg.Load()
+	if !errors.Is(err, ErrRequired) || !strings.Contains(err.Error(), "worker") {
+		t.Fatalf("expected worker required error, got %v", err)
+	}
+	if _, ok := err.(*MultiError); ok {
+		t.Fatalf("expected the only error unwrapped, got %#v", err)
+	}
+	health = reg.Health()
+	if health["http"] != nil || !errors.Is(health["worker"], ErrRequired) {
+		t.Fatalf("unexpected health: %v", health)
+	}
+
+	t.Setenv("APP_CONCURRENCY", "4")
+	if err := reg.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := reg.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+	reg.StopWatching()
+}
+
+func TestRegistryStartWatchingRollsBack(t *testing.T) {
+	type Config struct {
+		Port int `default:"8080"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := NewRegistry(WithOutput(io.Discard))
+	good, _ := Register[Config](reg, "good", WithProvider(File(path)), WithWatch(path, 10*time.Millisecond))
+	if _, err := Register[Config](reg, "bad", WithWatch(path, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.StartWatching(); err == nil || !strings.Contains(err.Error(), "bad") {
+		t.Fatalf("expected bad loader error, got %v", err)
+	}
+	good.mu.RLock()
+	watching := good.isWatching
+	good.mu.RUnlock()
+	if watching {
+		t.Fatal("expected good loader to be stopped after rollback")
+	}
+}
+
+func TestContextHelpers(t *testing.T) {
+	type Config struct {
+		Port int `default:"8080"`
+	}
+	type Other struct{}
+
+	loader := NewLoader[Config]()
+	pinned := loader.MustLoad()
+	ctx := NewContext(context.Background(), pinned)
+
+	if err := loader.Override("PORT", "9090"); err != nil {
+		t.Fatalf("Override: %v", err)
+	}
+
+	cfg, ok := FromContext[Config](ctx)
+	if !ok || cfg != pinned || cfg.Port != 8080 {
+		t.Fatalf("FromContext = %v, %v; want pinned snapshot", cfg, ok)
+	}
+	if _, ok := FromContext[Other](ctx); ok {
+		t.Fatal("expected no Other config in context")
+	}
+	if _, ok := FromContext[Config](NewContext[Config](context.Background(), nil)); ok {
+		t.Fatal("expected nil config to report false")
+	}
+}
+
+func TestLoaderPin(t *testing.T) {
+	type Config struct {
+		Port int `default:"8080"`
+	}
+
+	loader := NewLoader[Config]()
+	loader.MustLoad()
+
+	snap := loader.Pin()
+	if err := loader.Override("PORT", "9090"); err != nil {
+		t.Fatalf("Override: %v", err)
+	}
+
+	if snap.Config().Port != 8080 || snap.Version() != 1 {
+		t.Fatalf("pinned snapshot changed: port=%d version=%d", snap.Config().Port, snap.Version())
+	}
+	if now := loader.Pin(); now.Config().Port != 9090 || now.Version() != 2 {
+		t.Fatalf("new snapshot = port %d version %d, want 9090/2", now.Config().Port, now.Version())
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	type Config struct {
+		Port     int
+		Password string
+		DB       struct {
+			Host string
+		}
+	}
+
+	a := &Config{Port: 80, Password: "one"}
+	b := &Config{Port: 80, Password: "two"}
+
+	if Fingerprint(a) != Fingerprint(b) {
+		t.Error("expected Fingerprint to ignore secret values")
+	}
+	if FingerprintWithSecrets(a) == FingerprintWithSecrets(b) {
+		t.Error("expected FingerprintWithSecrets to detect secret changes")
+	}
+	if len(Fingerprint(a)) != 64 {
+		t.Errorf("unexpected fingerprint %q", Fingerprint(a))
+	}
+
+	b.DB.Host = "other"
+	if Fingerprint(a) == Fingerprint(b) {
+		t.Error("expected Fingerprint to change with non-secret values")
+	}
+
+	if got := Fingerprint[Config](nil); got != "" {
+		t.Errorf("Fingerprint(nil) = %q, want empty", got)
+	}
+	n := 1
+	if got := Fingerprint(&n); got != "" {
+		t.Errorf("Fingerprint(non-struct) = %q, want empty", got)
+	}
+}
+
+func TestFingerprintHandler(t *testing.T) {
+	type Config struct {
+		Port int `default:"8080"`
+	}
+
+	l := NewLoader[Config](WithProvider(Defaults[Config]()))
+	srv := httptest.NewServer(FingerprintHandler(l))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("status before load = %d, want 503", resp.StatusCode)
+	}
+
+	l.MustLoad()
+	resp, err = http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var info FingerprintInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Fingerprint != Fingerprint(l.Get()) || info.Version != 1 {
+		t.Fatalf("unexpected info: %#v", info)
+	}
+}
+
+func TestLoad_RequiredProfile(t *testing.T) {
+	type Config struct {
+		DSN    string `required:"staging, prod"`
+		Token  string `required:"false"`
+		Always string `required:"true" default:"x"`
+	}
+
+	if _, err := Load[Config](); err != nil {
+		t.Fatalf("expected no requirement without profile, got %v", err)
+	}
+	if _, err := Load[Config](WithProfile("local")); err != nil {
+		t.Fatalf("expected no requirement in local, got %v", err)
+	}
+
+	_, err := Load[Config](WithProfile("PROD"))
+	var envErr *Error
+	if !errors.As(err, &envErr) || envErr.Field != "DSN" || !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected DSN required in prod, got %v", err)
+	}
+}
+
+func TestLoad_DevFill(t *testing.T) {
+	type Config struct {
+		APIToken string `required:"true"`
+		Nested   struct {
+			Secret string `required:"local"`
+		}
+		Name string `required:"true" default:"svc"`
+	}
+
+	var buf bytes.Buffer
+	cfg, err := Load[Config](WithProfile("local"), WithDevFill(), WithOutput(&buf))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(cfg.APIToken, "dev-") || !strings.HasPrefix(cfg.Nested.Secret, "dev-") {
+		t.Fatalf("expected generated placeholders, got %#v", cfg)
+	}
+	if cfg.APIToken == cfg.Nested.Secret {
+		t.Fatal("expected distinct placeholders")
+	}
+	if !strings.Contains(buf.String(), "API_TOKEN") || !strings.Contains(buf.String(), "NESTED_SECRET") {
+		t.Fatalf("expected loud log lines, got %q", buf.String())
+	}
+
+	if _, err := Load[Config](WithProfile("prod"), WithDevFill()); !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected ErrRequired outside local profile, got %v", err)
+	}
+
+	// Reloads keep the placeholders rather than rotate them.
+	src := &mutableProvider{values: map[string]any{"NAME": "a"}}
+	loader := NewLoader[Config](WithProvider(src), WithProfile("local"), WithDevFill(), WithOutput(io.Discard))
+	first := loader.MustLoad().APIToken
+	src.Set("NAME", "b")
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := loader.Get(); got.Name != "b" || got.APIToken != first {
+		t.Fatalf("placeholder rotated on reload: %q, then %+v", first, *got)
+	}
+}
+
+func TestPromptProvider(t *testing.T) {
+	type Config struct {
+		DatabaseURL string `required:"true"`
+		APIToken    string `required:"true"`
+		Region      string `required:"true" default:"us-east-1"`
+		Optional    string
+	}
+
+	path := filepath.Join(t.TempDir(), "answers.json")
+	var out bytes.Buffer
+	prompt := &promptProvider{in: strings.NewReader("postgres://db\r\nsecret\n"), out: &out, persist: path}
+
+	cfg, err := Load[Config](WithPrefix("APP"), WithProvider(DefaultsWithPrefix[Config]("APP")), WithProvider(prompt))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.DatabaseURL != "postgres://db" || cfg.APIToken != "secret" || cfg.Region != "us-east-1" {
+		t.Fatalf("unexpected config: %#v", cfg)
+	}
+	if got := out.String(); got != "APP_DATABASE_URL: APP_API_TOKEN: " {
+		t.Fatalf("unexpected prompts %q", got)
+	}
+
+	if info, err := os.Stat(path); err != nil || runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Fatalf("answers file: %v, %v; want mode 0600", info, err)
+	}
+
+	again := PromptAndSave(path)
+	cfg, err = Load[Config](WithPrefix("APP"), WithProvider(DefaultsWithPrefix[Config]("APP")), WithProvider(again))
+	if err != nil {
+		t.Fatalf("expected saved answers to satisfy required fields, got %v", err)
+	}
+	if cfg.APIToken != "secret" {
+		t.Fatalf("APIToken = %q, want saved answer", cfg.APIToken)
+	}
+	if vals, err := again.Values(); err != nil || vals["APP_API_TOKEN"] != "secret" {
+		t.Fatalf("Values() = %v, %v", vals, err)
+	}
+
+	empty := &promptProvider{in: strings.NewReader("\n"), out: io.Discard}
+	if _, err := Load[Config](WithProvider(empty)); !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected ErrRequired when answer is empty, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load[Config](WithProvider(PromptAndSave(path))); !errors.Is(err, ErrParse) {
+		t.Fatalf("expected ErrParse for corrupt answers file, got %v", err)
+	}
+	if _, err := PromptAndSave(path).Values(); !errors.Is(err, ErrParse) {
+		t.Fatalf("expected ErrParse from Values, got %v", err)
+	}
+	if vals, err := Prompt().Values(); err != nil || len(vals) != 0 {
+		t.Fatalf("Prompt().Values() = %v, %v", vals, err)
+	}
+}
+
+func TestCompletionWords(t *testing.T) {
+	type Config struct {
+		Port   int
+		hidden string
+		DB     struct {
+			Host string
+		}
+	}
+
+	got := CompletionWords[Config](WithPrefix("app"))
+	want := []string{"APP_DB_HOST=", "APP_PORT="}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CompletionWords = %v, want %v", got, want)
+	}
+	if got := CompletionWords[int](); got != nil {
+		t.Fatalf("CompletionWords[int] = %v, want nil", got)
+	}
+}
+
+func TestWithSetFlags(t *testing.T) {
+	t.Setenv("APP_PORT", "7000")
+
+	type Config struct {
+		Port int    `default:"8080"`
+		Host string `default:"localhost"`
+	}
+
+	cfg, err := Load[Config](WithPrefix("APP"), WithSetFlags([]string{"APP_PORT=9090", "APP_HOST=a=b"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 9090 || cfg.Host != "a=b" {
+		t.Fatalf("unexpected config: %#v", cfg)
+	}
+
+	type Full struct {
+		Port int    `default:"8080"`
+		Host string `default:"localhost"`
+		Mode string `default:"dev"`
+	}
+	t.Setenv("APP_HOST", "fromenv")
+	full, err := Load[Full](WithPrefix("APP"), WithSetFlags([]string{"APP_MODE=prod"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (Full{Port: 7000, Host: "fromenv", Mode: "prod"}); *full != want {
+		t.Fatalf("defaults and env lost beside --set: %+v, want %+v", *full, want)
+	}
+	full, err = Load[Full](WithPrefix("APP"), WithSetFlags(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (Full{Port: 7000, Host: "fromenv", Mode: "dev"}); *full != want {
+		t.Fatalf("WithSetFlags(nil) = %+v, want %+v", *full, want)
+	}
+
+	_, err = Load[Config](WithSetFlags([]string{"PORT=1", "oops", "=x"}))
+	if !errors.Is(err, ErrParse) || !strings.Contains(err.Error(), "oops") || !strings.Contains(err.Error(), `"=x"`) {
+		t.Fatalf("expected parse errors for malformed flags, got %v", err)
+	}
+}
+
+func TestFileProviderProperties(t *testing.T) {
+	content := "# comment\r\n! also comment\n" +
+		"server.host = example.com\n" +
+		"server.maxConns: 25\n" +
+		"app-name  my\\ app\n" +
+		"greeting=caf\\u00e9 \\\n    au lait\n" +
+		"path=C:\\\\temp\\tdir\n" +
+		"key\\=with\\:seps=ok\n" +
+		"empty\n" +
+		"bad.escape=\\uZZZZ\n" +
+		"trailing=end\\"
+	path := filepath.Join(t.TempDir(), "app.properties")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := File(path).Values()
+	if err != nil {
+		t.Fatalf("Values: %v", err)
+	}
+	want := map[string]any{
+		"SERVER_HOST":      "example.com",
+		"SERVER_MAX_CONNS": "25",
+		"APP_NAME":         "my app",
+		"GREETING":         "café au lait",
+		"PATH":             "C:\\temp\tdir",
+		"KEY=WITH:SEPS":    "ok",
+		"EMPTY":            "",
+		"BAD_ESCAPE":       "uZZZZ",
+		"TRAILING":         "end",
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Fatalf("values = %#v, want %#v", values, want)
+	}
+
+	latin1 := filepath.Join(t.TempDir(), "latin1.properties")
+	if err := os.WriteFile(latin1, []byte("name=Jos\xe9"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	values, err = File(latin1).Values()
+	if err != nil || values["NAME"] != "José" {
+		t.Fatalf("expected Latin-1 decoding, got %#v, %v", values, err)
+	}
+}
+
+func TestLoad_OptionalSection(t *testing.T) {
+	type TLSConfig struct {
+		Cert string `required:"true"`
+		Port int    `default:"443"`
+	}
+	type Config struct {
+		Host string
+		TLS  *TLSConfig
+	}
+
+	cfg, err := Load[Config](WithProvider(Map(map[string]string{"HOST": "localhost"})))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.TLS != nil {
+		t.Fatalf("expected nil TLS section, got %#v", cfg.TLS)
+	}
+
+	var buf bytes.Buffer
+	PrintTo(&buf, cfg)
+	if !strings.Contains(buf.String(), "TLS: <nil>") {
+		t.Fatalf("expected nil section in output, got:\n%s", buf.String())
+	}
+
+	cfg, err = Load[Config](WithPrefix("APP"), WithProvider(Map(map[string]string{"TLS_CERT": "/etc/cert.pem"})))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.TLS == nil || cfg.TLS.Cert != "/etc/cert.pem" || cfg.TLS.Port != 443 {
+		t.Fatalf("unexpected TLS section: %#v", cfg.TLS)
+	}
+
+	_, err = Load[Config](WithProvider(Map(map[string]string{"TLS_PORT": "8443"})))
+	var envErr *Error
+	if !errors.As(err, &envErr) || envErr.Field != "TLS_CERT" || !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected TLS_CERT required error, got %v", err)
+	}
+
+	// A sibling key sharing the section's prefix does not create it.
+	type Shared struct {
+		TLSMode string
+		TLS     *TLSConfig
+	}
+	shared, err := Load[Shared](WithProvider(Map(map[string]string{"TLS_MODE": "strict"})))
+	if err != nil || shared.TLS != nil || shared.TLSMode != "strict" {
+		t.Fatalf("expected nil TLS section beside TLS_MODE, got %+v, %v", shared, err)
+	}
+}
+
+func TestLoad_EnabledGatedSection(t *testing.T) {
+	type Config struct {
+		Tracing struct {
+			Enabled  bool
+			Endpoint string `required:"true"`
+		}
+	}
+
+	cfg, err := Load[Config](WithProvider(Map(map[string]string{"TRACING_ENABLED": "false"})))
+	if err != nil {
+		t.Fatalf("disabled section should skip required fields: %v", err)
+	}
+	if cfg.Tracing.Enabled {
+		t.Fatal("expected tracing disabled")
+	}
+
+	_, err = Load[Config](WithProvider(Map(map[string]string{"TRACING_ENABLED": "true"})))
+	if !errors.Is(err, ErrRequired) || !strings.Contains(err.Error(), "TRACING_ENDPOINT") {
+		t.Fatalf("expected TRACING_ENDPOINT required, got %v", err)
+	}
+
+	cfg, err = Load[Config](WithProvider(Map(map[string]string{"TRACING_ENABLED": "true", "TRACING_ENDPOINT": "otel:4317"})))
+	if err != nil || cfg.Tracing.Endpoint != "otel:4317" {
+		t.Fatalf("unexpected result: %#v, %v", cfg, err)
+	}
+}
+
+func TestLoad_InheritedSections(t *testing.T) {
+	type DatabaseConfig struct {
+		Host string `default:"localhost"`
+		Port int    `default:"5432"`
+		Name string
+	}
+	type Config struct {
+		Primary DatabaseConfig `inherit:"DATABASE_COMMON"`
+		Replica DatabaseConfig `inherit:"DATABASE_COMMON"`
+	}
+
+	cfg, err := Load[Config](
+		WithPrefix("APP"),
+		WithProvider(DefaultsWithPrefix[Config]("APP")),
+		WithProvider(Map(map[string]string{
+			"DATABASE_COMMON_HOST": "db.internal",
+			"DATABASE_COMMON_NAME": "app",
+			"REPLICA_HOST":         "replica.internal",
+		})),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Config{
+		Primary: DatabaseConfig{Host: "db.internal", Port: 5432, Name: "app"},
+		Replica: DatabaseConfig{Host: "replica.internal", Port: 5432, Name: "app"},
+	}
+	if *cfg != want {
+		t.Fatalf("cfg = %#v, want %#v", *cfg, want)
+	}
+}
+
+func TestLoadWith_OptionsStruct(t *testing.T) {
+	type Config struct {
+		Host  string
+		Port  int
+		Token string `required:"prod"`
+	}
+
+	var opts Options
+	if err := json.Unmarshal([]byte(`{"prefix":"app","profile":"dev","set":["APP_PORT=9090"]}`), &opts); err != nil {
+		t.Fatal(err)
+	}
+	opts.Providers = []Provider{Map(map[string]string{"HOST": "localhost", "PORT": "80"})}
+
+	cfg, err := LoadWith[Config](opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Host != "localhost" || cfg.Port != 9090 {
+		t.Fatalf("unexpected config: %#v", cfg)
+	}
+
+	opts.Profile = "prod"
+	loader := NewLoaderWith[Config](opts)
+	if _, err := loader.Load(); !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected ErrRequired in prod profile, got %v", err)
+	}
+
+	cfg, err = Load[Config](Options{Prefix: "APP"}.Option(), WithProvider(Map(map[string]string{"HOST": "mixed"})))
+	if err != nil || cfg.Host != "mixed" {
+		t.Fatalf("mixing styles: %#v, %v", cfg, err)
+	}
+
+	var later Options
+	data := `{"strict":true,"fileSecrets":true,"approval":true,"maxValueSize":64,"fetchTimeout":1000000000,
+		"reloadWindow":"02:00-04:00","watchPaths":["a.json","b.json"],"watchInterval":1000000000,
+		"schema":{"type":"object","properties":{"PORT":{"type":"integer","maximum":100}}}}`
+	if err := json.Unmarshal([]byte(data), &later); err != nil {
+		t.Fatal(err)
+	}
+	o := prepareOptions[Config]([]Option{later.Option()})
+	if !o.strict || !o.fileSecrets || !o.approval || o.maxValue != 64 || o.fetchTimeout != time.Second ||
+		o.reloadWindow == nil || len(o.watches) != 2 || o.schema == nil {
+		t.Fatalf("Options fields not applied: %+v", o)
+	}
+}
+
+func TestLoaderValidate(t *testing.T) {
+	type Config struct{ Port int }
+	type Other struct{ Host string }
+
+	loader := NewLoader[Config](WithProvider(File("config.json")), WithWatch("config.json", time.Second))
+	if err := loader.Validate(); err != nil {
+		t.Fatalf("expected valid loader, got %v", err)
+	}
+
+	loader = NewLoader[Config](
+		WithProvider(Map(map[string]string{"PORT": "80"})),
+		WithWatch("config.json", 0),
+		WithOnReload(func(old, new *Other) {}),
+		WithPrecedence("map", "vault"),
+		WithMaxReloadRate(5, 0),
+	)
+	err := loader.Validate()
+	var multi *MultiError
+	if !errors.Is(err, ErrInvalidOptions) || !errors.As(err, &multi) || len(multi.Errors) != 5 {
+		t.Fatalf("expected a MultiError of five ErrInvalidOptions, got %v", err)
+	}
+	for _, want := range []string{
+		"WithOnReload: invalid options: callback expects envx.Other",
+		"WithWatch: invalid options: interval must be greater than zero",
+		"no File provider is registered",
+		`no provider named "vault"`,
+		"WithMaxReloadRate",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), `"map"`) {
+		t.Errorf("known provider reported as unknown: %v", err)
+	}
+}
+
+func TestLoadTyped(t *testing.T) {
+	type Config struct {
+		Port int `default:"8080"`
+	}
+
+	validated := false
+	cfg, err := LoadTyped[Config](
+		For[Config](WithPrefix("APP"), WithProvider(DefaultsWithPrefix[Config]("APP"))),
+		ValidatorFor(func(c *Config) error {
+			validated = true
+			return nil
+		}),
+		OnReloadFor(func(old, new *Config) {}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 8080 || !validated {
+		t.Fatalf("unexpected result: %#v, validated=%v", cfg, validated)
+	}
+
+	loader := NewTypedLoader[Config](For[Config](WithProvider(Map(map[string]string{"PORT": "1"}))))
+	if err := loader.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if cfg := loader.MustLoad(); cfg.Port != 1 {
+		t.Fatalf("unexpected port: %d", cfg.Port)
+	}
+
+	// For cannot check its options at compile time, so it checks them at load.
+	type Other struct{ Port int }
+	_, err = LoadTyped[Config](For[Config](
+		WithOnReload(func(old, new *Other) {}),
+		WithValidator(func(*Other) error { return nil }),
+		WithOnFieldChange(func(c *Other) any { return c.Port }, func(old, new any) {}),
+	))
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Fatalf("expected ErrInvalidOptions, got %v", err)
+	}
+	for _, option := range []string{"WithOnReload", "WithValidator", "WithOnFieldChange"} {
+		if !strings.Contains(err.Error(), option) {
+			t.Errorf("expected %s reported, got %v", option, err)
+		}
+	}
+
+	// Neither an Option nor another type's OptionFor converts to OptionFor.
+	typed := reflect.TypeOf(OptionFor[Config]{})
+	for _, other := range []reflect.Type{reflect.TypeOf(Option(nil)), reflect.TypeOf(OptionFor[Other]{})} {
+		if other.ConvertibleTo(typed) {
+			t.Errorf("%s converts to %s", other, typed)
+		}
+	}
+	if _, err := LoadTyped[Config](OptionFor[Config]{}); err != nil {
+		t.Fatalf("a zero OptionFor must be a no-op, got %v", err)
+	}
+}
+
+func TestLoad_RequiredAny(t *testing.T) {
+	type Config struct {
+		Redis struct {
+			URL           string   `requiredAny:"redis"`
+			SentinelAddrs []string `requiredAny:"redis"`
+		}
+	}
+
+	_, err := Load[Config](WithProvider(Map(map[string]string{})))
+	var envErr *Error
+	if !errors.As(err, &envErr) || !errors.Is(err, ErrRequired) || envErr.Field != "redis" {
+		t.Fatalf("expected redis group error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "REDIS_URL, REDIS_SENTINEL_ADDRS") {
+		t.Fatalf("expected all alternatives in %q", err)
+	}
+
+	for _, values := range []map[string]string{
+		{"REDIS_URL": "redis://localhost"},
+		{"REDIS_SENTINEL_ADDRS": "a:26379,b:26379"},
+	} {
+		if _, err := Load[Config](WithProvider(Map(values))); err != nil {
+			t.Fatalf("unexpected error for %v: %v", values, err)
+		}
+	}
+}
+
+func TestLoad_Normalize(t *testing.T) {
+	RegisterNormalizer("noslash", func(s string) string { return strings.TrimSuffix(s, "/") })
+
+	type Config struct {
+		Env      string   `normalize:"trim,lower"`
+		Region   string   `normalize:"upper"`
+		Backends []string `normalize:"noslash"`
+		Raw      string
+	}
+
+	cfg, err := Load[Config](WithProvider(Map(map[string]string{
+		"ENV":      "  Production \t",
+		"REGION":   "eu-west-1",
+		"BACKENDS": "http://a/, http://b",
+		"RAW":      " Keep ",
+	})))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Config{Env: "production", Region: "EU-WEST-1", Backends: []string{"http://a", "http://b"}, Raw: " Keep "}
+	if !reflect.DeepEqual(*cfg, want) {
+		t.Fatalf("cfg = %#v, want %#v", *cfg, want)
+	}
+
+	type Bad struct {
+		Name string `normalize:"nope"`
+	}
+	_, err = Load[Bad](WithProvider(Map(map[string]string{"NAME": "x"})))
+	if !errors.Is(err, ErrParse) || !strings.Contains(err.Error(), `unknown normalizer "nope"`) {
+		t.Fatalf("expected unknown normalizer error, got %v", err)
+	}
+}
+
+func TestLoad_URLList(t *testing.T) {
+	type Config struct {
+		Endpoints URLList `schemes:"http,https"`
+		Mirrors   URLList
+	}
+
+	cfg, err := Load[Config](WithProvider(Map(map[string]string{
+		"ENDPOINTS": "https://a.example.com/api, http://b.example.com:8080",
+		"MIRRORS":   "ftp://mirror.example.com",
+	})))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Endpoints) != 2 || cfg.Endpoints[0].Host != "a.example.com" || cfg.Endpoints[1].Port() != "8080" {
+		t.Fatalf("unexpected endpoints: %v", cfg.Endpoints)
+	}
+	if cfg.Endpoints.String() != "https://a.example.com/api,http://b.example.com:8080" {
+		t.Fatalf("unexpected String(): %s", cfg.Endpoints)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := WriteFileAtomic(path, cfg, FormatJSON); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+	again, err := Load[Config](WithProvider(File(path)))
+	if err != nil || again.Endpoints.String() != cfg.Endpoints.String() {
+		t.Fatalf("round trip failed: %v, %v", again, err)
+	}
+
+	_, err = Load[Config](WithProvider(Map(map[string]string{"ENDPOINTS": "https://ok, ftp://nope"})))
+	if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), `scheme "ftp"`) {
+		t.Fatalf("expected scheme validation error, got %v", err)
+	}
+
+	_, err = Load[Config](WithProvider(Map(map[string]string{"MIRRORS": "not a url"})))
+	if !errors.Is(err, ErrParse) {
+		t.Fatalf("expected parse error for relative URL, got %v", err)
+	}
+}
+
+type fakeResolver struct {
+	hosts map[string]bool
+	srv   map[string]*net.SRV
+}
+
+func (r fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if r.hosts[host] {
+		return []string{"10.0.0.1"}, nil
+	}
+	return nil, fmt.Errorf("no such host")
+}
+
+func (r fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	if rec, ok := r.srv[name]; ok {
+		return name, []*net.SRV{rec}, nil
+	}
+	return "", nil, fmt.Errorf("no such host")
+}
+
+func TestLoad_HostPort(t *testing.T) {
+	type Config struct {
+		DB       HostPort `default:"localhost:5432"`
+		Peers    []HostPort
+		Postgres HostPort `resolve:"srv"`
+	}
+
+	cfg, err := Load[Config](
+		WithProvider(Defaults[Config]()),
+		WithProvider(Map(map[string]string{"PEERS": "a.internal:7000,[::1]:7001", "POSTGRES": "_pg._tcp.example.com"})),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.DB != (HostPort{Host: "localhost", Port: 5432}) || len(cfg.Peers) != 2 || cfg.Peers[1].String() != "[::1]:7001" {
+		t.Fatalf("unexpected config: %#v", cfg)
+	}
+	if cfg.Postgres.Host != "_pg._tcp.example.com" || cfg.Postgres.Port != 0 {
+		t.Fatalf("SRV name should be kept until resolved: %#v", cfg.Postgres)
+	}
+
+	for _, bad := range []string{"localhost", "localhost:http", ":80", "host:70000"} {
+		if _, err := Load[Config](WithProvider(Map(map[string]string{"DB": bad}))); !errors.Is(err, ErrParse) {
+			t.Errorf("%q: expected ErrParse, got %v", bad, err)
+		}
+	}
+	for key, bad := range map[string]string{"DB": "_pg._tcp.example.com", "PEERS": "a.internal:7000,_pg._tcp.example.com"} {
+		_, err := Load[Config](WithProvider(Map(map[string]string{key: bad})))
+		if !errors.Is(err, ErrParse) || !strings.Contains(err.Error(), "missing port") {
+			t.Errorf("%s=%q: a bare SRV name needs resolve:\"srv\", got %v", key, bad, err)
+		}
+	}
+
+	orig := resolver
+	defer func() { resolver = orig }()
+	resolver = fakeResolver{
+		hosts: map[string]bool{"localhost": true, "a.internal": true},
+		srv:   map[string]*net.SRV{"_pg._tcp.example.com": {Target: "pg1.example.com.", Port: 5433}},
+	}
+
+	values := map[string]string{"DB": "localhost:5432", "PEERS": "a.internal:7000,b.internal:7000", "POSTGRES": "_pg._tcp.example.com"}
+	_, err = Load[Config](WithProvider(Map(values)), WithResolve(ResolveError, time.Second))
+	if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), "b.internal:7000") {
+		t.Fatalf("expected resolve error for b.internal, got %v", err)
+	}
+
+	var logs bytes.Buffer
+	cfg, err = Load[Config](WithProvider(Map(values)), WithResolve(ResolveWarn, time.Second), WithOutput(&logs))
+	if err != nil {
+		t.Fatalf("warn mode should not fail: %v", err)
+	}
+	if !strings.Contains(logs.String(), "PEERS: cannot resolve b.internal:7000") {
+		t.Fatalf("expected warning, got %q", logs.String())
+	}
+	if cfg.Postgres != (HostPort{Host: "pg1.example.com", Port: 5433}) {
+		t.Fatalf("expected SRV target, got %#v", cfg.Postgres)
+	}
+}
+
+func TestExportEnvDialects(t *testing.T) {
+	values := map[string]string{
+		"PLAIN":   "value",
+		"SPACES":  "  padded value ",
+		"QUOTES":  `it's "quoted"`,
+		"NEWLINE": "line1\nline2\\n",
+		"HASH":    "#not-a-comment",
+		"EMPTY":   "",
+	}
+
+	var dotenv bytes.Buffer
+	if err := ExportEnv(&dotenv, values, DialectDotEnv); err != nil {
+		t.Fatalf("dotenv: %v", err)
+	}
+	if got := parseDotEnv(dotenv.Bytes()); !reflect.DeepEqual(got, values) {
+		t.Fatalf("dotenv round trip:\n%s\ngot %#v", dotenv.String(), got)
+	}
+
+	var ps bytes.Buffer
+	if err := ExportEnv(&ps, map[string]string{"QUOTES": "it's", "my-key": "x"}, DialectPowerShell); err != nil {
+		t.Fatalf("powershell: %v", err)
+	}
+	if want := "$env:QUOTES = 'it''s'\n${env:my-key} = 'x'\n"; ps.String() != want {
+		t.Fatalf("powershell output = %q, want %q", ps.String(), want)
+	}
+
+	var posix bytes.Buffer
+	if err := ExportEnv(&posix, values, DialectPOSIX); err != nil {
+		t.Fatalf("posix: %v", err)
+	}
+	if err := ExportEnv(io.Discard, map[string]string{"my-key": "x"}, DialectPOSIX); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("expected invalid name error, got %v", err)
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	script := posix.String() + `for k in EMPTY HASH NEWLINE PLAIN QUOTES SPACES; do eval "printf '%s\0' \"\$$k\""; done`
+	out, err := exec.Command("sh", "-c", script).Output()
+	if err != nil {
+		t.Fatalf("sh: %v", err)
+	}
+	got := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	want := []string{values["EMPTY"], values["HASH"], values["NEWLINE"], values["PLAIN"], values["QUOTES"], values["SPACES"]}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("posix round trip = %q, want %q", got, want)
+	}
+}
+
+func TestEncodeDotEnvRoundTrip(t *testing.T) {
+	cases := []map[string]string{
+		{},
+		{"EMPTY": ""},
+		{"SPACES": "  a  b  ", "TABS": "\tx\t"},
+		{"SINGLE": "'", "DOUBLE": `"`, "MIXED": `'"'"`, "BOTH_ENDS": `"x"`},
+		{"BACKSLASH": `C:\temp\`, "ESCAPES": `\n\t\"`},
+		{"MULTI": "line1\nline2\n", "CRLF": "a\r\nb", "LEADING_NL": "\n x"},
+		{"HASH": "# x", "INLINE": "a # b", "EQUALS": "a=b=c"},
+		{"UNICODE": "café ☕", "lower.key": "v"},
+	}
+	for _, values := range cases {
+		data, err := EncodeDotEnv(values)
+		if err != nil {
+			t.Fatalf("EncodeDotEnv(%q): %v", values, err)
+		}
+		if got := parseDotEnv(data); !reflect.DeepEqual(got, values) {
+			t.Errorf("round trip of %q via\n%s\ngot %q", values, data, got)
+		}
+	}
+
+	for _, key := range []string{"", " KEY", "A=B", "A\nB", "#KEY"} {
+		if _, err := EncodeDotEnv(map[string]string{key: "x"}); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("key %q: expected ErrUnsupportedType, got %v", key, err)
+		}
+	}
+}
+
+func TestParseDotEnvMultiline(t *testing.T) {
+	data := []byte("CERT=\"-----BEGIN-----\r\n  abc==\r\nZm9v=\r\n-----END-----\"\r\nNEXT=1\nOPEN=\"unterminated\nLAST=2\n" +
+		"B=\"x\nC=\"y\"\n")
+	want := map[string]string{
+		"CERT": "-----BEGIN-----\n  abc==\nZm9v=\n-----END-----",
+		"NEXT": "1",
+		"OPEN": `"unterminated`,
+		"LAST": "2",
+		"B":    `"x`,
+		"C":    "y",
+	}
+	if got := parseDotEnv(data); !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseDotEnv = %q, want %q", got, want)
+	}
+}
+
+func TestParseDotEnvKeepsLiteralBackslashes(t *testing.T) {
+	data := []byte(`DIR="C:\new\table"` + "\n" +
+		`SHARE="\\fs01\share\new"` + "\n" +
+		`PATTERN="a\nb\d"` + "\n" +
+		`QUOTED="say \"hi\""` + "\n" +
+		`NEWLINE="C:\new"` + "\n" +
+		`UNMARKED="line1\nline2"` + "\n" +
+		`ENCODED="line1\nsay \"hi\"\\" # envx:escaped` + "\n")
+	want := map[string]string{
+		"NEWLINE":  `C:\new`,
+		"UNMARKED": `line1\nline2`,
+		"DIR":      `C:\new\table`,
+		"SHARE":    `\\fs01\share\new`,
+		"PATTERN":  `a\nb\d`,
+		"QUOTED":   `say \"hi\"`,
+		"ENCODED":  "line1\nsay \"hi\"\\",
+	}
+	if got := parseDotEnv(data); !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseDotEnv = %q, want %q", got, want)
+	}
+}
+
+func TestLoad_TimeOfDayAndCron(t *testing.T) {
+	type Config struct {
+		QuietStart TimeOfDay    `default:"23:30"`
+		Backup     CronSchedule `default:"@daily"`
+		Report     CronSchedule
+	}
+
+	cfg, err := Load[Config](
+		WithProvider(Defaults[Config]()),
+		WithProvider(Map(map[string]string{"REPORT": "*/15 9-17 * * mon-fri"})),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.QuietStart != (TimeOfDay{Hour: 23, Minute: 30}) || cfg.QuietStart.String() != "23:30" {
+		t.Fatalf("unexpected time of day: %#v", cfg.QuietStart)
+	}
+
+	day := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC) // a Friday
+	if got := cfg.QuietStart.On(day); !got.Equal(time.Date(2026, 3, 6, 23, 30, 0, 0, time.UTC)) {
+		t.Fatalf("On = %v", got)
+	}
+	if got := cfg.Backup.Next(day.Add(time.Hour)); !got.Equal(day.AddDate(0, 0, 1)) {
+		t.Fatalf("@daily Next = %v", got)
+	}
+	if got := cfg.Report.Next(day.Add(17*time.Hour + 50*time.Minute)); !got.Equal(time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("weekday Next = %v", got)
+	}
+	if cfg.Report.String() != "*/15 9-17 * * mon-fri" {
+		t.Fatalf("String = %q", cfg.Report)
+	}
+
+	cases := map[string]time.Time{
+		"@every 90m":    day.Add(90 * time.Minute),
+		"0 12 13 * 5":   time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC),
+		"30 2 29 feb *": time.Date(2028, 2, 29, 2, 30, 0, 0, time.UTC),
+		"0 0 30 2 *":    {},
+	}
+	for spec, want := range cases {
+		s, err := ParseCronSchedule(spec)
+		if err != nil {
+			t.Fatalf("%q: %v", spec, err)
+		}
+		if got := s.Next(day); !got.Equal(want) {
+			t.Errorf("%q: Next = %v, want %v", spec, got, want)
+		}
+	}
+
+	for _, bad := range []string{"* * * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *", "@often", "@every 1ms"} {
+		if _, err := Load[Config](WithProvider(Map(map[string]string{"REPORT": bad}))); !errors.Is(err, ErrParse) {
+			t.Errorf("%q: expected ErrParse, got %v", bad, err)
+		}
+	}
+	for _, bad := range []string{"24:00", "7:30", "12", "12:60"} {
+		if _, err := Load[Config](WithProvider(Map(map[string]string{"QUIET_START": bad}))); !errors.Is(err, ErrParse) {
+			t.Errorf("%q: expected ErrParse, got %v", bad, err)
+		}
+	}
+}
+
+func TestLoad_Locale(t *testing.T) {
+	type Config struct {
+		DefaultLocale Locale `default:"en-US"`
+		Supported     []Locale
+	}
+
+	cfg, err := Load[Config](
+		WithProvider(Defaults[Config]()),
+		WithProvider(Map(map[string]string{"SUPPORTED": "pt_br, zh-hant-tw, de-CH-1996, en-u-ca-gregory, x-klingon, sr-Latn"})),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.DefaultLocale != "en-US" || cfg.DefaultLocale.Language() != "en" || cfg.DefaultLocale.Region() != "US" {
+		t.Fatalf("unexpected default locale: %q", cfg.DefaultLocale)
+	}
+	want := []Locale{"pt-BR", "zh-Hant-TW", "de-CH-1996", "en-u-ca-gregory", "x-klingon", "sr-Latn"}
+	if !reflect.DeepEqual(cfg.Supported, want) {
+		t.Fatalf("Supported = %q, want %q", cfg.Supported, want)
+	}
+	if tw := cfg.Supported[1]; tw.Script() != "Hant" || tw.Region() != "TW" {
+		t.Fatalf("unexpected subtags of %q: %q %q", tw, tw.Script(), tw.Region())
+	}
+	if ext := cfg.Supported[3]; ext.Region() != "" || ext.Language() != "en" {
+		t.Fatalf("extension subtags leaked into %q", ext)
+	}
+
+	for _, bad := range []string{"", "e", "engl-US", "pt-BR-", "en--US", "en-u", "en-US-a-", "toolongtag-US", "en-ÜS"} {
+		if _, err := ParseLocale(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+	if _, err := Load[Config](WithProvider(Map(map[string]string{"DEFAULT_LOCALE": "en_US_POSIX!"}))); !errors.Is(err, ErrParse) {
+		t.Fatalf("expected ErrParse, got %v", err)
+	}
+}
+
+func TestLoad_FormatCodes(t *testing.T) {
+	if n := len(formats["iso3166"]); n != 249 || len(formats["iso3166-alpha3"]) != n {
+		t.Fatalf("unexpected country list sizes: %d alpha-2, %d alpha-3", n, len(formats["iso3166-alpha3"]))
+	}
+
+	type Config struct {
+		Currency  string   `format:"iso4217"`
+		Countries []string `format:"iso3166" normalize:"upper"`
+		Origin    string   `format:"iso3166-alpha3"`
+	}
+
+	cfg, err := Load[Config](WithProvider(Map(map[string]string{"CURRENCY": "BRL", "COUNTRIES": "br,pt,us", "ORIGIN": "PRT"})))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Countries, []string{"BR", "PT", "US"}) {
+		t.Fatalf("unexpected countries: %v", cfg.Countries)
+	}
+
+	bad := []map[string]string{
+		{"CURRENCY": "BRX"},
+		{"CURRENCY": "usd"},
+		{"COUNTRIES": "BR,UK"},
+		{"ORIGIN": "PT"},
+	}
+	for _, values := range bad {
+		_, err := Load[Config](WithProvider(Map(values)))
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("%v: expected ErrValidation, got %v", values, err)
+		}
+	}
+
+	type Unknown struct {
+		Code string `format:"iso9999"`
+	}
+	if _, err := Load[Unknown](WithProvider(Map(map[string]string{"CODE": "x"}))); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("expected ErrUnsupportedType, got %v", err)
+	}
+}
+
+func FuzzParseDotEnv(f *testing.F) {
+	f.Add([]byte("KEY=value\n# comment\nQUOTED=\"a\\nb\"\nSINGLE='x'\n"))
+	f.Add([]byte("MULTI=\"line1\r\nline2\"\nOPEN=\"unterminated\n=novalue\nNOEQ\n"))
+	f.Add([]byte("KEY=\"\\\\\"\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for k := range ParseDotEnv(data) {
+			if k != strings.TrimSpace(k) || strings.Contains(k, "\n") {
+				t.Fatalf("untrimmed key %q", k)
+			}
+		}
+	})
+}
+
+func FuzzEncodeDotEnv(f *testing.F) {
+	f.Add("KEY", "value")
+	f.Add("CERT", "line1\nline2\r\n")
+	f.Add("QUOTES", `'"\"'`)
+	f.Add("SPACES", "  \t ")
+	f.Fuzz(func(t *testing.T, key, value string) {
+		values := map[string]string{key: value}
+		data, err := EncodeDotEnv(values)
+		if err != nil {
+			return
+		}
+		if got := ParseDotEnv(data); !reflect.DeepEqual(got, values) {
+			t.Fatalf("round trip of %q via %q: got %q", values, data, got)
+		}
+	})
+}
+
+func FuzzFlatten(f *testing.F) {
+	f.Add([]byte(`{"db":{"poolSize":5,"hosts":["a","b"]},"name":"x"}`))
+	f.Add([]byte(`{"a":{"b":{"c":{}}}}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var m map[string]any
+		if json.Unmarshal(data, &m) != nil {
+			return
+		}
+		for k, v := range Flatten(m) {
+			if _, nested := v.(map[string]any); nested {
+				t.Fatalf("key %q still holds a map", k)
+			}
+		}
+	})
+}
+
+func FuzzSplitCSV(f *testing.F) {
+	f.Add("a,b,c")
+	f.Add(`"quoted,item",plain`)
+	f.Add(`"unterminated,x`)
+	f.Add("")
+	f.Fuzz(func(t *testing.T, s string) {
+		if items := SplitCSV(s); len(items) == 0 {
+			t.Fatalf("SplitCSV(%q) returned no items", s)
+		}
+	})
+}
+
+func TestLoad_SizeLimits(t *testing.T) {
+	type Config struct {
+		Name string
+		Tags []string
+	}
+
+	os.Setenv("UNRELATED_HUGE", strings.Repeat("x", 4096))
+	defer os.Unsetenv("UNRELATED_HUGE")
+
+	cfg, err := Load[Config](WithProvider(Env()), WithProvider(Map(map[string]string{"NAME": "ok", "TAGS": "a,b"})), WithSizeLimits(16, 64))
+	if err != nil || cfg.Name != "ok" {
+		t.Fatalf("unrelated variables must not count: %v, %v", cfg, err)
+	}
+
+	_, err = Load[Config](WithProvider(Map(map[string]string{"NAME": strings.Repeat("n", 17)})), WithSizeLimits(16, 0))
+	var envErr *Error
+	if !errors.As(err, &envErr) || envErr.Field != "NAME" || !errors.Is(err, ErrLimit) {
+		t.Fatalf("expected NAME value limit error, got %v", err)
+	}
+
+	_, err = Load[Config](WithProvider(Map(map[string]string{"NAME": "0123456789", "TAGS": "0123456789"})), WithSizeLimits(0, 20))
+	if !errors.Is(err, ErrLimit) || !strings.Contains(err.Error(), "values total 28 bytes") {
+		t.Fatalf("expected total limit error, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "big.json")
+	if err := os.WriteFile(path, []byte(`{"name":"`+strings.Repeat("x", 1000)+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load[Config](WithProvider(File(path)), WithSizeLimits(0, 512))
+	if !errors.As(err, &envErr) || envErr.Field != "file" || !errors.Is(err, ErrLimit) {
+		t.Fatalf("expected file size error before reading, got %v", err)
+	}
+}
+
+func TestFileProviderEncodingDetection(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string][]byte{
+		"utf16.env":  {0xFF, 0xFE, 'P', 0, '=', 0, '1', 0},
+		"utf16.json": {0xFE, 0xFF, 0, '{', 0, '}'},
+		"binary.env": append([]byte("PORT=1\n"), 0x00, 0x01, 0x02),
+		"bad.json":   []byte("{\"name\":\"\xff\xfe\"}"),
+	}
+	for name, data := range cases {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := File(path).Values()
+		if !errors.Is(err, ErrEncoding) || !strings.Contains(err.Error(), path) {
+			t.Errorf("%s: expected ErrEncoding naming the file, got %v", name, err)
+		}
+	}
+
+	latin1 := filepath.Join(dir, "ok.properties")
+	if err := os.WriteFile(latin1, []byte("name=Jos\xe9"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := File(latin1).Values(); err != nil {
+		t.Fatalf("Latin-1 properties must still load: %v", err)
+	}
+}
+
+func TestFileProviderBOMAndCRLF(t *testing.T) {
+	type Config struct {
+		Name string
+		Port int
+	}
+	bom := "\xEF\xBB\xBF"
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":           bom + "NAME=\"multi\r\nline\"\r\nPORT=1\r\n",
+		"app.json":       bom + "{\r\n  \"name\": \"multi\\nline\",\r\n  \"port\": 1\r\n}\r\n",
+		"app.properties": bom + "name=multi\\nline\r\nport=1\r\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var logs bytes.Buffer
+		cfg, err := Load[Config](WithProvider(File(path)), WithNormalizationWarnings(), WithOutput(&logs))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if cfg.Name != "multi\nline" || cfg.Port != 1 {
+			t.Errorf("%s: unexpected config %#v", name, cfg)
+		}
+		for _, want := range []string{path + ": stripped UTF-8 byte order mark", path + ": converted CRLF line endings"} {
+			if !strings.Contains(logs.String(), want) {
+				t.Errorf("%s: expected warning %q, got %q", name, want, logs.String())
+			}
+		}
+
+		logs.Reset()
+		if _, err := Load[Config](WithProvider(File(path)), WithOutput(&logs)); err != nil || logs.Len() != 0 {
+			t.Errorf("%s: expected silent normalization without the option, got %q, %v", name, logs.String(), err)
+		}
+	}
+}
+
+func TestEnvTagOverridesName(t *testing.T) {
+	type Database struct {
+		DSN  string `env:"DB_DSN" required:"true"`
+		Pool int    `env:"POOLSIZE" default:"5"`
+	}
+	type Config struct {
+		Namespace string   `env:"K8S_NAMESPACE"`
+		Database  Database `env:"PG"`
+	}
+
+	cfg, err := Load[Config](
+		WithPrefix("APP"),
+		WithProvider(DefaultsWithPrefix[Config]("APP")),
+		WithProvider(MapPrefixed(map[string]string{
+			"APP_K8S_NAMESPACE": "payments",
+			"APP_PG_DB_DSN":     "postgres://db",
+			"APP_NAMESPACE":     "ignored",
+		})),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Namespace != "payments" || cfg.Database.DSN != "postgres://db" || cfg.Database.Pool != 5 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	_, err = Load[Config](WithProvider(Map(map[string]string{"DATABASE_DSN": "x"})))
+	var e *Error
+	if !errors.As(err, &e) || e.Field != "PG_DB_DSN" {
+		t.Fatalf("expected required error for PG_DB_DSN, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	PrintTo(&buf, cfg)
+	if !strings.Contains(buf.String(), "K8S_NAMESPACE") || !strings.Contains(buf.String(), "POOLSIZE") {
+		t.Fatalf("expected env tag names in output, got:\n%s", buf.String())
+	}
+}
+
+func TestBuildInfoProvider(t *testing.T) {
+	orig := readBuildInfo
+	defer func() { readBuildInfo = orig }()
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			GoVersion: "go1.24.1",
+			Main:      debug.Module{Path: "example.com/app", Version: "v1.4.2"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs", Value: "git"},
+				{Key: "vcs.revision", Value: "0123abcd"},
+				{Key: "vcs.time", Value: "2025-01-02T03:04:05Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}, true
+	}
+
+	type Config struct {
+		AppVersion string `from:"buildinfo"`
+		GitSHA     string `from:"buildinfo"`
+		GitTime    string
+		GitDirty   bool
+		GoVersion  string
+	}
+	cfg, err := Load[Config](WithProvider(BuildInfo()))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AppVersion != "v1.4.2" || cfg.GitSHA != "0123abcd" || !cfg.GitDirty || cfg.GoVersion != "go1.24.1" || cfg.GitTime != "2025-01-02T03:04:05Z" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	readBuildInfo = func() (*debug.BuildInfo, bool) { return nil, false }
+	cfg, err = Load[Config](WithProvider(BuildInfo()))
+	if err != nil || cfg.AppVersion != "" {
+		t.Fatalf("expected empty config without build info, got %+v, %v", cfg, err)
+	}
+}
+
+type logLevel int
+
+func (l *logLevel) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	case "warn":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", b)
+	}
+	return nil
+}
+
+func TestTextUnmarshalerFields(t *testing.T) {
+	type Config struct {
+		Level   logLevel
+		Bind    netip.Addr
+		Allowed []netip.Prefix
+		Since   time.Time
+		Peer    *netip.AddrPort
+		Unset   *netip.AddrPort
+	}
+
+	cfg, err := Load[Config](WithProvider(Map(map[string]string{
+		"LEVEL":   "warn",
+		"BIND":    "10.0.0.1",
+		"ALLOWED": "10.0.0.0/8,192.168.0.0/16",
+		"SINCE":   "2025-03-01T10:00:00Z",
+		"PEER":    "[::1]:9000",
+	})))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Level != 2 || cfg.Bind != netip.MustParseAddr("10.0.0.1") || len(cfg.Allowed) != 2 || cfg.Since.Year() != 2025 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.Peer == nil || cfg.Peer.Port() != 9000 || cfg.Unset != nil {
+		t.Fatalf("unexpected pointer fields: %v, %v", cfg.Peer, cfg.Unset)
+	}
+
+	_, err = Load[Config](WithProvider(Map(map[string]string{"LEVEL": "loud"})))
+	var e *Error
+	if !errors.As(err, &e) || e.Field != "LEVEL" || !strings.Contains(err.Error(), "unknown level") {
+		t.Fatalf("expected LEVEL parse error, got %v", err)
+	}
+}
+
+func TestRuntimeProvider(t *testing.T) {
+	type Config struct {
+		Hostname    string
+		WorkerCount int `env:"NUM_CPU"`
+		GOMAXPROCS  int
+		PID         int
+	}
+
+	cfg, err := Load[Config](WithLayer(Runtime(), LayerDefaults))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	host, _ := os.Hostname()
+	if cfg.Hostname != host || cfg.WorkerCount != runtime.NumCPU() || cfg.GOMAXPROCS != runtime.GOMAXPROCS(0) || cfg.PID != os.Getpid() {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	cfg, err = Load[Config](
+		WithLayer(Runtime(), LayerDefaults),
+		WithProvider(Map(map[string]string{"NUM_CPU": "3"})),
+	)
+	if err != nil || cfg.WorkerCount != 3 {
+		t.Fatalf("expected explicit value to win, got %+v, %v", cfg, err)
+	}
+}
+
+func TestReloadWindow(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, ny)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	night, _ := parseReloadWindow("02:00-04:00", ny)
+	span, _ := parseReloadWindow("22:00 - 02:00", ny)
+	for _, tc := range []struct {
+		w    *reloadWindow
+		now  string
+		want time.Duration
+	}{
+		{night, "2025-06-10 03:00", 0},
+		{night, "2025-06-10 01:30", 30 * time.Minute},
+		{night, "2025-06-10 04:00", 22 * time.Hour},
+		{span, "2025-06-10 23:00", 0},
+		{span, "2025-06-10 01:00", 0},
+		{span, "2025-06-10 12:00", 10 * time.Hour},
+		// Clocks spring forward from 02:00 to 03:00 on 2025-03-09, so the
+		// window opens at 03:00 EDT.
+		{night, "2025-03-08 04:00", 22 * time.Hour},
+		{night, "2025-03-09 01:30", 30 * time.Minute},
+		{night, "2025-03-09 03:30", 0},
+		// Clocks fall back on 2025-11-02, making that day an hour longer.
+		{night, "2025-11-01 04:00", 23 * time.Hour},
+	} {
+		if got := tc.w.delay(at(tc.now)); got != tc.want {
+			t.Errorf("%v-%v at %s: delay = %v, want %v", tc.w.start, tc.w.end, tc.now, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"02:00", "02:00-02:00", "25:00-04:00"} {
+		if _, err := Load[struct{}](WithReloadWindow(bad, ny)); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("%q: expected ErrInvalidOptions, got %v", bad, err)
+		}
+	}
+
+	type Config struct{ Port int }
+	provider := &mutableProvider{}
+	provider.Set("PORT", "8080")
+	now := time.Now().In(ny)
+	closed := fmt.Sprintf("%s-%s", now.Add(2*time.Hour).Format("15:04"), now.Add(3*time.Hour).Format("15:04"))
+	loader := NewLoader[Config](WithProvider(provider), WithReloadWindow(closed, ny))
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	provider.Set("PORT", "9090")
+	o := prepareOptions[Config](loader.opts)
+	loader.reloadConfig(o)
+	loader.mu.Lock()
+	queued := loader.reloadTimer != nil
+	if queued {
+		loader.reloadTimer.Stop()
+	}
+	loader.mu.Unlock()
+	if loader.Get().Port != 8080 || !queued {
+		t.Fatalf("expected reload to be queued outside the window, got port %d, queued %v", loader.Get().Port, queued)
+	}
+}
+
+func TestApprovalGating(t *testing.T) {
+	type Config struct {
+		Port     int
+		APIToken string
+	}
+	provider := &mutableProvider{}
+	provider.Set("PORT", "8080")
+	provider.Set("API_TOKEN", "old-token-123")
+
+	reloaded := make(chan *Config, 1)
+	loader := NewLoader[Config](
+		WithProvider(provider),
+		WithApproval(),
+		WithOnReload(func(old, new *Config) { reloaded <- new }),
+		WithOutput(io.Discard),
+	)
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	o := prepareOptions[Config](loader.opts)
+
+	provider.Set("PORT", "9090")
+	provider.Set("API_TOKEN", "new-token-456")
+	loader.reloadConfig(o)
+	if loader.Get().Port != 8080 {
+		t.Fatalf("change applied without approval: %+v", loader.Get())
+	}
+	want := []Change{
+		{Key: "API_TOKEN", Old: "old***123", New: "new***456"},
+		{Key: "PORT", Old: "8080", New: "9090"},
+	}
+	if got := loader.Pending(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Pending() = %+v, want %+v", got, want)
+	}
+
+	if !loader.Approve() || loader.Get().Port != 9090 || loader.Pending() != nil {
+		t.Fatalf("expected approved config, got %+v", loader.Get())
+	}
+	select {
+	case cfg := <-reloaded:
+		if cfg.Port != 9090 {
+			t.Fatalf("OnReload got port %d", cfg.Port)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnReload not called on approval")
+	}
+	if loader.Approve() {
+		t.Fatal("Approve with nothing pending should report false")
+	}
+
+	provider.Set("PORT", "7070")
+	loader.reloadConfig(o)
+	if !loader.Reject() || loader.Pending() != nil || loader.Get().Port != 9090 {
+		t.Fatalf("expected rejected change, got %+v", loader.Get())
+	}
+}
+
+func TestURLAndIPFields(t *testing.T) {
+	type Config struct {
+		Endpoint url.URL  `schemes:"https"`
+		Callback *url.URL `schemes:"http,https"`
+		Proxy    *url.URL
+		BindIP   net.IP
+		Listen   netip.AddrPort
+	}
+
+	cfg, err := Load[Config](WithProvider(Map(map[string]string{
+		"ENDPOINT": "https://api.example.com/v1",
+		"CALLBACK": "http://localhost:8080/cb",
+		"BIND_IP":  "10.1.2.3",
+		"LISTEN":   "0.0.0.0:8443",
+	})))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Endpoint.Host != "api.example.com" || cfg.Callback == nil || cfg.Callback.Port() != "8080" || cfg.Proxy != nil {
+		t.Fatalf("unexpected URLs: %+v", cfg)
+	}
+	if !cfg.BindIP.Equal(net.ParseIP("10.1.2.3")) || cfg.Listen.Port() != 8443 {
+		t.Fatalf("unexpected addresses: %v, %v", cfg.BindIP, cfg.Listen)
+	}
+
+	var buf bytes.Buffer
+	PrintTo(&buf, cfg)
+	if !strings.Contains(buf.String(), "https://api.example.com/v1") || !strings.Contains(buf.String(), "http://localhost:8080/cb") {
+		t.Fatalf("expected URLs printed as strings, got:\n%s", buf.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := WriteFileAtomic(path, cfg, FormatJSON); err != nil {
+		t.Fatalf("WriteFileAtomic: %v", err)
+	}
+	again, err := Load[Config](WithProvider(File(path)))
+	if err != nil || again.Endpoint.String() != cfg.Endpoint.String() || again.Callback.String() != cfg.Callback.String() || again.Listen != cfg.Listen {
+		t.Fatalf("round trip: %+v, %v", again, err)
+	}
+
+	type Addrs struct {
+		BindIP  net.IP
+		Allowed []net.IP
+		Listen  netip.AddrPort
+	}
+	addrs := Addrs{BindIP: cfg.BindIP, Allowed: []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("::1")}, Listen: cfg.Listen}
+	buf.Reset()
+	if err := WriteDotEnv(&buf, &addrs); err != nil {
+		t.Fatalf("WriteDotEnv: %v", err)
+	}
+	if !strings.Contains(buf.String(), "BIND_IP=10.1.2.3\n") || !strings.Contains(buf.String(), "ALLOWED=10.0.0.1,::1\n") {
+		t.Fatalf("expected addresses written as text, got:\n%s", buf.String())
+	}
+	dotenv := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(dotenv, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	back, err := Load[Addrs](WithProvider(File(dotenv)))
+	if err != nil || !back.BindIP.Equal(addrs.BindIP) || len(back.Allowed) != 2 || !back.Allowed[1].Equal(net.IPv6loopback) || back.Listen != addrs.Listen {
+		t.Fatalf("dotenv round trip: %+v, %v", back, err)
+	}
+
+	for key, val := range map[string]string{
+		"ENDPOINT": "/relative/path",
+		"CALLBACK": "ftp://files.example.com",
+		"BIND_IP":  "10.1.2",
+		"LISTEN":   "localhost",
+	} {
+		_, err := Load[Config](WithProvider(Map(map[string]string{"ENDPOINT": "https://ok.example.com", key: val})))
+		var e *Error
+		if !errors.As(err, &e) || e.Field != key {
+			t.Errorf("%s=%q: expected field error, got %v", key, val, err)
+		}
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	type TLS struct {
+		MinVersion string `default:"1.2"`
+	}
+	type Config struct {
+		Debug    bool
+		Password string `secret:"true"`
+		TLS      TLS
+	}
+	noDebugInProd := PolicyFunc(func(input map[string]any) ([]string, error) {
+		cfg := input["config"].(map[string]any)
+		if cfg["Password"] != "sup***123" {
+			return nil, fmt.Errorf("secret not masked: %v", cfg["Password"])
+		}
+		if input["profile"] == "prod" && cfg["Debug"] == true {
+			return []string{"debug must be off in prod"}, nil
+		}
+		return nil, nil
+	})
+	vals := map[string]string{"DEBUG": "true", "PASSWORD": "supersecret123"}
+
+	if _, err := Load[Config](WithProvider(Map(vals)), WithProfile("dev"), WithPolicy(noDebugInProd)); err != nil {
+		t.Fatalf("dev load: %v", err)
+	}
+	_, err := Load[Config](WithProvider(Map(vals)), WithProfile("prod"), WithPolicy(noDebugInProd))
+	if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), "debug must be off in prod") {
+		t.Fatalf("expected policy denial, got %v", err)
+	}
+}
+
+func TestSchemaValidation(t *testing.T) {
+	type Config struct {
+		Port     string
+		LogLevel string
+		Hosts    string
+		Replicas string
+	}
+	schema := []byte(`{
+		"type": "object",
+		"required": ["PORT", "REGION"],
+		"properties": {
+			"PORT": {"type": "integer", "minimum": 1024, "maximum": 65535},
+			"LOG_LEVEL": {"enum": ["debug", "info", "warn"]},
+			"HOSTS": {"type": "array", "minItems": 1, "items": {"type": "string", "pattern": "^[a-z0-9.-]+$"}},
+			"REGION": {"type": "string", "minLength": 2}
+		},
+		"additionalProperties": {"type": "string", "maxLength": 3}
+	}`)
+
+	cfg, err := Load[Config](
+		WithPrefix("APP"),
+		WithProvider(Map(map[string]string{"PORT": "8080", "LOG_LEVEL": "info", "HOSTS": "a.internal,b.internal", "REGION": "eu", "REPLICAS": "3"})),
+		WithSchemaValidation(schema),
+	)
+	if err != nil || cfg.Port != "8080" {
+		t.Fatalf("valid values rejected: %v, %v", cfg, err)
+	}
+
+	_, err = Load[Config](
+		WithProvider(Map(map[string]string{"PORT": "80", "LOG_LEVEL": "trace", "HOSTS": "ok.host,Bad_Host", "REPLICAS": "1000"})),
+		WithSchemaValidation(schema),
+	)
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+	for _, want := range []string{
+		"/PORT: validation failed: 80 is less than 1024",
+		"/LOG_LEVEL: validation failed: trace is not one of debug, info, warn",
+		`/HOSTS/1: validation failed: "Bad_Host" does not match`,
+		"/REGION: validation failed: is required",
+		"/REPLICAS: validation failed: length 4 is greater than 3",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+	var e *Error
+	if !errors.As(err, &e) || !strings.HasPrefix(e.Field, "/") {
+		t.Fatalf("expected pointer-style field, got %v", e)
+	}
+
+	if _, err := Load[Config](WithSchemaValidation([]byte(`{"pattern": "("}`))); !errors.Is(err, ErrInvalidOptions) {
+		t.Fatalf("expected ErrInvalidOptions for a bad schema, got %v", err)
+	}
+}
+
+func TestSchemaGeneration(t *testing.T) {
+	type Config struct {
+		Port     int           `default:"8080" min:"1024" max:"65535" desc:"HTTP listen port."`
+		LogLevel string        `default:"info" oneof:"debug info warn"`
+		Hosts    []string      `default:"a,b" min:"1"`
+		Timeout  time.Duration `default:"5s" max:"1m"`
+		Database struct {
+			URL string `required:"true" pattern:"^postgres://"`
+		}
+		Cache struct {
+			Enabled bool
+			Addr    string `required:"true"`
+		}
+		Redis *struct {
+			Addr string `requiredAny:"redis"`
+		}
+		Token  string `requiredAny:"auth"`
+		APIKey string `requiredAny:"auth"`
+	}
+
+	data, err := Schema[Config]()
+	if err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+	var schema struct {
+		Required   []string                  `json:"required"`
+		Properties map[string]map[string]any `json:"properties"`
+		AllOf      []map[string][]any        `json:"allOf"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	port := schema.Properties["PORT"]
+	if port["type"] != "integer" || port["default"] != float64(8080) || port["minimum"] != float64(1024) || port["description"] != "HTTP listen port." {
+		t.Fatalf("unexpected PORT schema %v", port)
+	}
+	if hosts := schema.Properties["HOSTS"]; hosts["type"] != "array" || !reflect.DeepEqual(hosts["default"], []any{"a", "b"}) || hosts["minItems"] != float64(1) {
+		t.Fatalf("unexpected HOSTS schema %v", hosts)
+	}
+	if timeout := schema.Properties["TIMEOUT"]; timeout["type"] != "string" || timeout["maxLength"] != nil {
+		t.Fatalf("unexpected TIMEOUT schema %v", timeout)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"DATABASE_URL"}) {
+		t.Fatalf("required = %v, want only DATABASE_URL", schema.Required)
+	}
+	if len(schema.AllOf) != 1 || len(schema.AllOf[0]["anyOf"]) != 2 {
+		t.Fatalf("expected one requiredAny group of two keys, got %v", schema.AllOf)
+	}
+	if _, ok := schema.Properties["REDIS_ADDR"]; !ok {
+		t.Fatalf("missing optional section key in %s", data)
+	}
+
+	// The generated schema validates loads as well as files.
+	_, err = Load[Config](
+		WithProvider(Map(map[string]string{"DATABASE_URL": "mysql://db", "LOG_LEVEL": "trace", "TOKEN": "t"})),
+		WithSchemaValidation(data),
+	)
+	for _, want := range []string{"/DATABASE_URL:", "/LOG_LEVEL:"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected a violation at %s, got %v", want, err)
+		}
+	}
+	if _, err := Load[Config](
+		WithProvider(Map(map[string]string{"DATABASE_URL": "postgres://db", "API_KEY": "k"})),
+		WithSchemaValidation(data),
+	); err != nil {
+		t.Fatalf("valid values rejected: %v", err)
+	}
+}
+
+func TestSchemaKeywords(t *testing.T) {
+	for _, tc := range []struct {
+		schema string
+		value  any
+		valid  bool
+	}{
+		{`{"type": "boolean"}`, "true", true},
+		{`{"type": "boolean"}`, "yes", false},
+		{`{"type": "integer"}`, 2.5, false},
+		{`{"type": ["integer", "string"]}`, "abc", true},
+		{`{"const": "on"}`, "off", false},
+		{`{"exclusiveMaximum": 10}`, "10", false},
+		{`{"anyOf": [{"type": "integer"}, {"enum": ["auto"]}]}`, "auto", true},
+		{`{"oneOf": [{"type": "integer"}, {"type": "number"}]}`, "5", false},
+		{`{"not": {"enum": ["root"]}}`, "root", false},
+		{`{"type": "array", "maxItems": 2}`, "a,b,c", false},
+		{`false`, "x", false},
+	} {
+		var s jsonSchema
+		if err := json.Unmarshal([]byte(tc.schema), &s); err != nil {
+			t.Fatalf("%s: %v", tc.schema, err)
+		}
+		s.compile()
+		if got := len(s.validate(tc.value, "/V")) == 0; got != tc.valid {
+			t.Errorf("%s with %v: valid = %v, want %v", tc.schema, tc.value, got, tc.valid)
+		}
+	}
+}
+
+func TestValidationTags(t *testing.T) {
+	type Config struct {
+		Port     int           `min:"1024" max:"65535"`
+		Level    string        `oneof:"debug info warn error" default:"info"`
+		Endpoint string        `pattern:"^https://"`
+		Timeout  time.Duration `min:"1s" max:"1m"`
+		Tags     []string      `max:"3" oneof:"a b c d"`
+		Name     string        `min:"3"`
+	}
+	valid := map[string]string{"PORT": "8080", "ENDPOINT": "https://x", "TIMEOUT": "5s", "TAGS": "a,b"}
+
+	cfg, err := Load[Config](WithProvider(Defaults[Config]()), WithProvider(Map(valid)))
+	if err != nil || cfg.Level != "info" {
+		t.Fatalf("valid config rejected: %v, %v", cfg, err)
+	}
+	if _, err := Load[Config](WithProvider(Map(map[string]string{}))); err != nil {
+		t.Fatalf("unset fields should be skipped, got %v", err)
+	}
+
+	for key, tc := range map[string]struct{ val, msg string }{
+		"PORT":     {"80", "80 is less than 1024"},
+		"LEVEL":    {"trace", `"trace" is not one of debug, info, warn, error`},
+		"ENDPOINT": {"http://x", `"http://x" does not match ^https://`},
+		"TIMEOUT":  {"2m", "2m0s is greater than 1m"},
+		"TAGS":     {"a,b,e", `"e" is not one of a, b, c, d`},
+		"NAME":     {"ab", "length 2 is less than 3"},
+	} {
+		vals := map[string]string{key: tc.val}
+		_, err := Load[Config](WithProvider(Map(vals)))
+		var e *Error
+		if !errors.As(err, &e) || e.Field != key || !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), tc.msg) {
+			t.Errorf("%s=%q: expected %q, got %v", key, tc.val, tc.msg, err)
+		}
+	}
+
+	// Explicit zero values are checked like any other.
+	for key, tc := range map[string]struct{ val, msg string }{
+		"PORT":  {"0", "0 is less than 1024"},
+		"LEVEL": {"", `"" is not one of debug, info, warn, error`},
+	} {
+		_, err := Load[Config](WithProvider(Defaults[Config]()), WithProvider(Map(map[string]string{key: tc.val})))
+		if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), tc.msg) {
+			t.Errorf("%s=%q: expected %q, got %v", key, tc.val, tc.msg, err)
+		}
+	}
+
+	// Secret values stay out of the messages.
+	type Secrets struct {
+		APIKey string `pattern:"^key_"`
+		PIN    int    `secret:"true" min:"1000"`
+		Tier   string `secret:"true" oneof:"gold silver"`
+	}
+	_, err = Load[Secrets](WithProvider(Map(map[string]string{"API_KEY": "hunter2", "PIN": "42", "TIER": "s3cret"})))
+	for _, leak := range []string{"hunter2", "42", "s3cret"} {
+		if err == nil || strings.Contains(err.Error(), leak) {
+			t.Errorf("message shows the secret %q: %v", leak, err)
+		}
+	}
+	if err != nil && !strings.Contains(err.Error(), "value does not match ^key_") {
+		t.Errorf("expected redacted pattern message, got %v", err)
+	}
+
+	type Bad struct {
+		Port int `min:"low"`
+	}
+	if _, err := Load[Bad](WithProvider(Map(map[string]string{"PORT": "1"}))); err == nil || !strings.Contains(err.Error(), `invalid min tag "low"`) {
+		t.Fatalf("expected invalid tag error, got %v", err)
+	}
+}
+
+func TestLoadCollectsAllErrors(t *testing.T) {
+	type DB struct {
+		Host string `required:"true"`
+		Port int    `max:"65535"`
+	}
+	type Config struct {
+		Port    int `required:"true"`
+		Timeout time.Duration
+		Level   string `oneof:"debug info"`
+		DB      DB
+	}
+
+	_, err := Load[Config](WithPrefix("APP"), WithProvider(Map(map[string]string{
+		"PORT":    "eighty",
+		"TIMEOUT": "soon",
+		"LEVEL":   "loud",
+		"DB_PORT": "70000",
+	})))
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("expected MultiError, got %T: %v", err, err)
+	}
+	var fields []string
+	for _, e := range multi.Errors {
+		fields = append(fields, e.(*Error).Field)
+	}
+	want := []string{"APP_PORT", "APP_TIMEOUT", "DB_HOST", "LEVEL", "DB_PORT"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("fields = %v, want %v\n%v", fields, want, err)
+	}
+	if !errors.Is(err, ErrParse) || !errors.Is(err, ErrRequired) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected errors.Is to match every kind, got %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "envx: 5 errors:") {
+		t.Fatalf("unexpected message: %v", err)
+	}
+
+	// Validators run only once the fields are valid, and both are reported.
+	_, err = Load[typeValidatedConfig](
+		WithProvider(Map(map[string]string{"PORT": "1"})),
+		WithValidator(func(*typeValidatedConfig) error { return errors.New("option validator") }),
+	)
+	if !errors.As(err, &multi) || len(multi.Errors) != 2 {
+		t.Fatalf("expected both validator errors, got %v", err)
+	}
+
+	_, err = Load[Config](WithProvider(Map(map[string]string{"PORT": "x"})), WithProvider(Map(map[string]string{"DB_HOST": "db"})))
+	var e *Error
+	if !errors.As(err, &e) || e.Field != "PORT" || errors.As(err, &multi) {
+		t.Fatalf("a single problem should stay a plain *Error, got %T: %v", err, err)
+	}
+}
+
+func TestWatchDegradesWithoutFilesystem(t *testing.T) {
+	orig := watchStat
+	defer func() { watchStat = orig }()
+	watchStat = func(string) (os.FileInfo, error) { return nil, syscall.ENOSYS }
+
+	type Config struct{ Port int }
+	var buf lockedBuffer
+	loader := NewLoader[Config](
+		WithProvider(Map(map[string]string{"PORT": "1"})),
+		WithWatch("config.json", time.Millisecond),
+		WithOutput(&buf),
+	)
+	if err := loader.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+	defer loader.StopWatching()
+
+	loader.mu.RLock()
+	watching := loader.isWatching
+	loader.mu.RUnlock()
+	if watching || !strings.Contains(string(buf.Bytes()), "file watching is not supported") {
+		t.Fatalf("expected the file watch to be skipped with a warning, watching=%v, log=%q", watching, buf.Bytes())
+	}
+}
+
+func TestPrintPlanMatchesFmt(t *testing.T) {
+	type Inner struct {
+		Ratio float32
+	}
+	type Config struct {
+		Name     string
+		Port     uint16
+		Offset   int64
+		Ratio    float64
+		Big      float64
+		On       bool
+		Wait     time.Duration
+		Level    logLevel
+		Tags     []string
+		Endpoint url.URL
+		Inner    Inner
+		Opt      *Inner
+		APIKey   string
+	}
+	u, _ := url.Parse("https://example.com/x")
+	cfg := &Config{
+		Name: "svc", Port: 8080, Offset: -3, Ratio: 0.1, Big: 1e21, On: true,
+		Wait: 90 * time.Second, Level: 2, Tags: []string{"a", "b"}, Endpoint: *u,
+		Inner: Inner{Ratio: 1.5}, APIKey: "abcdefghijkl",
+	}
+
+	var buf bytes.Buffer
+	PrintTo(&buf, cfg)
+	for _, want := range []string{
+		fmt.Sprintf("%-25s = %v\n", "NAME", cfg.Name),
+		fmt.Sprintf("%-25s = %v\n", "PORT", cfg.Port),
+		fmt.Sprintf("%-25s = %v\n", "OFFSET", cfg.Offset),
+		fmt.Sprintf("%-25s = %v\n", "RATIO", cfg.Ratio),
+		fmt.Sprintf("%-25s = %v\n", "BIG", cfg.Big),
+		fmt.Sprintf("%-25s = %v\n", "ON", cfg.On),
+		fmt.Sprintf("%-25s = %v\n", "WAIT", cfg.Wait),
+		fmt.Sprintf("%-25s = %v\n", "LEVEL", cfg.Level),
+		fmt.Sprintf("%-25s = %v\n", "TAGS", cfg.Tags),
+		fmt.Sprintf("%-25s = %v\n", "ENDPOINT", u),
+		fmt.Sprintf("  %-25s = %v\n", "RATIO", cfg.Inner.Ratio),
+		"Opt: <nil>\n",
+		fmt.Sprintf("%-25s = %v\n", "API_KEY", "abc***jkl"),
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, buf.String())
+		}
+	}
+
+	type Plain struct {
+		Host    string
+		Port    int
+		Debug   bool
+		Timeout time.Duration
+	}
+	plain := &Plain{Host: "localhost", Port: 8080, Debug: true, Timeout: time.Second}
+	PrintTo(io.Discard, plain)
+	if allocs := testing.AllocsPerRun(100, func() { PrintTo(io.Discard, plain) }); allocs > 2 {
+		t.Fatalf("PrintTo allocates %v times per call, want at most 2", allocs)
+	}
+}
+
+func TestStrictRejectsUnknownKeys(t *testing.T) {
+	type DatabaseConfig struct {
+		DatabaseURL string
+		Name        string
+	}
+	type Config struct {
+		Port    int
+		Primary DatabaseConfig `inherit:"DATABASE_COMMON"`
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"port": 8080, "primary": {"databse_url": "postgres://db"}, "database_common": {"name": "app"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_UNRELATED", "x")
+
+	_, err := Load[Config](WithPrefix("APP"), WithProvider(File(path)), WithStrict())
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+	var fieldErr *Error
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "APP_PRIMARY_DATABSE_URL" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(err.Error(), "did you mean APP_PRIMARY_DATABASE_URL?") {
+		t.Fatalf("expected a suggestion, got %v", err)
+	}
+
+	var warned []string
+	cfg, err := Load[Config](
+		WithPrefix("APP"),
+		WithProvider(File(path)),
+		WithProvider(Map(map[string]string{"PORT": "9090", "EXTRA": "1"})),
+		WithOnUnknownKey(func(key, source string) { warned = append(warned, source+":"+key) }),
+	)
+	if err != nil || cfg.Port != 9090 || cfg.Primary.Name != "app" {
+		t.Fatalf("unexpected result: %+v, %v", cfg, err)
+	}
+	if want := []string{"file:APP_PRIMARY_DATABSE_URL", "map:APP_EXTRA"}; !reflect.DeepEqual(warned, want) {
+		t.Fatalf("warned = %v, want %v", warned, want)
+	}
+
+	// Strictness is declared by the provider, not derived from its name.
+	unknown := map[string]any{"APP_PORT": "1", "APP_EXTRA": "1"}
+	if _, err := Load[Config](WithPrefix("APP"), WithProvider(namedMapProvider{name: "file", values: unknown}), WithStrict()); err != nil {
+		t.Fatalf("a provider that does not declare StrictKeys was checked: %v", err)
+	}
+	strict := strictProvider{namedMapProvider{name: "consul", values: unknown}}
+	if _, err := Load[Config](WithPrefix("APP"), WithProvider(strict), WithStrict()); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey from a provider declaring StrictKeys, got %v", err)
+	}
+}
+
+type strictProvider struct{ namedMapProvider }
+
+func (strictProvider) StrictKeys() bool { return true }
+
+func TestFileStreamsLargeJSON(t *testing.T) {
+	old := streamThreshold
+	streamThreshold = 0
+	t.Cleanup(func() { streamThreshold = old })
+
+	dir := t.TempDir()
+	doc := []byte(`{"server": {"host": "example.com", "port": 8080, "tls": {"enabled": true}}, "hosts": ["a", {"b": 1}], "name": "café ` + strings.Repeat("é", 5000) + `", "empty": null}`)
+	path := filepath.Join(dir, "big.json")
+	if err := os.WriteFile(path, doc, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := File(path).Values()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := decodeFile(doc, ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("streamed values differ:\n got %v\nwant %v", got, want)
+	}
+
+	bom := filepath.Join(dir, "bom.json")
+	os.WriteFile(bom, append([]byte{0xEF, 0xBB, 0xBF}, `{"port": 1}`...), 0644)
+	if values, err := File(bom).Values(); err != nil || values["PORT"] != float64(1) {
+		t.Fatalf("unexpected result: %v, %v", values, err)
+	}
+
+	for name, data := range map[string][]byte{
+		"utf16.json":   {0xFF, 0xFE, '{', 0, '}', 0},
+		"nul.json":     []byte("{\"a\": \"x\x00\"}"),
+		"invalid.json": append([]byte(`{"a": "`+strings.Repeat("x", 5000)), 0xC3, '"', '}'),
+		"cut.json":     []byte{'{', '"', 'a', '"', ':', '"', 0xE2, 0x82},
+	} {
+		p := filepath.Join(dir, name)
+		os.WriteFile(p, data, 0644)
+		if _, err := File(p).Values(); !errors.Is(err, ErrEncoding) {
+			t.Errorf("%s: expected ErrEncoding, got %v", name, err)
+		}
+	}
+
+	trailing := filepath.Join(dir, "trailing.json")
+	os.WriteFile(trailing, []byte(`{"a": 1} {}`), 0644)
+	if _, err := File(trailing).Values(); err == nil {
+		t.Fatal("expected an error for trailing data")
+	}
+}
+
+func TestFileSecrets(t *testing.T) {
+	type Config struct {
+		Password string `required:"true"`
+		APIKey   string `fromFile:"true"`
+		Token    string
+		CertFile string
+	}
+
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "db_pass")
+	if err := os.WriteFile(secret, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_PASSWORD_FILE", secret)
+	t.Setenv("APP_API_KEY_FILE", secret)
+	t.Setenv("APP_TOKEN_FILE", secret)
+	t.Setenv("APP_CERT_FILE", "/etc/cert.pem")
+
+	cfg, err := Load[Config](WithPrefix("APP"), WithProvider(Env()), WithFileSecrets())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Config{Password: "s3cret", APIKey: "s3cret", Token: "s3cret", CertFile: "/etc/cert.pem"}
+	if *cfg != want {
+		t.Fatalf("cfg = %+v, want %+v", *cfg, want)
+	}
+
+	t.Setenv("APP_PASSWORD", "direct")
+	cfg, err = Load[Config](WithPrefix("APP"), WithProvider(Env()))
+	if err != nil || cfg.Password != "direct" || cfg.APIKey != "s3cret" || cfg.Token != "" {
+		t.Fatalf("unexpected result: %+v, %v", cfg, err)
+	}
+
+	remote := Map(map[string]string{"PASSWORD": "direct", "TOKEN_FILE": secret, "API_KEY_FILE": secret})
+	cfg, err = Load[Config](WithPrefix("APP"), WithProvider(remote), WithFileSecrets())
+	if err != nil || cfg.Token != "" || cfg.APIKey != "s3cret" {
+		t.Fatalf("only opted-in fields may be read through other providers: %+v, %v", cfg, err)
+	}
+
+	t.Setenv("APP_API_KEY_FILE", filepath.Join(dir, "missing"))
+	_, err = Load[Config](WithPrefix("APP"), WithProvider(Env()))
+	var fieldErr *Error
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "APP_API_KEY_FILE" || !errors.Is(err, ErrParse) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestReloadSkipsUnchangedValues(t *testing.T) {
+	type Config struct {
+		Port  int
+		Hosts []string
+	}
+	provider := &mutableProvider{}
+	provider.Set("PORT", "8080")
+	provider.Set("HOSTS", "a,b")
+
+	var parsed int
+	loader := NewLoader[Config](
+		WithProvider(provider),
+		WithValidator(func(*Config) error { parsed++; return nil }),
+		WithOutput(io.Discard),
+	)
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	o := prepareOptions[Config](loader.opts)
+
+	for i := 0; i < 3; i++ {
+		loader.reloadConfig(o)
+	}
+	if parsed != 1 {
+		t.Fatalf("unchanged reloads parsed %d times, want 1", parsed)
+	}
+
+	provider.Set("PORT", "9090")
+	loader.reloadConfig(o)
+	if parsed != 2 || loader.Get().Port != 9090 {
+		t.Fatalf("changed reload: parsed %d times, config %+v", parsed, loader.Get())
+	}
+
+	if _, err := loader.Load(); err != nil || parsed != 3 {
+		t.Fatalf("explicit Load must parse again: parsed %d times, %v", parsed, err)
+	}
+
+	var c valueCache
+	a := c.sum(map[string]any{"A": "1", "B": []any{"x", 1.0}})
+	if b := c.sum(map[string]any{"A": 1.0, "B": []any{"x", 1.0}}); a == b {
+		t.Fatal("string and number values hash alike")
+	}
+	if b := c.sum(map[string]any{"B": []any{"x", 1.0}, "A": "1"}); a != b {
+		t.Fatal("hash depends on map order")
+	}
+}
+
+func TestWatchPathMustMatchFileProvider(t *testing.T) {
+	type Config struct{ Port int }
+
+	var buf bytes.Buffer
+	loader := NewLoader[Config](
+		WithProvider(File("b.json")),
+		WithWatch("a.json", time.Second),
+		WithOutput(&buf),
+	)
+	if !strings.Contains(buf.String(), "a.json is watched but File providers read") {
+		t.Fatalf("expected a construction warning, got %q", buf.String())
+	}
+	err := loader.Validate()
+	if !errors.Is(err, ErrInvalidOptions) || !strings.Contains(err.Error(), "b.json") {
+		t.Fatalf("expected ErrInvalidOptions naming b.json, got %v", err)
+	}
+
+	buf.Reset()
+	dir := t.TempDir()
+	loader = NewLoader[Config](
+		WithProvider(File(filepath.Join(dir, "b.json"))),
+		WithProvider(File(filepath.Join(dir, "a.json"))),
+		WithWatch(filepath.Join(dir, "sub", "..", "a.json"), time.Second),
+		WithOutput(&buf),
+	)
+	if buf.Len() != 0 || loader.Validate() != nil {
+		t.Fatalf("expected matching paths to pass, got %q, %v", buf.String(), loader.Validate())
+	}
+}
+
+type failingDetector struct{ mutableProvider }
+
+func (p *failingDetector) Name() string { return "remote" }
+
+func (p *failingDetector) Changed() (bool, error) { return false, errors.New("unreachable") }
+
+func TestWatchStatusPerSource(t *testing.T) {
+	type Config struct {
+		Port int
+		Host string
+	}
+
+	dir := t.TempDir()
+	fast := filepath.Join(dir, "fast.json")
+	slow := filepath.Join(dir, "slow.json")
+	os.WriteFile(fast, []byte(`{"port": 8080}`), 0644)
+	os.WriteFile(slow, []byte(`{"host": "a"}`), 0644)
+
+	reloaded := make(chan *Config, 1)
+	loader := NewLoader[Config](
+		WithProvider(File(fast)),
+		WithProvider(File(slow)),
+		WithWatch(fast, 5*time.Millisecond),
+		WithWatch(slow, time.Hour),
+		WithWatchProvider(&failingDetector{}, 5*time.Millisecond),
+		WithOnReload(func(old, new *Config) { reloaded <- new }),
+		WithOutput(io.Discard),
+	)
+	if err := loader.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if err := loader.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+	defer loader.StopWatching()
+
+	time.Sleep(20 * time.Millisecond)
+	os.WriteFile(fast, []byte(`{"port": 9090}`), 0644)
+	os.Chtimes(fast, time.Now().Add(time.Second), time.Now().Add(time.Second))
+	select {
+	case cfg := <-reloaded:
+		if cfg.Port != 9090 {
+			t.Fatalf("unexpected reload: %+v", cfg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+
+	status := loader.Status()
+	if len(status) != 3 {
+		t.Fatalf("Status() = %+v, want 3 sources", status)
+	}
+	if status[0].Source != fast || status[0].Interval != 5*time.Millisecond || status[0].LastChecked.IsZero() || status[0].LastChanged.IsZero() {
+		t.Errorf("fast file status: %+v", status[0])
+	}
+	if status[1].Source != slow || status[1].Interval != time.Hour || !status[1].LastChecked.IsZero() {
+		t.Errorf("slow file status: %+v", status[1])
+	}
+	if status[2].Source != "remote" || status[2].LastChecked.IsZero() || status[2].Err == nil || !status[2].LastChanged.IsZero() {
+		t.Errorf("provider status: %+v", status[2])
+	}
+}
+
+func TestEnvFileReload(t *testing.T) {
+	type Config struct {
+		Listen   string `restart:"true"`
+		LogLevel string
+	}
+
+	path := filepath.Join(t.TempDir(), "env")
+	os.WriteFile(path, []byte("export APP_LISTEN=:8080\nAPP_LOG_LEVEL=info\n"), 0644)
+	t.Setenv("APP_LOG_LEVEL", "warn")
+
+	reloaded := make(chan []string, 1)
+	loader := NewLoader[Config](
+		WithPrefix("APP"),
+		WithProvider(Env()),
+		WithWatchProvider(EnvFile(path), 5*time.Millisecond),
+		WithOnReload(func(old, new *Config) { reloaded <- RestartRequired(old, new) }),
+		WithOutput(io.Discard),
+	)
+	cfg, err := loader.Load()
+	if err != nil || cfg.Listen != ":8080" || cfg.LogLevel != "info" {
+		t.Fatalf("initial load: %+v, %v", cfg, err)
+	}
+	if err := loader.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+	defer loader.StopWatching()
+
+	os.WriteFile(path, []byte("APP_LISTEN=:8080\nAPP_LOG_LEVEL=debug\n"), 0644)
+	select {
+	case keys := <-reloaded:
+		if loader.Get().LogLevel != "debug" || len(keys) != 0 {
+			t.Fatalf("unexpected reload: %+v, restart %v", loader.Get(), keys)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for env file reload")
+	}
+
+	os.WriteFile(path, []byte("APP_LISTEN=:9090\nAPP_LOG_LEVEL=debug\n"), 0644)
+	select {
+	case keys := <-reloaded:
+		if !reflect.DeepEqual(keys, []string{"LISTEN"}) {
+			t.Fatalf("RestartRequired = %v, want [LISTEN]", keys)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for env file reload")
+	}
+}
+
+func TestReexec(t *testing.T) {
+	if os.Getenv("ENVX_REEXEC_HELPER") == "1" {
+		if os.Getenv("ENVX_REEXEC_STAGE") == "2" {
+			fmt.Print("reexec ok")
+			os.Exit(0)
+		}
+		err := Reexec(os.Getenv("ENVX_REEXEC_FILE"))
+		fmt.Printf("Reexec returned %v", err)
+		os.Exit(1)
+	}
+	if runtime.GOOS == "windows" || runtime.GOOS == "js" || runtime.GOOS == "wasip1" {
+		t.Skip("exec is not available")
+	}
+
+	path := filepath.Join(t.TempDir(), "env")
+	os.WriteFile(path, []byte("ENVX_REEXEC_STAGE=2\n"), 0644)
+	cmd := exec.Command(os.Args[0], "-test.run=^TestReexec$")
+	cmd.Env = append(os.Environ(), "ENVX_REEXEC_HELPER=1", "ENVX_REEXEC_FILE="+path, "ENVX_REEXEC_STAGE=1")
+	out, err := cmd.Output()
+	if err != nil || string(out) != "reexec ok" {
+		t.Fatalf("re-executed process: %q, %v", out, err)
+	}
+}
+
+type describedProvider struct{ mutableProvider }
+
+func (p *describedProvider) Describe() ProviderInfo {
+	return ProviderInfo{Name: "consul", Source: "http://consul:8500/v1/kv/app", Secret: true}
+}
+
+func TestProviderDescribe(t *testing.T) {
+	type Config struct{ Port int }
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	described := &describedProvider{}
+	described.Set("PORT", "8500")
+	loader := NewLoader[Config](
+		WithProvider(File(path)),
+		WithProvider(described),
+		WithWatchProvider(EnvFile("/etc/app/env"), time.Minute),
+		WithPrecedence("consul"),
+		WithOutput(io.Discard),
+	)
+	if err := loader.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	want := []ProviderInfo{
+		{Name: "file", Source: path, Watchable: true},
+		{Name: "envfile", PrefixAware: true, Source: "/etc/app/env", Watchable: true},
+		{Name: "consul", Source: "http://consul:8500/v1/kv/app", Secret: true},
+	}
+	if got := loader.Providers(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Providers() = %#v, want %#v", got, want)
+	}
+
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := loader.Sources()["PORT"]; got != "consul http://consul:8500/v1/kv/app" {
+		t.Fatalf("Sources()[PORT] = %q", got)
+	}
+	if err := loader.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+	defer loader.StopWatching()
+	if status := loader.Status(); len(status) != 1 || status[0].Source != "envfile /etc/app/env" {
+		t.Fatalf("Status() = %+v", status)
+	}
+}
+
+func TestKeyFor(t *testing.T) {
+	type Database struct {
+		MaxConns int
+		Password string `env:"DB_PASS"`
+	}
+	type Config struct {
+		APIKey   string
+		Database Database
+		Cache    *Database
+	}
+
+	if got := KeyFor("Database.MaxConns"); got != "DATABASE_MAX_CONNS" {
+		t.Errorf("KeyFor = %q", got)
+	}
+	if got := KeyFor("APIKey", WithPrefix("app")); got != "APP_API_KEY" {
+		t.Errorf("KeyFor with prefix = %q", got)
+	}
+
+	for path, want := range map[string]string{
+		"APIKey":            "APP_API_KEY",
+		"Database.MaxConns": "APP_DATABASE_MAX_CONNS",
+		"Database.Password": "APP_DATABASE_DB_PASS",
+		"Cache.Password":    "APP_CACHE_DB_PASS",
+	} {
+		got, err := FieldKey[Config](path, WithPrefix("app"))
+		if err != nil || got != want {
+			t.Errorf("FieldKey(%q) = %q, %v, want %q", path, got, err, want)
+		}
+	}
+
+	keys := CompletionWords[Config](WithPrefix("app"))
+	for _, path := range []string{"APIKey", "Database.MaxConns", "Cache.Password"} {
+		key, _ := FieldKey[Config](path, WithPrefix("app"))
+		if !slices.Contains(keys, key+"=") {
+			t.Errorf("FieldKey(%q) = %q is not a key the loader reads: %v", path, key, keys)
+		}
+	}
+
+	for _, path := range []string{"Port", "Database", "APIKey.Foo", "Database.maxConns"} {
+		if _, err := FieldKey[Config](path); err == nil {
+			t.Errorf("FieldKey(%q): expected an error", path)
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	type Config struct {
+		DBPassword string
+		DBUser     string
+		APIKey     string
+		Token      string
+		Replicas   int
+	}
+
+	dir := t.TempDir()
+	db := filepath.Join(dir, "db.json")
+	os.WriteFile(db, []byte(`{"user": "app", "password": "s3cret", "pool": {"size": 4}}`), 0600)
+
+	var calls int
+	fake := WithResolver("fake", func(ref *url.URL) (string, error) {
+		calls++
+		return "k-" + ref.Host + ref.Path, nil
+	})
+	file := WithResolver("file", ResolveFileRef)
+	cfg, err := Load[Config](
+		WithProvider(Map(map[string]string{
+			"DB_PASSWORD": "ref+file://" + db + "#password",
+			"DB_USER":     "ref+file://" + db + "#user",
+			"API_KEY":     "ref+fake://team/api",
+			"TOKEN":       "ref+fake://team/api",
+			"REPLICAS":    "ref+file://" + db + "#/pool/size",
+			"UNRELATED":   "ref+missing://x",
+		})),
+		fake, file,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Config{DBPassword: "s3cret", DBUser: "app", APIKey: "k-team/api", Token: "k-team/api", Replicas: 4}
+	if *cfg != want {
+		t.Fatalf("cfg = %+v, want %+v", *cfg, want)
+	}
+	if calls != 1 {
+		t.Fatalf("resolver called %d times, want once per reference", calls)
+	}
+
+	_, err = Load[Config](
+		WithProvider(Map(map[string]string{
+			"DB_PASSWORD": "ref+file://" + db + "#missing",
+			"API_KEY":     "ref+missing://x",
+		})),
+		fake, file,
+	)
+	if !errors.Is(err, ErrReference) {
+		t.Fatalf("expected ErrReference, got %v", err)
+	}
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(multi.Errors) != 2 {
+		t.Fatalf("expected both references reported, got %v", err)
+	}
+
+	// Local files are only read once the file scheme is opted into.
+	_, err = Load[Config](WithProvider(Map(map[string]string{"DB_PASSWORD": "ref+file://" + db + "#password"})))
+	if !errors.Is(err, ErrReference) || !strings.Contains(err.Error(), `no resolver for scheme "file"`) {
+		t.Fatalf("expected the file scheme to be off by default, got %v", err)
+	}
+}
+
+type countingProvider struct {
+	mutableProvider
+	calls atomic.Int32
+}
+
+func (p *countingProvider) Values() (map[string]any, error) {
+	p.calls.Add(1)
+	return p.mutableProvider.Values()
+}
+
+func TestLoadAll(t *testing.T) {
+	type DBConfig struct {
+		URL      string `required:"true"`
+		Password string
+	}
+	type CacheConfig struct {
+		Addr     string
+		Password string
+	}
+
+	src := &countingProvider{}
+	src.Set("DB_URL", "postgres://db")
+	src.Set("DB_PASSWORD", "ref+vault://secret/app#password")
+	src.Set("CACHE_ADDR", "cache:6379")
+	src.Set("CACHE_PASSWORD", "ref+vault://secret/app#password")
+
+	// Keys carry their target's prefix, as with Env().
+	shared := WithProvider(PrefixAware(src, true))
+
+	var resolves int
+	vault := WithResolver("vault", func(ref *url.URL) (string, error) {
+		resolves++
+		return `{"password": "s3cret"}`, nil
+	})
+
+	var db DBConfig
+	var cache CacheConfig
+	err := LoadAll(context.Background(),
+		Shared(shared, vault),
+		Into(&db, WithPrefix("DB")),
+		Into(&cache, WithPrefix("CACHE")),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if db.URL != "postgres://db" || db.Password != "s3cret" || cache.Addr != "cache:6379" || cache.Password != "s3cret" {
+		t.Fatalf("unexpected configs: %+v %+v", db, cache)
+	}
+	if src.calls.Load() != 1 || resolves != 1 {
+		t.Fatalf("expected one fetch and one resolution, got %d and %d", src.calls.Load(), resolves)
+	}
+
+	var missing DBConfig
+	cache = CacheConfig{}
+	err = LoadAll(context.Background(),
+		Shared(shared, vault),
+		Into(&missing, WithPrefix("OTHER")),
+		Into(&cache, WithPrefix("CACHE")),
+	)
+	var envErr *Error
+	if !errors.As(err, &envErr) || envErr.Field != "URL" || !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected URL to be required, got %v", err)
+	}
+	if cache.Addr != "cache:6379" {
+		t.Fatalf("expected the other target to load, got %+v", cache)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	src.calls.Store(0)
+	if err := LoadAll(ctx, Shared(shared), Into(&db, WithPrefix("DB"))); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if src.calls.Load() != 0 {
+		t.Fatal("provider fetched after the context was done")
+	}
+
+	hung := &hangingProvider{cancelled: make(chan struct{})}
+	hung.hang.Store(true)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := LoadAll(ctx, Shared(WithProvider(hung)), Into(&db, WithPrefix("DB"))); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the fetch to be cancelled with ctx, got %v", err)
+	}
+}
+
+func TestLoaderShadow(t *testing.T) {
+	type Config struct {
+		Host     string
+		Port     int    `min:"1024"`
+		Password string `secret:"true"`
+	}
+
+	loader := NewLoader[Config](
+		WithPrefix("APP"),
+		WithProvider(Map(map[string]string{"HOST": "db", "PORT": "5432", "PASSWORD": "old"})),
+	)
+	if _, err := loader.Shadow(WithProvider(Map(nil))); !errors.Is(err, ErrNotLoaded) {
+		t.Fatalf("expected ErrNotLoaded before Load, got %v", err)
+	}
+	live := loader.MustLoad()
+
+	same, err := loader.Shadow(WithProvider(Map(map[string]string{"HOST": "db", "PORT": "5432", "PASSWORD": "old"})))
+	if err != nil || len(same) != 0 {
+		t.Fatalf("expected no changes, got %v, %v", same, err)
+	}
+
+	changes, err := loader.Shadow(WithProvider(Map(map[string]string{"HOST": "db2", "PORT": "5432", "PASSWORD": "newpass"})))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Key != "HOST" || changes[0].New != "db2" || changes[1].Key != "PASSWORD" || strings.Contains(changes[1].New, "newpass") {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+	if loader.Get() != live || live.Host != "db" {
+		t.Fatal("Shadow must not apply the shadow configuration")
+	}
+
+	// The Loader's validators still apply to the shadow chain.
+	if _, err := loader.Shadow(WithProvider(Map(map[string]string{"HOST": "db", "PORT": "80"}))); !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	type Config struct {
+		URL string `required:"true"`
+	}
+
+	src := &mutableProvider{}
+	loader := NewLoader[Config](WithProvider(src))
+	probe := httptest.NewServer(ReadyHandler(loader))
+	defer probe.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := loader.WaitReady(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if resp, err := http.Get(probe.URL); err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 before load, got %v, %v", resp, err)
+	}
+
+	// A goroutine retries until the source becomes available.
+	go func() {
+		for {
+			if _, err := loader.Load(); err == nil {
+				return
+			}
+			src.Set("URL", "http://config")
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := loader.WaitReady(ctx); err != nil || loader.Get() == nil {
+		t.Fatalf("expected ready, got %v", err)
+	}
+	if resp, err := http.Get(probe.URL); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 after load, got %v, %v", resp, err)
+	}
+
+	reg := NewRegistry(WithProvider(src))
+	Register[Config](reg, "a")
+	b, _ := Register[Config](reg, "b")
+	regProbe := httptest.NewServer(reg.ReadyHandler())
+	defer regProbe.Close()
+	b.MustLoad()
+	if resp, err := http.Get(regProbe.URL); err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while a loader is unloaded, got %v, %v", resp, err)
+	}
+	reg.Load()
+	if err := reg.WaitReady(context.Background()); err != nil {
+		t.Fatalf("registry WaitReady: %v", err)
+	}
+	if resp, err := http.Get(regProbe.URL); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 once all loaded, got %v, %v", resp, err)
+	}
+
+	// The zero Loader neither panics on load nor blocks WaitReady after it.
+	var zero Loader[struct{ Port int }]
+	if _, err := zero.Load(); err != nil {
+		t.Fatalf("zero Loader: %v", err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := zero.WaitReady(ctx); err != nil {
+		t.Fatalf("zero Loader WaitReady: %v", err)
+	}
+}
+
+func TestReloadSignal(t *testing.T) {
+	type Config struct {
+		Level string
+	}
+
+	src := &mutableProvider{}
+	src.Set("LEVEL", "info")
+	reloaded := make(chan *Config, 1)
+	loader := NewLoader[Config](
+		WithProvider(src),
+		WithReloadSignal(os.Interrupt),
+		WithOnReload(func(old, new *Config) { reloaded <- new }),
+		WithOutput(io.Discard),
+	)
+	loader.MustLoad()
+	if err := loader.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+	defer loader.StopWatching()
+
+	src.Set("LEVEL", "debug")
+	// SIGHUP is not defined everywhere; any signal behaves the same.
+	self, _ := os.FindProcess(os.Getpid())
+	if err := self.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot signal the test process: %v", err)
+	}
+	select {
+	case cfg := <-reloaded:
+		if cfg.Level != "debug" {
+			t.Fatalf("expected reloaded level, got %+v", cfg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for signal reload")
+	}
+}
+
+func TestLoaderReload(t *testing.T) {
+	type Config struct {
+		Port int `required:"true"`
+	}
+
+	src := &mutableProvider{}
+	src.Set("PORT", "8080")
+	var reloads atomic.Int32
+	loader := NewLoader[Config](
+		WithProvider(src),
+		WithMaxReloadRate(1, time.Hour),
+		WithOnReload(func(old, new *Config) { reloads.Add(1) }),
+		WithOutput(io.Discard),
+	)
+	if err := loader.Reload(); err != nil || loader.Get().Port != 8080 {
+		t.Fatalf("Reload before Load should load: %v", err)
+	}
+
+	for _, port := range []string{"9090", "9091"} {
+		src.Set("PORT", port)
+		if err := loader.Reload(); err != nil {
+			t.Fatalf("Reload: %v", err)
+		}
+	}
+	if loader.Get().Port != 9091 || loader.Version() != 3 {
+		t.Fatalf("manual reloads must bypass the rate limit, got %+v at version %d", loader.Get(), loader.Version())
+	}
+
+	src.Set("PORT", "nope")
+	err := loader.Reload()
+	if !errors.Is(err, ErrParse) {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+	if loader.Err() != err || loader.Get().Port != 9091 {
+		t.Fatal("a failed Reload must keep the last configuration and record the error")
+	}
+
+	if err := loader.Reload(); !errors.Is(err, ErrParse) {
+		t.Fatalf("expected the error again, got %v", err)
+	}
+}
+
+func TestCallbackPanicsAreRecovered(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+
+	_, err := Load[Config](
+		WithProvider(Map(map[string]string{"PORT": "1"})),
+		WithValidator(func(cfg *Config) error { panic("validator boom") }),
+	)
+	if !errors.Is(err, ErrPanic) || !strings.Contains(err.Error(), "validator boom") || !strings.Contains(err.Error(), "panics.go") {
+		t.Fatalf("expected a recovered validator panic with its stack, got %v", err)
+	}
+
+	_, err = Load[Config](
+		WithProvider(Map(map[string]string{"PORT": "1"})),
+		WithPolicy(PolicyFunc(func(map[string]any) ([]string, error) { panic("policy boom") })),
+	)
+	if !errors.Is(err, ErrPanic) {
+		t.Fatalf("expected a recovered policy panic, got %v", err)
+	}
+
+	_, err = Load[Config](
+		WithProvider(Map(map[string]string{"PORT": "ref+boom://x"})),
+		WithResolver("boom", func(*url.URL) (string, error) { panic("resolver boom") }),
+	)
+	if !errors.Is(err, ErrPanic) || !errors.Is(err, ErrReference) {
+		t.Fatalf("expected a recovered resolver panic, got %v", err)
+	}
+
+	src := &mutableProvider{}
+	src.Set("PORT", "1")
+	reloadErrs := make(chan error, 2)
+	loader := NewLoader[Config](
+		WithProvider(src),
+		WithOnReload(func(old, new *Config) { panic("reload boom") }),
+		WithOnReloadError(func(err error) {
+			reloadErrs <- err
+			panic("error handler boom")
+		}),
+		WithOutput(io.Discard),
+	)
+	loader.MustLoad()
+	src.Set("PORT", "2")
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	select {
+	case err := <-reloadErrs:
+		if !errors.Is(err, ErrPanic) || !strings.Contains(err.Error(), "reload boom") {
+			t.Fatalf("expected the OnReload panic, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the OnReload panic was not reported")
+	}
+
+	src.Set("PORT", "3")
+	if err := loader.Reload(); err != nil || loader.Get().Port != 3 {
+		t.Fatalf("the loader must keep working after a callback panic: %v", err)
+	}
+}
+
+// hangingProvider blocks in ValuesContext until its context is done, once
+// armed, like a remote source that stopped answering.
+type hangingProvider struct {
+	mutableProvider
+	hang      atomic.Bool
+	cancelled chan struct{}
+}
+
+func (p *hangingProvider) ValuesContext(ctx context.Context) (map[string]any, error) {
+	if p.hang.Load() {
+		<-ctx.Done()
+		close(p.cancelled)
+		return nil, ctx.Err()
+	}
+	return p.Values()
+}
+
+func (p *hangingProvider) Changed() (bool, error) { return p.hang.Load(), nil }
+
+func TestStopWatchingReapsHungFetches(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+
+	src := &hangingProvider{cancelled: make(chan struct{})}
+	src.Set("PORT", "1")
+	loader := NewLoader[Config](
+		WithWatchProvider(src, time.Millisecond),
+		WithReloadSignal(os.Interrupt),
+		WithOutput(io.Discard),
+	)
+	loader.MustLoad()
+	if err := loader.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+	if n := loader.WatcherCount(); n != 2 {
+		t.Fatalf("expected 2 watchers, got %d", n)
+	}
+
+	src.hang.Store(true)
+	time.Sleep(20 * time.Millisecond) // let a reload block in the fetch
+
+	done := make(chan struct{})
+	go func() {
+		loader.StopWatching()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("StopWatching blocked on a hung fetch")
+	}
+	select {
+	case <-src.cancelled:
+	default:
+		t.Fatal("the hung fetch was not cancelled")
+	}
+	if n := loader.WatcherCount(); n != 0 {
+		t.Fatalf("expected no watchers after StopWatching, got %d", n)
+	}
+
+	// Providers without ValuesContext are abandoned after the timeout.
+	block := make(chan struct{})
+	defer close(block)
+	slow := WithProvider(providerFunc(func() (map[string]any, error) {
+		<-block
+		return nil, nil
+	}))
+	_, err := Load[Config](slow, WithFetchTimeout(10*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a fetch timeout, got %v", err)
+	}
+}
+
+// hangingDetector blocks in ChangedContext until its context is done.
+type hangingDetector struct {
+	mutableProvider
+	once      sync.Once
+	checking  chan struct{}
+	cancelled chan struct{}
+}
+
+func (p *hangingDetector) Changed() (bool, error) { return false, nil }
+
+func (p *hangingDetector) ChangedContext(ctx context.Context) (bool, error) {
+	p.once.Do(func() { close(p.checking) })
+	<-ctx.Done()
+	select {
+	case <-p.cancelled:
+	default:
+		close(p.cancelled)
+	}
+	return false, ctx.Err()
+}
+
+func TestStopWatchingCancelsChangeChecks(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+
+	src := &hangingDetector{checking: make(chan struct{}), cancelled: make(chan struct{})}
+	loader := NewLoader[Config](WithWatchProvider(src, time.Millisecond), WithOutput(io.Discard))
+	loader.MustLoad()
+	if err := loader.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+	select {
+	case <-src.checking:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ChangedContext was not called")
+	}
+	loader.StopWatching()
+	select {
+	case <-src.cancelled:
+	default:
+		t.Fatal("the hung change check was not cancelled")
+	}
+}
+
+type providerFunc func() (map[string]any, error)
+
+func (f providerFunc) Values() (map[string]any, error) { return f() }
+
+func TestSubscribe(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+
+	src := &mutableProvider{}
+	src.Set("PORT", "1")
+	loader := NewLoader[Config](WithProvider(src))
+	loader.MustLoad()
+
+	a, cancelA := loader.Subscribe()
+	b, cancelB := loader.Subscribe()
+	defer cancelB()
+
+	src.Set("PORT", "2")
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	for _, ch := range []<-chan ChangeEvent[Config]{a, b} {
+		select {
+		case ev := <-ch:
+			if ev.Old.Port != 1 || ev.New.Port != 2 || ev.Version != 2 {
+				t.Fatalf("unexpected event: old %+v new %+v version %d", ev.Old, ev.New, ev.Version)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+
+	cancelA()
+	cancelA()
+	if _, ok := <-a; ok {
+		t.Fatal("expected the cancelled subscription to be closed")
+	}
+
+	// A subscriber that never reads does not block reloads and keeps the
+	// latest events.
+	for port := 3; port < 3+2*subscriberBuffer; port++ {
+		src.Set("PORT", fmt.Sprint(port))
+		if err := loader.Reload(); err != nil {
+			t.Fatalf("Reload: %v", err)
+		}
+	}
+	var last ChangeEvent[Config]
+	for len(b) > 0 {
+		last = <-b
+	}
+	if last.New.Port != 2+2*subscriberBuffer || last.Version != loader.Version() {
+		t.Fatalf("expected the latest event last, got %+v at version %d", last.New, last.Version)
+	}
+}
+
+func TestOnFieldChange(t *testing.T) {
+	type Config struct {
+		LogLevel string
+		Port     int
+		Database struct {
+			Host string
+		}
+	}
+
+	src := &mutableProvider{}
+	src.Set("LOG_LEVEL", "info")
+	src.Set("PORT", "80")
+	src.Set("DATABASE_HOST", "db1")
+
+	levels := make(chan [2]any, 4)
+	databases := make(chan any, 4)
+	loader := NewLoader[Config](
+		WithProvider(src),
+		WithOnFieldChange(func(c *Config) any { return c.LogLevel }, func(old, new any) {
+			levels <- [2]any{old, new}
+		}),
+		WithOnFieldChange(func(c *Config) any { return c.Database }, func(old, new any) {
+			databases <- new
+		}),
+		WithOutput(io.Discard),
+	)
+	loader.MustLoad()
+
+	src.Set("PORT", "81")
+	loader.Reload()
+	src.Set("LOG_LEVEL", "debug")
+	loader.Reload()
+
+	select {
+	case got := <-levels:
+		if got[0] != "info" || got[1] != "debug" {
+			t.Fatalf("unexpected level change: %v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the LogLevel callback")
+	}
+	src.Set("DATABASE_HOST", "db2")
+	loader.Reload()
+	select {
+	case got := <-databases:
+		if got.(struct{ Host string }).Host != "db2" {
+			t.Fatalf("unexpected database change: %v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the Database callback")
+	}
+	if len(levels) != 0 || len(databases) != 0 {
+		t.Fatal("callbacks must only fire for their own field")
+	}
+
+	type Other struct{}
+	err := NewLoader[Config](WithOnFieldChange(func(*Other) any { return nil }, func(old, new any) {})).Validate()
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Fatalf("expected a selector type mismatch, got %v", err)
+	}
+}
+
+func TestEventLog(t *testing.T) {
+	type Config struct {
+		Port     int `min:"80"`
+		APIToken string
+	}
+
+	src := &mutableProvider{}
+	src.Set("PORT", "8080")
+	src.Set("API_TOKEN", "old-token-123")
+	loader := NewLoader[Config](WithProvider(src), WithApproval(), WithEventLog(5), WithOutput(io.Discard))
+	loader.MustLoad()
+
+	src.Set("PORT", "1")
+	loader.Reload()
+	src.Set("PORT", "9090")
+	src.Set("API_TOKEN", "new-token-456")
+	loader.Reload()
+	loader.Reject()
+	if err := loader.Override("PORT", "7070"); err != nil {
+		t.Fatalf("Override: %v", err)
+	}
+
+	var kinds []EventKind
+	for _, ev := range loader.Events() {
+		kinds = append(kinds, ev.Kind)
+	}
+	want := []EventKind{EventLoad, EventReloadFailed, EventStaged, EventRejected, EventOverride}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("event kinds = %v, want %v", kinds, want)
+	}
+	events := loader.Events()
+	if !errors.Is(events[1].Err, ErrValidation) {
+		t.Fatalf("reload failure not recorded: %v", events[1].Err)
+	}
+	data, err := json.Marshal(events[1])
+	if err != nil || !strings.Contains(string(data), `"kind":"reload_failed"`) || !strings.Contains(string(data), `"error":"`) {
+		t.Fatalf("unexpected event JSON %s: %v", data, err)
+	}
+	wantStaged := []Change{
+		{Key: "API_TOKEN", Old: "old***123", New: "new***456"},
+		{Key: "PORT", Old: "8080", New: "9090"},
+	}
+	if !reflect.DeepEqual(events[2].Changes, wantStaged) {
+		t.Fatalf("staged changes = %+v, want %+v", events[2].Changes, wantStaged)
+	}
+	data, err = json.Marshal(events[2])
+	if err != nil || !strings.Contains(string(data), `{"key":"PORT","old":"8080","new":"9090"}`) {
+		t.Fatalf("unexpected staged event JSON %s: %v", data, err)
+	}
+	if ch := events[4].Changes; events[4].Version != 2 || len(ch) != 2 || ch[1] != (Change{Key: "PORT", Old: "8080", New: "7070"}) {
+		t.Fatalf("unexpected override event: %+v", events[4])
+	}
+
+	src.Set("API_TOKEN", "next-token-789")
+	loader.Reload()
+	loader.Approve()
+	if got := loader.Events(); len(got) != 5 || got[0].Kind != EventStaged || got[4].Kind != EventApproved {
+		t.Fatalf("log must drop its oldest event when full, got %d events", len(got))
+	}
+
+	quiet := NewLoader[Config](WithProvider(src), WithEventLog(0), WithOutput(io.Discard))
+	quiet.MustLoad()
+	if got := quiet.Events(); len(got) != 0 {
+		t.Fatalf("disabled log recorded %d events", len(got))
+	}
+}
+
+func TestAdminHandler(t *testing.T) {
+	type Config struct {
+		Port     int `desc:"HTTP listen port."`
+		Database struct {
+			Host     string
+			Password string
+		}
+		Debug bool `group:"Diagnostics"`
+	}
+	loader := NewLoader[Config](WithPrefix("APP"), WithProvider(PrefixAware(Map(map[string]string{
+		"APP_PORT":              "8080",
+		"APP_DATABASE_HOST":     "db.internal",
+		"APP_DATABASE_PASSWORD": "supersecret99",
+	}), true)))
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	post := func(h http.Handler, form url.Values, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	readOnly := AdminHandler(loader)
+	rec := httptest.NewRecorder()
+	readOnly.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin", nil))
+	body := rec.Body.String()
+	for _, want := range []string{"<h2>General</h2>", "<h2>Database</h2>", "<h2>Diagnostics</h2>", "APP_DATABASE_HOST", "sup***t99", "HTTP listen port."} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page lacks %q", want)
+		}
+	}
+	if strings.Contains(body, "supersecret99") || strings.Contains(body, "<form") {
+		t.Fatalf("read-only page leaks a secret or offers edits:\n%s", body)
+	}
+	if rec := post(readOnly, url.Values{"action": {"set"}, "key": {"APP_PORT"}, "value": {"9090"}}, nil); rec.Code != http.StatusForbidden {
+		t.Fatalf("read-only console accepted an edit: %d", rec.Code)
+	}
+
+	admin := AdminHandler(loader, AdminAuthorize(func(r *http.Request) (string, bool) {
+		user := r.Header.Get("X-User")
+		return user, user != ""
+	}))
+	alice := http.Header{"X-User": {"alice"}}
+	if rec := post(admin, url.Values{"action": {"set"}, "key": {"APP_PORT"}, "value": {"9090"}}, nil); rec.Code != http.StatusForbidden {
+		t.Fatalf("unauthorized edit answered %d", rec.Code)
+	}
+	if rec := post(admin, url.Values{"action": {"set"}, "key": {"APP_PORT"}, "value": {"9090"}}, http.Header{"X-User": {"alice"}, "Origin": {"https://evil.example"}}); rec.Code != http.StatusForbidden {
+		t.Fatalf("cross-origin edit answered %d", rec.Code)
+	}
+	if rec := post(admin, url.Values{"action": {"set"}, "key": {"APP_NOPE"}, "value": {"1"}}, alice); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown key answered %d", rec.Code)
+	}
+	if rec := post(admin, url.Values{"action": {"set"}, "key": {"APP_PORT"}, "value": {"9090"}}, alice); rec.Code != http.StatusSeeOther {
+		t.Fatalf("edit answered %d: %s", rec.Code, rec.Body)
+	}
+	if loader.Get().Port != 9090 {
+		t.Fatalf("override not applied: %d", loader.Get().Port)
+	}
+	if rec := post(admin, url.Values{"action": {"set"}, "key": {"APP_PORT"}, "value": {"nope"}}, alice); rec.Code != http.StatusUnprocessableEntity || loader.Get().Port != 9090 {
+		t.Fatalf("invalid edit answered %d, port %d", rec.Code, loader.Get().Port)
+	}
+
+	events := loader.Events()
+	last := events[len(events)-1]
+	prev := events[len(events)-2]
+	if last.Actor != "alice" || last.Err == nil || prev.Actor != "alice" || len(prev.Changes) != 1 || prev.Changes[0].New != "9090" {
+		t.Fatalf("unexpected audit events %+v, %+v", prev, last)
+	}
+
+	rec = httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin", nil))
+	if body := rec.Body.String(); !strings.Contains(body, `class="badge">override`) || !strings.Contains(body, "<td>alice</td>") || !strings.Contains(body, `value="clear"`) {
+		t.Fatalf("page lacks the override state:\n%s", body)
+	}
+	if rec := post(admin, url.Values{"action": {"clear"}}, alice); rec.Code != http.StatusSeeOther || loader.Get().Port != 8080 {
+		t.Fatalf("clear answered %d, port %d", rec.Code, loader.Get().Port)
+	}
+}
+
+func TestAdminHandler_RejectedSecretEditIsRedacted(t *testing.T) {
+	type Config struct {
+		Token string `secret:"true" pattern:"^tok_"`
+		PIN   int    `secret:"true"`
+	}
+	loader := NewLoader[Config](WithProvider(Map(map[string]string{"TOKEN": "tok_ok"})))
+	loader.MustLoad()
+
+	admin := AdminHandler(loader, AdminAuthorize(func(*http.Request) (string, bool) { return "alice", true }))
+	for key, value := range map[string]string{"TOKEN": "hunter2", "PIN": "s3cretpin"} {
+		form := url.Values{"action": {"set"}, "key": {key}, "value": {value}}
+		req := httptest.NewRequest(http.MethodPost, "/admin", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		admin.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("%s edit answered %d", key, rec.Code)
+		}
+		if strings.Contains(rec.Body.String(), value) {
+			t.Fatalf("error page shows the rejected %s value:\n%s", key, rec.Body)
+		}
+
+		rec = httptest.NewRecorder()
+		admin.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin", nil))
+		if strings.Contains(rec.Body.String(), value) {
+			t.Fatalf("page shows the rejected %s value:\n%s", key, rec.Body)
+		}
+		events := loader.Events()
+		last := events[len(events)-1]
+		if last.Err == nil || strings.Contains(last.Err.Error(), value) {
+			t.Fatalf("event log shows the rejected %s value: %v", key, last.Err)
+		}
+	}
+}
+
+func TestAdminHandler_EditKeepsOtherFields(t *testing.T) {
+	type Config struct {
+		Port int    `default:"8080"`
+		Host string `default:"localhost"`
+		Mode string `default:"dev"`
+	}
+	t.Setenv("APP_HOST", "fromenv")
+	loader := NewLoader[Config](WithPrefix("APP"))
+	loader.MustLoad()
+
+	admin := AdminHandler(loader, AdminAuthorize(func(*http.Request) (string, bool) { return "alice", true }))
+	form := url.Values{"action": {"set"}, "key": {"APP_PORT"}, "value": {"9090"}}
+	req := httptest.NewRequest(http.MethodPost, "/admin", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	admin.ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("edit answered %d: %s", rec.Code, rec.Body)
+	}
+
+	want := Config{Port: 9090, Host: "fromenv", Mode: "dev"}
+	if got := *loader.Get(); got != want {
+		t.Fatalf("after edit = %+v, want %+v", got, want)
+	}
+}
+
+func TestLocaleMessages(t *testing.T) {
+	type Config struct {
+		Host string `required:"true"`
+		Port int
+	}
+	src := Map(map[string]string{"PORT": "abc"})
+
+	_, err := Load[Config](WithProvider(src), WithLocale("pt-BR"))
+	if !errors.Is(err, ErrRequired) || !errors.Is(err, ErrParse) {
+		t.Fatalf("translated errors must keep their sentinels, got %v", err)
+	}
+	for _, want := range []string{"envx: HOST: campo obrigatório não preenchido", "envx: PORT: valor inválido: "} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in %v", want, err)
+		}
+	}
+
+	_, err = Load[Config](WithProvider(src), WithLocale("sv"), WithMessages(Messages{ErrRequired: "obligatoriskt fält saknas"}))
+	if msg := err.Error(); !strings.Contains(msg, "HOST: obligatoriskt fält saknas") || !strings.Contains(msg, "PORT: parse error") {
+		t.Fatalf("unexpected custom messages: %v", err)
+	}
+
+	_, err = Load[Config](WithProvider(src), WithLocale("not a tag"))
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Fatalf("expected an invalid locale to be reported, got %v", err)
+	}
+}
+
+func TestReloadDebounce(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+	src := &mutableProvider{}
+	src.Set("PORT", "8080")
+
+	reloaded := make(chan int, 8)
+	loader := NewLoader[Config](
+		WithProvider(src),
+		WithReloadDebounce(200*time.Millisecond),
+		WithOnReload(func(old, new *Config) { reloaded <- new.Port }),
+		WithOutput(io.Discard),
+	)
+	loader.MustLoad()
+	o := prepareOptions[Config](loader.opts)
+
+	for port := 8081; port <= 8085; port++ {
+		src.Set("PORT", fmt.Sprint(port))
+		loader.scheduleReload(o)
+		time.Sleep(10 * time.Millisecond)
+	}
+	if loader.Version() != 1 {
+		t.Fatalf("reload ran during the burst, version %d", loader.Version())
+	}
+
+	select {
+	case port := <-reloaded:
+		if port != 8085 {
+			t.Fatalf("debounced reload loaded port %d, want 8085", port)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the debounced reload")
+	}
+	time.Sleep(300 * time.Millisecond)
+	if n := len(reloaded); n != 0 || loader.Version() != 2 {
+		t.Fatalf("burst must coalesce into one reload, got %d more and version %d", n, loader.Version())
+	}
+
+	err := NewLoader[Config](WithReloadDebounce(-time.Second)).Validate()
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Fatalf("expected a negative debounce to be reported, got %v", err)
+	}
+}
+
+func TestStartupSummary(t *testing.T) {
+	type Config struct {
+		Name     string `default:"app"`
+		Database struct {
+			Host     string `default:"localhost"`
+			Port     int    `default:"5432"`
+			Password string `secret:"true"`
+		}
+		Cache *struct {
+			TTL time.Duration
+		}
+	}
+
+	var buf bytes.Buffer
+	loader := NewLoader[Config](
+		WithProvider(Defaults[Config]()),
+		WithProvider(Map(map[string]string{"DATABASE_HOST": "db.internal", "DATABASE_PASSWORD": "hunter2"})),
+		WithStartupSummary(newWriterLogger(&buf)),
+		WithOutput(io.Discard),
+	)
+	loader.MustLoad()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per non-empty section, got %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "envx: config section=root fields=1 non_default=0 sources=defaults fingerprint=") {
+		t.Fatalf("unexpected root line %q", lines[0])
+	}
+	want := "envx: config section=DATABASE fields=3 non_default=2 overridden=DATABASE_HOST,DATABASE_PASSWORD sources=defaults,map fingerprint="
+	if !strings.HasPrefix(lines[1], want) {
+		t.Fatalf("unexpected section line %q, want prefix %q", lines[1], want)
+	}
+	if strings.Contains(buf.String(), "hunter2") || strings.Contains(buf.String(), "db.internal") {
+		t.Fatalf("summary must not log values: %q", buf.String())
+	}
+
+	buf.Reset()
+	loader.Reload()
+	if buf.Len() != 0 {
+		t.Fatalf("reloads must not be summarized: %q", buf.String())
+	}
+}
+
+func TestContentWatch(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"port": 8080}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := make(chan int, 4)
+	loader := NewLoader[Config](
+		WithProvider(File(path)),
+		WithWatch(path, 5*time.Millisecond),
+		WithContentWatch(),
+		WithOnReload(func(old, new *Config) { reloaded <- new.Port }),
+		WithOutput(io.Discard),
+	)
+	if err := loader.StartWatching(); err != nil {
+		t.Fatalf("StartWatching: %v", err)
+	}
+	defer loader.StopWatching()
+
+	// A touch changes the modification time but not the content.
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if st := loader.Status(); len(st) != 1 || !st[0].LastChanged.IsZero() || st[0].LastChecked.IsZero() {
+		t.Fatalf("a touch must not count as a change: %+v", st)
+	}
+
+	// New content with an older modification time, as after a symlink swap.
+	if err := os.WriteFile(path, []byte(`{"port": 9090}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, past, past); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case port := <-reloaded:
+		if port != 9090 {
+			t.Fatalf("reloaded port %d, want 9090", port)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("content change was not detected")
+	}
+}
+
+func TestOnRemoveTag(t *testing.T) {
+	type Config struct {
+		Mode    string `default:"safe" onRemove:"default"`
+		Workers int    `onRemove:"keep"`
+		Region  string `onRemove:"fail"`
+		Plain   string
+	}
+
+	file := &mutableProvider{}
+	for k, v := range map[string]string{"MODE": "fast", "WORKERS": "8", "REGION": "eu", "PLAIN": "x"} {
+		file.Set(k, v)
+	}
+	loader := NewLoader[Config](WithProvider(file), WithOutput(io.Discard))
+	loader.MustLoad()
+
+	file.Delete("MODE")
+	file.Delete("WORKERS")
+	file.Delete("PLAIN")
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	want := Config{Mode: "safe", Workers: 8, Region: "eu"}
+	if got := *loader.Get(); got != want {
+		t.Fatalf("after removal got %+v, want %+v", got, want)
+	}
+
+	// A kept value survives later reloads until the key comes back.
+	file.Set("PLAIN", "y")
+	loader.Reload()
+	if got := loader.Get(); got.Workers != 8 || got.Plain != "y" {
+		t.Fatalf("kept value lost on a later reload: %+v", got)
+	}
+	file.Set("WORKERS", "2")
+	loader.Reload()
+	if got := loader.Get(); got.Workers != 2 {
+		t.Fatalf("returning key not applied: %+v", got)
+	}
+
+	file.Delete("REGION")
+	file.Set("PLAIN", "z")
+	if err := loader.Reload(); !errors.Is(err, ErrRemoved) {
+		t.Fatalf("expected ErrRemoved, got %v", err)
+	}
+	if got := loader.Get(); got.Region != "eu" || got.Plain != "y" {
+		t.Fatalf("failed reload must keep the previous config: %+v", got)
+	}
+	if err := loader.Reload(); !errors.Is(err, ErrRemoved) {
+		t.Fatalf("removal must keep failing until the key returns, got %v", err)
+	}
+	file.Set("REGION", "us")
+	if err := loader.Reload(); err != nil || loader.Get().Region != "us" || loader.Get().Plain != "z" {
+		t.Fatalf("expected recovery once the key returns, got %+v, %v", loader.Get(), err)
+	}
+
+	type Bad struct {
+		Port int `onRemove:"ignore"`
+	}
+	if err := NewLoader[Bad]().Validate(); !errors.Is(err, ErrInvalidOptions) {
+		t.Fatalf("expected an unknown onRemove mode to be reported, got %v", err)
+	}
+}
+
+func TestOnRemoveBeforeValidation(t *testing.T) {
+	type Config struct {
+		Token  string `required:"true" onRemove:"keep"`
+		Level  int    `default:"0" min:"1" onRemove:"default"`
+		Region string `onRemove:"fail"`
+	}
+
+	file := &mutableProvider{}
+	file.Set("TOKEN", "abc")
+	file.Set("LEVEL", "3")
+	var logs bytes.Buffer
+	loader := NewLoader[Config](WithProvider(file), WithApproval(), WithOutput(&logs))
+	loader.MustLoad()
+
+	file.Delete("TOKEN")
+	for i := 0; i < 2; i++ {
+		if err := loader.Reload(); err != nil || loader.Get().Token != "abc" {
+			t.Fatalf("a required key must be kept: %+v, %v", loader.Get(), err)
+		}
+	}
+	if n := strings.Count(logs.String(), "keeping its last value"); n != 1 {
+		t.Fatalf("kept warning logged %d times, want once:\n%s", n, &logs)
+	}
+
+	file.Delete("LEVEL")
+	if err := loader.Reload(); !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected the reverted default to be validated, got %v", err)
+	}
+	file.Set("LEVEL", "3")
+
+	// A staged reload is not applied, so the keys it sets do not count
+	// as removed when they go away again.
+	file.Set("REGION", "eu")
+	if err := loader.Reload(); err != nil || loader.Pending() == nil {
+		t.Fatalf("expected a staged reload, got %v", err)
+	}
+	file.Delete("REGION")
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("a key only a staged reload set must not fail removal: %v", err)
+	}
+}
+
+func TestMarshalMasked(t *testing.T) {
+	type Config struct {
+		Name     string
+		Port     int
+		Debug    bool
+		Timeout  time.Duration
+		Hosts    []string
+		Note     string
+		Database struct {
+			URL      string
+			Password string
+		}
+		APIToken string `secret:"true"`
+	}
+	cfg := &Config{Name: "api", Port: 8080, Timeout: 5 * time.Second, Hosts: []string{"a", "b: c"}, Note: "true"}
+	cfg.Database.URL = "postgres://db/app"
+	cfg.Database.Password = "supersecret99"
+	cfg.APIToken = "tok"
+
+	data, err := MarshalJSON(cfg)
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	db := decoded["Database"].(map[string]any)
+	if db["Password"] != "sup***t99" || decoded["APIToken"] != "***" || decoded["Port"] != float64(8080) || decoded["Timeout"] != "5s" {
+		t.Fatalf("unexpected JSON %s", data)
+	}
+
+	data, err = MarshalYAML(cfg)
+	if err != nil {
+		t.Fatalf("MarshalYAML: %v", err)
+	}
+	want := `APIToken: "***"
+Database:
+  Password: sup***t99
+  URL: postgres://db/app
+Debug: false
+Hosts:
+  - a
+  - "b: c"
+Name: api
+Note: "true"
+Port: 8080
+Timeout: "5s"
+`
+	if string(data) != want {
+		t.Fatalf("MarshalYAML =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestLoaderExport(t *testing.T) {
+	type Config struct {
+		Name     string
+		Timeout  time.Duration
+		Hosts    []string
+		Started  time.Time
+		Database struct {
+			Password string
+		}
+		Port int
+	}
+
+	loader := NewLoader[Config](WithProvider(Map(map[string]string{
+		"NAME":              "api",
+		"TIMEOUT":           "90s",
+		"HOSTS":             "a,b",
+		"STARTED":           "2024-01-02T03:04:05Z",
+		"DATABASE_PASSWORD": "supersecret99",
+	})))
+	var buf bytes.Buffer
+	if err := loader.Export(&buf, FormatJSON, true); !errors.Is(err, ErrNotLoaded) {
+		t.Fatalf("expected ErrNotLoaded before Load, got %v", err)
+	}
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	export := func(format Format, mask bool) string {
+		t.Helper()
+		buf.Reset()
+		if err := loader.Export(&buf, format, mask); err != nil {
+			t.Fatalf("Export(%s): %v", format, err)
+		}
+		return buf.String()
+	}
+
+	if out := export(FormatJSON, false); !strings.Contains(out, `"Password": "supersecret99"`) {
+		t.Fatalf("unmasked JSON lost the secret:\n%s", out)
+	}
+	if out := export(FormatYAML, true); !strings.Contains(out, "Password: sup***t99\n") || !strings.Contains(out, `Timeout: "1m30s"`) {
+		t.Fatalf("unexpected YAML:\n%s", out)
+	}
+	if out := export(FormatDotEnv, true); !strings.Contains(out, "DATABASE_PASSWORD=sup***t99\n") || !strings.Contains(out, "HOSTS=a,b\n") {
+		t.Fatalf("unexpected dotenv:\n%s", out)
+	}
+
+	want := `Config{
+	Name:    "api",
+	Timeout: 90 * time.Second,
+	Hosts: []string{
+		"a",
+		"b",
+	},
+	Started: func() time.Time { var v time.Time; _ = v.UnmarshalText([]byte("2024-01-02T03:04:05Z")); return v }(),
+	Database: struct{ Password string }{
+		Password: "sup***t99",
+	},
+}
+`
+	if out := export(FormatGoLiteral, true); out != want {
+		t.Fatalf("Go literal =\n%s\nwant\n%s", out, want)
+	}
+
+	if err := loader.Export(&buf, Format("toml"), false); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("expected ErrUnsupportedType, got %v", err)
+	}
+}
+
+func TestGoLiteral(t *testing.T) {
+	type Config struct {
+		Name     string
+		Retry    *int
+		Database struct {
+			Password string
+			Token    []byte `secret:"true"`
+		}
+		Labels map[string]string
+	}
+	retry := 3
+	cfg := &Config{Name: "api", Retry: &retry, Labels: map[string]string{"tier": "web", "app": "api"}}
+	cfg.Database.Password = "supersecret99"
+	cfg.Database.Token = []byte("tok")
+
+	want := `Config{
+	Name:  "api",
+	Retry: func() *int { v := int(3); return &v }(),
+	Database: struct {
+		Password string
+		Token    []uint8 "secret:\"true\""
+	}{
+		Password: "sup***t99",
+	},
+	Labels: map[string]string{
+		"app":  "api",
+		"tier": "web",
+	},
+}`
+	if got := GoLiteral(cfg); got != want {
+		t.Fatalf("GoLiteral =\n%s\nwant\n%s", got, want)
+	}
+	if got := GoLiteral(cfg, GoLiteralPlaceholders()); !strings.Contains(got, `Password: "<DATABASE_PASSWORD>",`) {
+		t.Fatalf("expected a placeholder, got\n%s", got)
+	}
+}
+
+func TestEnvSettings(t *testing.T) {
+	type Config struct {
+		Port int
+	}
+	t.Setenv("ENVX_WATCH_INTERVAL", "250ms")
+	t.Setenv("ENVX_RELOAD_DEBOUNCE", "1s")
+	t.Setenv("ENVX_FETCH_TIMEOUT", "3s")
+
+	opts := []Option{WithWatch("config.json", time.Minute), WithWatchProvider(&failingDetector{}, time.Minute), WithFetchTimeout(time.Second)}
+	o := prepareOptions[Config](opts)
+	if o.watches[0].interval != 250*time.Millisecond || o.watchProviders[0].interval != 250*time.Millisecond {
+		t.Fatalf("ENVX_WATCH_INTERVAL not applied: %+v %+v", o.watches, o.watchProviders)
+	}
+	if o.reloadDebounce != time.Second || o.fetchTimeout != 3*time.Second {
+		t.Fatalf("ENVX_RELOAD_DEBOUNCE or ENVX_FETCH_TIMEOUT not applied: %s %s", o.reloadDebounce, o.fetchTimeout)
+	}
+
+	o = prepareOptions[Config](append(opts, WithoutEnvSettings()))
+	if o.watches[0].interval != time.Minute || o.fetchTimeout != time.Second {
+		t.Fatal("WithoutEnvSettings must keep the options from code")
+	}
+
+	t.Setenv("ENVX_WATCH_INTERVAL", "0s")
+	t.Setenv("ENVX_RELOAD_DEBOUNCE", "soon")
+	_, err := Load[Config](WithProvider(Map(nil)))
+	var e *Error
+	if !errors.Is(err, ErrInvalidOptions) || !errors.As(err, &e) {
+		t.Fatalf("expected invalid settings to fail the load, got %v", err)
+	}
+	for _, name := range []string{"ENVX_WATCH_INTERVAL", "ENVX_RELOAD_DEBOUNCE"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Fatalf("expected %s in %v", name, err)
+		}
+	}
+}
+
+func TestWriteDotEnv(t *testing.T) {
+	type Config struct {
+		Greeting string
+		Hosts    []string
+		Timeout  time.Duration
+		Database struct {
+			Password string
+			Cert     string
+		}
+	}
+	cfg := &Config{Greeting: "it's \"quoted\" # not a comment", Hosts: []string{"a", "b,c"}, Timeout: 90 * time.Second}
+	cfg.Database.Password = "supersecret99"
+	cfg.Database.Cert = "line1\nline2"
+
+	var buf bytes.Buffer
+	if err := WriteDotEnv(&buf, cfg, DotEnvPrefix("app")); err != nil {
+		t.Fatalf("WriteDotEnv: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "APP_GREETING=") {
+		t.Fatalf("expected prefixed keys in field order, got %q", buf.String())
+	}
+	got, err := Load[Config](WithProvider(PrefixAware(Map(ParseDotEnv(buf.Bytes())), true)), WithPrefix("APP"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Fatalf("round trip = %+v, want %+v", got, cfg)
+	}
+
+	buf.Reset()
+	if err := WriteDotEnv(&buf, cfg, DotEnvMaskSecrets()); err != nil {
+		t.Fatalf("WriteDotEnv: %v", err)
+	}
+	if !strings.Contains(buf.String(), "DATABASE_PASSWORD=sup***t99\n") || strings.Contains(buf.String(), "supersecret99") {
+		t.Fatalf("secret not masked: %q", buf.String())
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	type Config struct {
+		Port  int    `default:"8080"`
+		Mode  string `default:"safe"`
+		Debug bool   `required:"dev"`
+	}
+	dir := t.TempDir()
+	base, local := filepath.Join(dir, "base.json"), filepath.Join(dir, "local.json")
+	os.WriteFile(base, []byte(`{"mode": "fast", "debug": true}`), 0644)
+	os.WriteFile(local, []byte(`{"mode": "turbo"}`), 0644)
+
+	t.Setenv("ENVX_PROFILE", "dev")
+	t.Setenv("ENVX_PREFIX", "app")
+	t.Setenv("ENVX_CONFIG_FILES", base+","+local)
+	t.Setenv("ENVX_WATCH_INTERVAL", "1s")
+	t.Setenv("APP_PORT", "9090")
+
+	boot, err := LoadBootstrap()
+	if err != nil {
+		t.Fatalf("LoadBootstrap: %v", err)
+	}
+	if boot.Profile != "dev" || boot.Prefix != "app" || len(boot.ConfigFiles) != 2 || boot.WatchInterval != time.Second {
+		t.Fatalf("unexpected bootstrap %+v", boot)
+	}
+
+	loader := NewLoader[Config](boot.Options()...)
+	if err := loader.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if *cfg != (Config{Port: 9090, Mode: "turbo", Debug: true}) {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if o := prepareOptions[Config](loader.opts); len(o.watches) != 2 || o.profile != "dev" {
+		t.Fatalf("expected both files watched under the dev profile, got %+v", o.watches)
+	}
+
+	// A remote source whose subsystem is compiled out is reported.
+	orig := bootstrapRemotes
+	defer func() { bootstrapRemotes = orig }()
+	bootstrapRemotes = map[string]func(*Bootstrap) Provider{}
+	boot.HTTPURL = "http://config.internal/app.json"
+	if _, err := Load[Config](boot.Options()...); !errors.Is(err, ErrInvalidOptions) {
+		t.Fatalf("expected a missing subsystem to be reported, got %v", err)
+	}
+}
+
+func TestDocs(t *testing.T) {
+	type Config struct {
+		Port     int           `default:"8080" desc:"HTTP listen port."`
+		Timeout  time.Duration `default:"5s"`
+		Database struct {
+			URL      string `required:"true" desc:"Postgres DSN.\nInclude sslmode."`
+			Password string `required:"prod,staging"`
+		}
+		Cache *struct {
+			Addr string `requiredAny:"cache" desc:"host|port"`
+		}
+	}
+
+	docs := Docs[Config](WithPrefix("app"))
+	if len(docs) != 5 {
+		t.Fatalf("expected 5 fields, got %+v", docs)
+	}
+	want := FieldDoc{Key: "APP_DATABASE_PASSWORD", Field: "Database.Password", Type: "string", RequiredIn: []string{"prod", "staging"}, Secret: true}
+	if !reflect.DeepEqual(docs[3], want) {
+		t.Fatalf("Docs[3] = %+v, want %+v", docs[3], want)
+	}
+	if !docs[2].Required || docs[1].Type != "time.Duration" || !docs[4].Optional || docs[4].RequiredAny != "cache" {
+		t.Fatalf("unexpected docs %+v", docs)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, docs); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	wantMD := "| Variable | Type | Default | Required | Secret | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| `APP_PORT` | `int` | `8080` |  |  | HTTP listen port. |\n" +
+		"| `APP_TIMEOUT` | `time.Duration` | `5s` |  |  |  |\n" +
+		"| `APP_DATABASE_URL` | `string` |  | yes |  | Postgres DSN.<br>Include sslmode. |\n" +
+		"| `APP_DATABASE_PASSWORD` | `string` |  | in prod, staging | yes |  |\n" +
+		"| `APP_CACHE_ADDR` | `string` |  | one of cache |  | host\\|port<br>Optional section. |\n"
+	if buf.String() != wantMD {
+		t.Fatalf("WriteMarkdown =\n%s\nwant\n%s", buf.String(), wantMD)
+	}
+}
+
+func TestDocsLayout(t *testing.T) {
+	type Config struct {
+		Name    string
+		Port    int    `group:"Networking" order:"20"`
+		Host    string `group:"Networking" order:"10"`
+		Verbose bool   `order:"5"`
+		TLS     struct {
+			Cert string
+			Key  string `group:"Security"`
+		} `group:"Networking"`
+		Proxy string `group:"Networking"`
+	}
+
+	var keys []string
+	for _, d := range Docs[Config]() {
+		keys = append(keys, d.Group+"/"+d.Key)
+	}
+	want := []string{"/VERBOSE", "/NAME", "Networking/HOST", "Networking/PORT", "Networking/TLS_CERT", "Networking/PROXY", "Security/TLS_KEY"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("Docs layout = %v, want %v", keys, want)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, Docs[Config]()); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if out := buf.String(); strings.Count(out, "| Variable |") != 3 || !strings.Contains(out, "\n\n### Networking\n\n| Variable |") {
+		t.Fatalf("expected one table per group:\n%s", out)
+	}
+
+	type BadOrder struct {
+		Port int `order:"first"`
+	}
+	if _, err := Load[BadOrder](WithProvider(Map(map[string]string{"PORT": "80"}))); err != nil {
+		t.Fatalf("a bad order tag must not fail Load: %v", err)
+	}
+	buf.Reset()
+	var e *Error
+	if err := WriteMarkdown(&buf, Docs[BadOrder]()); !errors.Is(err, ErrParse) || !errors.As(err, &e) || e.Field != "PORT" || buf.Len() != 0 {
+		t.Fatalf("expected an ErrParse for PORT's order tag and no output, got %v, %q", err, buf.String())
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	type Config struct {
+		Port        int    `default:"8080" desc:"HTTP listen port."`
+		DatabaseURL string `required:"true" desc:"Postgres DSN.\nInclude sslmode."`
+		Database    struct {
+			Pool int `required:"prod,staging"`
+		} `desc:"Connection pool."`
+		Cache *struct {
+			Addr string `requiredAny:"cache"`
+		}
+		Greeting string `default:"hi there #1"`
+	}
+
+	var buf bytes.Buffer
+	if err := Template[Config](&buf, WithPrefix("app")); err != nil {
+		t.Fatalf("Template: %v", err)
+	}
+	want := `# HTTP listen port.
+APP_PORT=8080
+
+# Postgres DSN.
+# Include sslmode.
+# required
+APP_DATABASE_URL=
+
+# Database
+# Connection pool.
+
+# required in prod, staging
+APP_DATABASE_POOL=
+
+# Cache (optional section)
+
+# set at least one of the cache group
+APP_CACHE_ADDR=
+
+APP_GREETING='hi there #1'
+`
+	if buf.String() != want {
+		t.Fatalf("Template =\n%s\nwant\n%s", buf.String(), want)
+	}
+	if got := ParseDotEnv(buf.Bytes()); got["APP_PORT"] != "8080" || got["APP_GREETING"] != "hi there #1" {
+		t.Fatalf("template does not parse back: %v", got)
+	}
 }
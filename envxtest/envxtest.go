@@ -0,0 +1,99 @@
+// Package envxtest provides providers for testing how applications behave
+// under configuration churn and provider outages.
+package envxtest
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/nicolasmmb/envx"
+)
+
+// ErrInjected is returned by Flaky providers for simulated failures.
+var ErrInjected = errors.New("envxtest: injected failure")
+
+type flakyProvider struct {
+	envx.Provider
+	failureRate float64
+	latency     time.Duration
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// Flaky wraps p so that every call first waits latency and then fails with
+// ErrInjected with probability failureRate (0 never, 1 always). The wrapper
+// keeps p's name and prefix handling.
+func Flaky(p envx.Provider, failureRate float64, latency time.Duration) envx.Provider {
+	seed := uint64(time.Now().UnixNano())
+	return &flakyProvider{
+		Provider:    p,
+		failureRate: failureRate,
+		latency:     latency,
+		rng:         rand.New(rand.NewPCG(seed, seed>>1)),
+	}
+}
+
+func (p *flakyProvider) Name() string { return providerName(p.Provider) }
+
+func (p *flakyProvider) PrefixAware() bool {
+	pa, ok := p.Provider.(interface{ PrefixAware() bool })
+	return ok && pa.PrefixAware()
+}
+
+func (p *flakyProvider) Values() (map[string]any, error) {
+	if p.latency > 0 {
+		time.Sleep(p.latency)
+	}
+
+	p.mu.Lock()
+	fail := p.rng.Float64() < p.failureRate
+	p.mu.Unlock()
+
+	if fail {
+		return nil, ErrInjected
+	}
+	return p.Provider.Values()
+}
+
+type sequenceProvider struct {
+	mu   sync.Mutex
+	sets []map[string]string
+	next int
+}
+
+// Sequence returns a provider that yields the given value sets one per
+// call, in order, and keeps returning the last set once exhausted.
+func Sequence(sets ...map[string]string) envx.Provider {
+	return &sequenceProvider{sets: sets}
+}
+
+func (p *sequenceProvider) Name() string { return "sequence" }
+
+func (p *sequenceProvider) Values() (map[string]any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.sets) == 0 {
+		return map[string]any{}, nil
+	}
+	set := p.sets[p.next]
+	if p.next < len(p.sets)-1 {
+		p.next++
+	}
+
+	values := make(map[string]any, len(set))
+	for k, v := range set {
+		values[k] = v
+	}
+	return values, nil
+}
+
+func providerName(p envx.Provider) string {
+	if np, ok := p.(interface{ Name() string }); ok {
+		return np.Name()
+	}
+	return "flaky"
+}
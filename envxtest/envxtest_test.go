@@ -0,0 +1,50 @@
+package envxtest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nicolasmmb/envx"
+)
+
+func TestFlaky(t *testing.T) {
+	type Config struct{ Port int }
+	base := envx.Map(map[string]string{"PORT": "8080"})
+
+	if _, err := envx.Load[Config](envx.WithProvider(Flaky(base, 1, 0))); !errors.Is(err, ErrInjected) {
+		t.Fatalf("expected injected failure, got %v", err)
+	}
+
+	start := time.Now()
+	cfg, err := envx.Load[Config](envx.WithProvider(Flaky(base, 0, 20*time.Millisecond)))
+	if err != nil || cfg.Port != 8080 {
+		t.Fatalf("unexpected result: %v, %v", cfg, err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("expected latency to be applied")
+	}
+
+	info := envx.NewLoader[Config](envx.WithProvider(Flaky(base, 0, 0))).Providers()
+	if len(info) != 1 || info[0].Name != "map" {
+		t.Fatalf("expected wrapped provider name, got %+v", info)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	type Config struct{ Port int }
+
+	loader := envx.NewLoader[Config](envx.WithProvider(Sequence(
+		map[string]string{"PORT": "1"},
+		map[string]string{"PORT": "2"},
+	)))
+	for _, want := range []int{1, 2, 2} {
+		cfg, err := loader.Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.Port != want {
+			t.Fatalf("Port = %d, want %d", cfg.Port, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package envx
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
@@ -10,6 +11,14 @@ var (
 	ErrValidation      = errors.New("validation failed")
 	ErrUnsupportedType = errors.New("unsupported type")
 	ErrParse           = errors.New("parse error")
+	ErrNotLoaded       = errors.New("configuration not loaded")
+	ErrInvalidOptions  = errors.New("invalid options")
+	ErrLimit           = errors.New("size limit exceeded")
+	ErrEncoding        = errors.New("unsupported encoding")
+	ErrUnknownKey      = errors.New("unknown key")
+	ErrReference       = errors.New("unresolved reference")
+	ErrPanic           = errors.New("callback panicked")
+	ErrRemoved         = errors.New("key removed from its source")
 )
 
 type Error struct {
@@ -22,3 +31,43 @@ func (e *Error) Error() string {
 }
 
 func (e *Error) Unwrap() error { return e.Err }
+
+// MultiError holds every problem found by one load, so a broken
+// configuration can be fixed in a single pass. errors.Is and errors.As
+// match any of its errors.
+type MultiError struct {
+	Errors []error
+}
+
+func (m *MultiError) Error() string {
+	msgs := make([]string, len(m.Errors))
+	for i, err := range m.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("envx: %d errors:\n\t%s", len(m.Errors), strings.Join(msgs, "\n\t"))
+}
+
+func (m *MultiError) Unwrap() []error { return m.Errors }
+
+// appendErrors appends err to errs, flattening a MultiError.
+func appendErrors(errs []error, err error) []error {
+	if m, ok := err.(*MultiError); ok {
+		return append(errs, m.Errors...)
+	}
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// joinErrors returns nil, the only error, or a MultiError holding all of
+// errs.
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return &MultiError{Errors: errs}
+}
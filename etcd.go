@@ -0,0 +1,295 @@
+//go:build !envx_minimal && !envx_no_etcd
+
+package envx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+func init() {
+	registerSubsystem("etcd")
+	bootstrapRemotes["etcd"] = func(b *Bootstrap) Provider { return Etcd(b.EtcdEndpoint, b.EtcdPrefix) }
+}
+
+type etcdProvider struct {
+	endpoint string
+	prefix   string
+	username string
+	password string
+	client   *http.Client
+
+	mu       sync.Mutex
+	token    string
+	revision int64
+}
+
+// EtcdOption configures an Etcd provider.
+type EtcdOption func(*etcdProvider)
+
+// EtcdAuth authenticates with etcd's user and password authentication.
+func EtcdAuth(username, password string) EtcdOption {
+	return func(p *etcdProvider) {
+		p.username = username
+		p.password = password
+	}
+}
+
+// EtcdHTTPClient sets the HTTP client used to call the gateway, e.g. one
+// configured with client certificates. Leave its Timeout unset: it would
+// also cut the long-lived watch stream. Other calls give up after 30 seconds
+// regardless.
+func EtcdHTTPClient(c *http.Client) EtcdOption {
+	return func(p *etcdProvider) {
+		p.client = c
+	}
+}
+
+// Etcd returns a provider that reads every key under prefix from an etcd v3
+// cluster through its JSON gateway at endpoint (e.g.
+// "http://127.0.0.1:2379"). The remainder of each key after prefix becomes
+// the variable name, with "/" separating sections, so with prefix "/app/"
+// the key "/app/database/url" sets DATABASE_URL. The provider implements
+// ChangeNotifier, so WithWatchProvider reloads on etcd watch events instead
+// of polling.
+func Etcd(endpoint, prefix string, opts ...EtcdOption) Provider {
+	p := &etcdProvider{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		prefix:   prefix,
+		client:   http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *etcdProvider) Name() string { return "etcd" }
+
+func (p *etcdProvider) StrictKeys() bool { return true }
+
+func (p *etcdProvider) Describe() ProviderInfo {
+	return ProviderInfo{Source: displayURL(p.endpoint) + " " + p.prefix}
+}
+
+type etcdHeader struct {
+	Revision string `json:"revision"`
+}
+
+type etcdKeyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+func (p *etcdProvider) Values() (map[string]any, error) {
+	return p.ValuesContext(context.Background())
+}
+
+func (p *etcdProvider) ValuesContext(ctx context.Context) (map[string]any, error) {
+	var resp struct {
+		Header etcdHeader     `json:"header"`
+		Kvs    []etcdKeyValue `json:"kvs"`
+	}
+	req := map[string]any{
+		"key":       []byte(p.prefix),
+		"range_end": etcdPrefixEnd(p.prefix),
+	}
+	if err := p.call(ctx, "/v3/kv/range", req, &resp); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if key := etcdVarName(strings.TrimPrefix(string(kv.Key), p.prefix)); key != "" {
+			values[key] = string(kv.Value)
+		}
+	}
+
+	rev, _ := strconv.ParseInt(resp.Header.Revision, 10, 64)
+	p.mu.Lock()
+	p.revision = rev
+	p.mu.Unlock()
+	return values, nil
+}
+
+// Watch streams watch events for the prefix, starting just after the
+// revision the last Values call read, so no change between a load and the
+// watch is missed.
+func (p *etcdProvider) Watch(ctx context.Context, notify func()) error {
+	p.mu.Lock()
+	rev := p.revision
+	p.mu.Unlock()
+
+	create := map[string]any{
+		"key":       []byte(p.prefix),
+		"range_end": etcdPrefixEnd(p.prefix),
+	}
+	if rev > 0 {
+		create["start_revision"] = strconv.FormatInt(rev+1, 10)
+	}
+	body, err := p.send(ctx, "/v3/watch", map[string]any{"create_request": create})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	for {
+		var msg struct {
+			Result *struct {
+				Canceled     bool              `json:"canceled"`
+				CancelReason string            `json:"cancel_reason"`
+				Events       []json.RawMessage `json:"events"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("envx: etcd watch %s: %w", p.prefix, err)
+		}
+		switch {
+		case msg.Error != nil:
+			return fmt.Errorf("envx: etcd watch %s: %s", p.prefix, msg.Error.Message)
+		case msg.Result == nil:
+		case msg.Result.Canceled:
+			return fmt.Errorf("envx: etcd watch %s canceled: %s", p.prefix, msg.Result.CancelReason)
+		case len(msg.Result.Events) > 0:
+			notify()
+		}
+	}
+}
+
+// call posts req to path and decodes the JSON response into out. Unlike the
+// watch stream, it is bounded by remoteTimeout.
+func (p *etcdProvider) call(ctx context.Context, path string, req, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+	defer cancel()
+	body, err := p.send(ctx, path, req)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(body).Decode(out); err != nil {
+		return fmt.Errorf("envx: etcd %s: %w", path, err)
+	}
+	return nil
+}
+
+func (p *etcdProvider) send(ctx context.Context, path string, req any) (io.ReadCloser, error) {
+	token, err := p.authToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.post(ctx, path, req, token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && token != "" {
+		// The token expired; authenticate again once.
+		resp.Body.Close()
+		p.mu.Lock()
+		p.token = ""
+		p.mu.Unlock()
+		if token, err = p.authToken(ctx); err != nil {
+			return nil, err
+		}
+		if resp, err = p.post(ctx, path, req, token); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("envx: etcd %s: %s: %s", path, resp.Status, bytes.TrimSpace(msg))
+	}
+	return resp.Body, nil
+}
+
+func (p *etcdProvider) post(ctx context.Context, path string, req any, token string) (*http.Response, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpReq.Header.Set("Authorization", token)
+	}
+	return p.client.Do(httpReq)
+}
+
+func (p *etcdProvider) authToken(ctx context.Context) (string, error) {
+	if p.username == "" {
+		return "", nil
+	}
+	p.mu.Lock()
+	token := p.token
+	p.mu.Unlock()
+	if token != "" {
+		return token, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+	defer cancel()
+	resp, err := p.post(ctx, "/v3/auth/authenticate", map[string]string{"name": p.username, "password": p.password}, "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("envx: etcd authentication failed: %s", resp.Status)
+	}
+	var out struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("envx: etcd authentication: %w", err)
+	}
+
+	p.mu.Lock()
+	p.token = out.Token
+	p.mu.Unlock()
+	return out.Token, nil
+}
+
+// etcdPrefixEnd returns the range end matching every key with prefix. The
+// gateway expects bytes fields base64-encoded, which encoding/json does
+// for []byte.
+func etcdPrefixEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// An empty or all-0xff prefix ranges over every key.
+	return []byte{0}
+}
+
+// etcdVarName maps a key relative to the prefix to a variable name.
+func etcdVarName(rel string) string {
+	var parts []string
+	for _, seg := range strings.Split(rel, "/") {
+		if seg == "" {
+			continue
+		}
+		seg = strings.NewReplacer("-", "_", ".", "_").Replace(seg)
+		parts = append(parts, toScreamingSnake(seg))
+	}
+	return strings.Join(parts, "_")
+}
@@ -0,0 +1,93 @@
+package envx
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventKind names a step in a Loader's lifecycle.
+type EventKind string
+
+const (
+	EventLoad         EventKind = "load"
+	EventLoadFailed   EventKind = "load_failed"
+	EventReload       EventKind = "reload"
+	EventReloadFailed EventKind = "reload_failed"
+	// EventStaged, EventApproved and EventRejected follow a reload held
+	// back by WithApproval; a rejection is a veto of the staged change.
+	EventStaged   EventKind = "staged"
+	EventApproved EventKind = "approved"
+	EventRejected EventKind = "rejected"
+	// EventOverride records Override and ClearOverrides, with Err set when
+	// the override was discarded.
+	EventOverride EventKind = "override"
+)
+
+// Event is one entry of a Loader's event log.
+type Event struct {
+	Time time.Time
+	Kind EventKind
+	// Version is the Loader's version after the event.
+	Version int64
+	// Changes lists what the event changed, or would change for
+	// EventStaged and EventRejected, with secrets masked. It is empty for
+	// the first load.
+	Changes []Change
+	Err     error
+	// Actor names who made an override through AdminHandler.
+	Actor string
+}
+
+// MarshalJSON encodes Err as its message, so the log can be served as is.
+func (e Event) MarshalJSON() ([]byte, error) {
+	var msg string
+	if e.Err != nil {
+		msg = e.Err.Error()
+	}
+	return json.Marshal(struct {
+		Time    time.Time `json:"time"`
+		Kind    EventKind `json:"kind"`
+		Version int64     `json:"version"`
+		Changes []Change  `json:"changes,omitempty"`
+		Err     string    `json:"error,omitempty"`
+		Actor   string    `json:"actor,omitempty"`
+	}{e.Time, e.Kind, e.Version, e.Changes, msg, e.Actor})
+}
+
+const defaultEventLogSize = 100
+
+// WithEventLog keeps the last size lifecycle events for Loader.Events
+// instead of the default 100. A size of zero or less disables the log.
+func WithEventLog(size int) Option {
+	return func(o *options) {
+		o.eventLogSize = size
+	}
+}
+
+// Events returns the logged lifecycle events, oldest first, e.g. to serve
+// from a debug endpoint for post-incident analysis.
+func (l *Loader[T]) Events() []Event {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]Event(nil), l.events...)
+}
+
+// recordEvent appends an event to the log. The caller holds l.mu.
+func (l *Loader[T]) recordEvent(kind EventKind, changes []Change, err error) {
+	l.appendEvent(Event{Kind: kind, Changes: changes, Err: err})
+}
+
+// appendEvent stamps ev with the time and version and appends it, dropping
+// the oldest entry when the log is full. The caller holds l.mu.
+func (l *Loader[T]) appendEvent(ev Event) {
+	if l.eventLogSize <= 0 {
+		return
+	}
+	ev.Time, ev.Version = time.Now(), l.version
+	if len(l.events) == l.eventLogSize {
+		copy(l.events, l.events[1:])
+		l.events[len(l.events)-1] = ev
+		return
+	}
+	l.events = append(l.events, ev)
+}
@@ -0,0 +1,415 @@
+package envx
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Format selects the encoding used when writing configuration.
+type Format string
+
+const (
+	FormatJSON      Format = "json"
+	FormatDotEnv    Format = "dotenv"
+	FormatYAML      Format = "yaml"
+	FormatGoLiteral Format = "go"
+)
+
+type keyValue struct {
+	key   string
+	value string
+	field reflect.StructField
+}
+
+// WriteFileAtomic encodes cfg and replaces path with it using a temporary
+// file and a rename, so a Loader watching path never reads a partial write.
+func WriteFileAtomic[T any](path string, cfg *T, format Format) error {
+	data, err := encodeConfig(cfg, format)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+func encodeConfig[T any](cfg *T, format Format) ([]byte, error) {
+	v := reflect.ValueOf(cfg).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: configuration type must be a struct", ErrUnsupportedType)
+	}
+
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(structToMap(v), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case FormatDotEnv:
+		var buf bytes.Buffer
+		if err := writeDotEnv(&buf, v, dotEnvOptions{}); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("%w: format %q", ErrUnsupportedType, format)
+}
+
+// Export writes the current configuration in format, e.g. to attach a
+// snapshot to a bug report or to seed another environment's test fixtures.
+// FormatGoLiteral writes a composite literal that compiles in the package
+// declaring T. With mask, secret fields are masked as Print does; a Go
+// literal leaves masked non-string secrets at their zero value.
+func (l *Loader[T]) Export(w io.Writer, format Format, mask bool) error {
+	cfg := l.Get()
+	if cfg == nil {
+		return ErrNotLoaded
+	}
+	v := reflect.ValueOf(cfg).Elem()
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: configuration type must be a struct", ErrUnsupportedType)
+	}
+
+	var data []byte
+	var err error
+	switch format {
+	case FormatJSON, FormatYAML:
+		m := structToMap(v)
+		if mask {
+			m = maskSecrets(m, v.Type())
+		}
+		if format == FormatJSON {
+			data, err = marshalJSON(m)
+		} else {
+			data, err = marshalYAML(m)
+		}
+	case FormatDotEnv:
+		return writeDotEnv(w, v, dotEnvOptions{mask: mask})
+	case FormatGoLiteral:
+		data, err = goLiteral(v, goLiteralWriter{mask: mask})
+	default:
+		return fmt.Errorf("%w: format %q", ErrUnsupportedType, format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// DotEnvOption configures WriteDotEnv.
+type DotEnvOption func(*dotEnvOptions)
+
+type dotEnvOptions struct {
+	prefix string
+	mask   bool
+}
+
+// DotEnvPrefix prepends prefix and "_" to every key, matching a loader
+// configured with WithPrefix.
+func DotEnvPrefix(prefix string) DotEnvOption {
+	return func(o *dotEnvOptions) {
+		o.prefix = strings.ToUpper(prefix)
+	}
+}
+
+// DotEnvMaskSecrets masks secret fields as Print does, for snapshots that
+// are shared rather than loaded again.
+func DotEnvMaskSecrets() DotEnvOption {
+	return func(o *dotEnvOptions) {
+		o.mask = true
+	}
+}
+
+// WriteDotEnv writes cfg as KEY=value lines in field order, under the keys
+// a loader reads them from, e.g. to generate an env_file for docker compose
+// or to snapshot the effective configuration. Values are quoted so the File
+// provider reads them back unchanged.
+func WriteDotEnv[T any](w io.Writer, cfg *T, opts ...DotEnvOption) error {
+	v := reflect.ValueOf(cfg).Elem()
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: configuration type must be a struct", ErrUnsupportedType)
+	}
+	var o dotEnvOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return writeDotEnv(w, v, o)
+}
+
+func writeDotEnv(w io.Writer, v reflect.Value, o dotEnvOptions) error {
+	var buf bytes.Buffer
+	for _, kv := range flattenConfig(v, v.Type(), "") {
+		key, val := kv.key, kv.value
+		if o.prefix != "" {
+			key = o.prefix + "_" + key
+		}
+		if o.mask && isSecret(kv.field) && val != "" {
+			val = maskSecretValue(val)
+		}
+		line, err := exportLine(key, val, DialectDotEnv)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(line + "\n")
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeFileAtomic replaces path with data through a temporary file and a
+// rename. An existing file keeps its mode; a new one is created with perm.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	mode := perm
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, mode); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
+
+// structToMap mirrors the struct as nested maps keyed by Go field names,
+// which the File provider maps back to the same keys.
+func structToMap(v reflect.Value) map[string]any {
+	t := v.Type()
+	out := make(map[string]any, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+
+		if isSection(field.Type) {
+			out[field.Name] = structToMap(fv)
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			if !fv.IsNil() {
+				out[field.Name] = structToMap(fv.Elem())
+			}
+			continue
+		}
+
+		if fv.Type() == reflect.TypeOf(time.Duration(0)) {
+			out[field.Name] = time.Duration(fv.Int()).String()
+			continue
+		}
+		if fv.Type() == urlType || fv.Type() == urlPtrType && !fv.IsNil() {
+			out[field.Name] = stringValue(fv)
+			continue
+		}
+		out[field.Name] = fv.Interface()
+	}
+	return out
+}
+
+// flattenConfig lists leaf fields in declaration order with their keys and
+// string representations.
+func flattenConfig(v reflect.Value, t reflect.Type, path string) []keyValue {
+	var out []keyValue
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+
+		if isSection(field.Type) {
+			nestedPath := path + fieldName(field) + "_"
+			out = append(out, flattenConfig(fv, field.Type, nestedPath)...)
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			if !fv.IsNil() {
+				nestedPath := path + fieldName(field) + "_"
+				out = append(out, flattenConfig(fv.Elem(), field.Type.Elem(), nestedPath)...)
+			}
+			continue
+		}
+
+		out = append(out, keyValue{
+			key:   path + fieldName(field),
+			value: formatValue(fv),
+			field: field,
+		})
+	}
+	return out
+}
+
+func formatValue(fv reflect.Value) string {
+	if format := scalarFormatter(fv.Type()); format != nil {
+		return string(format(nil, fv))
+	}
+	if fv.Kind() == reflect.Slice {
+		// Slices such as net.IP are one value, not a list.
+		if m, ok := fv.Interface().(encoding.TextMarshaler); ok {
+			if text, err := m.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+		if reflect.PointerTo(fv.Type()).Implements(stringerType) {
+			return stringValue(fv)
+		}
+		items := make([]string, fv.Len())
+		for i := range items {
+			items[i] = formatValue(fv.Index(i))
+		}
+		return joinCSV(items)
+	}
+	return stringValue(fv)
+}
+
+// stringValue formats fv, using a String method declared on its pointer
+// type, as url.URL does, when the value is addressable.
+func stringValue(fv reflect.Value) string {
+	if fv.CanAddr() && fv.Kind() != reflect.Pointer {
+		if s, ok := fv.Addr().Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+	}
+	return fmt.Sprintf("%v", fv.Interface())
+}
+
+func joinCSV(items []string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(items); err != nil {
+		return strings.Join(items, ",")
+	}
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// Dialect selects the syntax ExportEnv writes assignments in.
+type Dialect string
+
+const (
+	DialectPOSIX      Dialect = "posix"
+	DialectPowerShell Dialect = "powershell"
+	DialectDotEnv     Dialect = "dotenv"
+)
+
+// ExportEnv writes values as variable assignments in the given dialect,
+// sorted by key. Values are quoted so that sourcing the output in a POSIX
+// shell or PowerShell, or reading it with the File provider, yields them
+// unchanged.
+func ExportEnv(w io.Writer, values map[string]string, dialect Dialect) error {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		line, err := exportLine(k, values[k], dialect)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeDotEnv renders values as .env content that the File provider parses
+// back to exactly the same map, quoting and escaping as needed. Keys that
+// cannot be represented, such as ones containing '=' or line breaks, are
+// rejected.
+func EncodeDotEnv(values map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := ExportEnv(&buf, values, DialectDotEnv); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func exportLine(key, val string, dialect Dialect) (string, error) {
+	switch dialect {
+	case DialectPOSIX:
+		if !isShellName(key) {
+			return "", &Error{Field: key, Err: fmt.Errorf("%w: not a valid shell variable name", ErrUnsupportedType)}
+		}
+		return "export " + key + "=" + quotePOSIX(val), nil
+	case DialectPowerShell:
+		if isShellName(key) {
+			return "$env:" + key + " = " + quotePowerShell(val), nil
+		}
+		return "${env:" + strings.ReplaceAll(key, "}", "`}") + "} = " + quotePowerShell(val), nil
+	case DialectDotEnv:
+		if key == "" || key != strings.TrimSpace(key) || strings.ContainsAny(key, "=\n\r") || strings.HasPrefix(key, "#") {
+			return "", &Error{Field: key, Err: fmt.Errorf("%w: not a valid dotenv key", ErrUnsupportedType)}
+		}
+		return key + "=" + quoteDotEnvValue(val), nil
+	}
+	return "", fmt.Errorf("%w: dialect %q", ErrUnsupportedType, dialect)
+}
+
+func isShellName(key string) bool {
+	if key == "" || key[0] >= '0' && key[0] <= '9' {
+		return false
+	}
+	for _, r := range key {
+		if r != '_' && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// quotePOSIX single-quotes val, closing and reopening the quotes around an
+// escaped embedded quote.
+func quotePOSIX(val string) string {
+	return "'" + strings.ReplaceAll(val, "'", `'\''`) + "'"
+}
+
+// quotePowerShell single-quotes val; an embedded quote is doubled.
+func quotePowerShell(val string) string {
+	return "'" + strings.ReplaceAll(val, "'", "''") + "'"
+}
+
+// quoteDotEnvValue leaves simple values bare and single-quotes values the
+// parser would otherwise trim. Line breaks, which single quotes cannot
+// carry, use the escaped double-quoted form.
+func quoteDotEnvValue(val string) string {
+	if strings.ContainsAny(val, "\n\r") {
+		r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+		return `"` + r.Replace(val) + `"` + dotEnvEscapedMark
+	}
+	if val == strings.TrimSpace(val) && !strings.ContainsAny(val, "#\"'") {
+		return val
+	}
+	return "'" + val + "'"
+}
@@ -0,0 +1,392 @@
+package envx
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// validateExpressions evaluates `expr` tags. Expressions use Go syntax and
+// are evaluated against the struct that declares the field, so sibling
+// fields are referenced by their Go names (e.g. `expr:"Port > 1024"`).
+// Integers are compared exactly, so int64 and uint64 values beyond 2^53
+// keep their meaning.
+func validateExpressions(cfg any) error {
+	v := reflect.ValueOf(cfg).Elem()
+	return checkExpressions(v, v.Type(), "")
+}
+
+func checkExpressions(v reflect.Value, t reflect.Type, path string) error {
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+
+		if isSection(field.Type) {
+			nestedPath := path + fieldName(field) + "_"
+			errs = appendErrors(errs, checkExpressions(fv, field.Type, nestedPath))
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			if fv.IsNil() {
+				continue
+			}
+			nestedPath := path + fieldName(field) + "_"
+			errs = appendErrors(errs, checkExpressions(fv.Elem(), field.Type.Elem(), nestedPath))
+			continue
+		}
+
+		src := field.Tag.Get("expr")
+		if src == "" {
+			continue
+		}
+
+		key := path + fieldName(field)
+		ok, err := evalBoolExpr(src, v)
+		if err != nil {
+			errs = append(errs, &Error{Field: key, Err: fmt.Errorf("%w: invalid expression %q: %v", ErrValidation, src, err)})
+		} else if !ok {
+			errs = append(errs, &Error{Field: key, Err: fmt.Errorf("%w: expression %q is false", ErrValidation, src)})
+		}
+	}
+	return joinErrors(errs)
+}
+
+func evalBoolExpr(src string, scope reflect.Value) (bool, error) {
+	node, err := parser.ParseExpr(src)
+	if err != nil {
+		return false, err
+	}
+	res, err := evalExpr(node, scope)
+	if err != nil {
+		return false, err
+	}
+	b, ok := res.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression yields %T, want bool", res)
+	}
+	return b, nil
+}
+
+func evalExpr(node ast.Expr, scope reflect.Value) (any, error) {
+	switch n := node.(type) {
+	case *ast.ParenExpr:
+		return evalExpr(n.X, scope)
+
+	case *ast.BasicLit:
+		return evalLiteral(n)
+
+	case *ast.Ident:
+		switch n.Name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return lookupField(scope, n.Name)
+
+	case *ast.SelectorExpr:
+		x, err := evalSection(n.X, scope)
+		if err != nil {
+			return nil, err
+		}
+		return lookupField(x, n.Sel.Name)
+
+	case *ast.CallExpr:
+		return evalCall(n, scope)
+
+	case *ast.UnaryExpr:
+		x, err := evalExpr(n.X, scope)
+		if err != nil {
+			return nil, err
+		}
+		return evalUnary(n.Op, x)
+
+	case *ast.BinaryExpr:
+		return evalBinary(n, scope)
+	}
+	return nil, fmt.Errorf("unsupported expression %T", node)
+}
+
+func evalSection(node ast.Expr, scope reflect.Value) (reflect.Value, error) {
+	switch n := node.(type) {
+	case *ast.Ident:
+		fv := scope.FieldByName(n.Name)
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() && isOptionalSection(fv.Type()) {
+				return reflect.Value{}, fmt.Errorf("section %q is unset", n.Name)
+			}
+			if !fv.IsNil() {
+				fv = fv.Elem()
+			}
+		}
+		if !fv.IsValid() || fv.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("unknown section %q", n.Name)
+		}
+		return fv, nil
+	case *ast.SelectorExpr:
+		x, err := evalSection(n.X, scope)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return evalSection(n.Sel, x)
+	}
+	return reflect.Value{}, fmt.Errorf("unsupported selector %T", node)
+}
+
+func evalLiteral(lit *ast.BasicLit) (any, error) {
+	switch lit.Kind {
+	case token.INT:
+		if i, ok := new(big.Int).SetString(lit.Value, 0); ok {
+			return i, nil
+		}
+		return nil, fmt.Errorf("invalid integer %s", lit.Value)
+	case token.FLOAT:
+		return strconv.ParseFloat(lit.Value, 64)
+	case token.STRING, token.CHAR:
+		return strconv.Unquote(lit.Value)
+	}
+	return nil, fmt.Errorf("unsupported literal %s", lit.Value)
+}
+
+func lookupField(scope reflect.Value, name string) (any, error) {
+	fv, err := fieldByName(scope, name)
+	if err != nil {
+		return nil, err
+	}
+	return exprValue(fv)
+}
+
+func fieldByName(scope reflect.Value, name string) (reflect.Value, error) {
+	if scope.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("unknown field %q", name)
+	}
+	fv := scope.FieldByName(name)
+	if !fv.IsValid() {
+		return reflect.Value{}, fmt.Errorf("unknown field %q", name)
+	}
+	return fv, nil
+}
+
+// exprField resolves an identifier or selector to the field it names.
+func exprField(node ast.Expr, scope reflect.Value) (reflect.Value, error) {
+	switch n := node.(type) {
+	case *ast.Ident:
+		return fieldByName(scope, n.Name)
+	case *ast.SelectorExpr:
+		x, err := evalSection(n.X, scope)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return fieldByName(x, n.Sel.Name)
+	}
+	return reflect.Value{}, fmt.Errorf("unsupported expression %T", node)
+}
+
+func exprValue(fv reflect.Value) (any, error) {
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		return fv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(fv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(fv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), nil
+	case reflect.Slice:
+		// A list compares as its length, e.g. Hosts > 1.
+		return big.NewInt(int64(fv.Len())), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, fv.Kind())
+}
+
+func evalCall(call *ast.CallExpr, scope reflect.Value) (any, error) {
+	fn, ok := call.Fun.(*ast.Ident)
+	if !ok || fn.Name != "len" || len(call.Args) != 1 {
+		return nil, fmt.Errorf("unsupported call")
+	}
+	if fv, err := exprField(call.Args[0], scope); err == nil && fv.Kind() == reflect.Slice {
+		return big.NewInt(int64(fv.Len())), nil
+	}
+	x, err := evalExpr(call.Args[0], scope)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := x.(string); ok {
+		return big.NewInt(int64(len(v))), nil
+	}
+	return nil, fmt.Errorf("len of %T", x)
+}
+
+func evalUnary(op token.Token, x any) (any, error) {
+	switch op {
+	case token.NOT:
+		if b, ok := x.(bool); ok {
+			return !b, nil
+		}
+	case token.SUB:
+		switch v := x.(type) {
+		case *big.Int:
+			return new(big.Int).Neg(v), nil
+		case float64:
+			return -v, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid operation %s%T", op, x)
+}
+
+func evalBinary(n *ast.BinaryExpr, scope reflect.Value) (any, error) {
+	x, err := evalExpr(n.X, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.Op == token.LAND || n.Op == token.LOR {
+		lb, ok := x.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid operation %T %s", x, n.Op)
+		}
+		if (n.Op == token.LAND && !lb) || (n.Op == token.LOR && lb) {
+			return lb, nil
+		}
+		y, err := evalExpr(n.Y, scope)
+		if err != nil {
+			return nil, err
+		}
+		rb, ok := y.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid operation %s %T", n.Op, y)
+		}
+		return rb, nil
+	}
+
+	y, err := evalExpr(n.Y, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	switch l := x.(type) {
+	case *big.Int, float64:
+		switch y.(type) {
+		case *big.Int, float64:
+			return evalNumeric(n.Op, l, y)
+		}
+	case string:
+		if r, ok := y.(string); ok {
+			return evalString(n.Op, l, r)
+		}
+	case bool:
+		if r, ok := y.(bool); ok {
+			switch n.Op {
+			case token.EQL:
+				return l == r, nil
+			case token.NEQ:
+				return l != r, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("invalid operation %T %s %T", x, n.Op, y)
+}
+
+// evalNumeric applies op to two numbers, each an exact *big.Int or a
+// float64. Integer sums, differences and products stay exact; division,
+// and arithmetic involving a float, is done in floating point. Comparisons
+// are exact either way.
+func evalNumeric(op token.Token, l, r any) (any, error) {
+	switch op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return compareNumbers(op, l, r), nil
+	case token.ADD, token.SUB, token.MUL:
+		li, lok := l.(*big.Int)
+		ri, rok := r.(*big.Int)
+		if lok && rok {
+			switch op {
+			case token.ADD:
+				return new(big.Int).Add(li, ri), nil
+			case token.SUB:
+				return new(big.Int).Sub(li, ri), nil
+			}
+			return new(big.Int).Mul(li, ri), nil
+		}
+	case token.QUO:
+	default:
+		return nil, fmt.Errorf("invalid numeric operator %s", op)
+	}
+
+	lf, rf := numberFloat(l), numberFloat(r)
+	switch op {
+	case token.ADD:
+		return lf + rf, nil
+	case token.SUB:
+		return lf - rf, nil
+	case token.MUL:
+		return lf * rf, nil
+	}
+	if rf == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+	return lf / rf, nil
+}
+
+func compareNumbers(op token.Token, l, r any) bool {
+	if math.IsNaN(numberFloat(l)) || math.IsNaN(numberFloat(r)) {
+		return op == token.NEQ
+	}
+	c := numberBig(l).Cmp(numberBig(r))
+	switch op {
+	case token.EQL:
+		return c == 0
+	case token.NEQ:
+		return c != 0
+	case token.LSS:
+		return c < 0
+	case token.LEQ:
+		return c <= 0
+	case token.GTR:
+		return c > 0
+	}
+	return c >= 0
+}
+
+func numberFloat(x any) float64 {
+	if i, ok := x.(*big.Int); ok {
+		f, _ := new(big.Float).SetInt(i).Float64()
+		return f
+	}
+	return x.(float64)
+}
+
+// numberBig converts x to a big.Float without rounding.
+func numberBig(x any) *big.Float {
+	if i, ok := x.(*big.Int); ok {
+		return new(big.Float).SetInt(i)
+	}
+	return big.NewFloat(x.(float64))
+}
+
+func evalString(op token.Token, l, r string) (any, error) {
+	switch op {
+	case token.ADD:
+		return l + r, nil
+	case token.EQL:
+		return l == r, nil
+	case token.NEQ:
+		return l != r, nil
+	case token.LSS:
+		return l < r, nil
+	case token.LEQ:
+		return l <= r, nil
+	case token.GTR:
+		return l > r, nil
+	case token.GEQ:
+		return l >= r, nil
+	}
+	return nil, fmt.Errorf("invalid string operator %s", op)
+}
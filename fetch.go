@@ -0,0 +1,149 @@
+package envx
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// remoteTimeout bounds each request of the network providers and each git
+// command unless they are given their own client, so a hung source cannot
+// hold a reload, and the Loader's lock, forever when WithFetchTimeout is off.
+const remoteTimeout = 30 * time.Second
+
+// ContextProvider is implemented by providers that can abandon a fetch when
+// ctx is done, such as the network providers. The Loader prefers it over
+// Values, so StopWatching and WithFetchTimeout cancel the request itself.
+// A provider with only Values is waited for on a separate goroutine, which
+// the Loader gives up on but cannot stop: a hung call keeps running, even
+// past StopWatching, until it returns.
+type ContextProvider interface {
+	ValuesContext(ctx context.Context) (map[string]any, error)
+}
+
+// ContextChangeDetector is the ChangeDetector counterpart of
+// ContextProvider: the Loader prefers ChangedContext over Changed.
+type ContextChangeDetector interface {
+	ChangedContext(ctx context.Context) (bool, error)
+}
+
+// WithFetchTimeout bounds each provider fetch and each ChangeDetector check.
+// A source that does not answer in time fails the load or reload instead
+// of blocking it.
+func WithFetchTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.fetchTimeout = d
+	}
+}
+
+func withContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+func (o *options) fetchContext() (context.Context, context.CancelFunc) {
+	ctx := o.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if o.fetchTimeout > 0 {
+		return context.WithTimeout(ctx, o.fetchTimeout)
+	}
+	return ctx, func() {}
+}
+
+// callContext runs fn and returns its result, or ctx's error once ctx is
+// done. An abandoned fn finishes in the background; its result is dropped.
+func callContext[R any](ctx context.Context, fn func() (R, error)) (R, error) {
+	if ctx.Done() == nil {
+		return fn()
+	}
+
+	type result struct {
+		val R
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		val, err := fn()
+		done <- result{val, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.val, r.err
+	case <-ctx.Done():
+		var zero R
+		return zero, ctx.Err()
+	}
+}
+
+func fetchProvider(p Provider, o *options) (map[string]any, error) {
+	ctx, cancel := o.fetchContext()
+	defer cancel()
+
+	var values map[string]any
+	var err error
+	if cp, ok := providerAs[ContextProvider](p); ok {
+		values, err = cp.ValuesContext(ctx)
+	} else {
+		values, err = callContext(ctx, p.Values)
+	}
+	if err != nil && ctx.Err() != nil {
+		return nil, fmt.Errorf("envx: %s: %w", providerName(p), ctx.Err())
+	}
+	return values, err
+}
+
+func detectChange(o *options, cd ChangeDetector) (bool, error) {
+	ctx, cancel := o.fetchContext()
+	defer cancel()
+	if ccd, ok := cd.(ContextChangeDetector); ok {
+		return ccd.ChangedContext(ctx)
+	}
+	return callContext(ctx, cd.Changed)
+}
+
+// spawnWatcher runs fn as one of the goroutines StartWatching owns.
+func (l *Loader[T]) spawnWatcher(fn func()) {
+	l.watchWG.Add(1)
+	l.watchers.Add(1)
+	go func() {
+		defer l.watchWG.Done()
+		defer l.watchers.Add(-1)
+		fn()
+	}()
+}
+
+// WatcherCount reports how many goroutines StartWatching started are still
+// running: one per watched file and provider, plus one for
+// WithReloadSignal. It drops to zero once StopWatching returns.
+func (l *Loader[T]) WatcherCount() int {
+	return int(l.watchers.Load())
+}
+
+// displayURL strips the userinfo and query of a source URL, which may carry
+// credentials, for diagnostics. Strings that are not URLs are kept.
+func displayURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		raw, _, _ = strings.Cut(raw, "?")
+		if scheme, rest, ok := strings.Cut(raw, "://"); ok {
+			if i := strings.LastIndex(rest, "@"); i >= 0 {
+				raw = scheme + "://" + rest[i+1:]
+			}
+		}
+		return raw
+	}
+	if u.Scheme == "" || u.Opaque != "" {
+		return raw
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.ForceQuery = false
+	u.Fragment = ""
+	return u.String()
+}
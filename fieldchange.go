@@ -0,0 +1,37 @@
+package envx
+
+import "reflect"
+
+type fieldChange struct {
+	typ    reflect.Type
+	notify func(old, new any)
+}
+
+// WithOnFieldChange calls fn after a reload that changes the value selector
+// picks from the configuration, e.g.
+//
+//	envx.WithOnFieldChange(func(c *Config) any { return c.LogLevel }, func(old, new any) {
+//		logger.SetLevel(new.(string))
+//	})
+//
+// Values are compared with reflect.DeepEqual, so selecting a section
+// reports a change to any of its fields. Callbacks run after WithOnReload,
+// in registration order, in the reload callback goroutine.
+func WithOnFieldChange[T any](selector func(*T) any, fn func(old, new any)) Option {
+	return func(o *options) {
+		o.fieldChanges = append(o.fieldChanges, fieldChange{
+			typ: reflect.TypeOf((*T)(nil)).Elem(),
+			notify: func(old, new any) {
+				oCfg, ok1 := old.(*T)
+				nCfg, ok2 := new.(*T)
+				if !ok1 || !ok2 {
+					return
+				}
+				before, after := selector(oCfg), selector(nCfg)
+				if !reflect.DeepEqual(before, after) {
+					fn(before, after)
+				}
+			},
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package envx
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// WithFileSecrets reads a field's value from the file named by <KEY>_FILE
+// when Env or EnvFile sets that variable but not <KEY> itself, as
+// orchestrators do with DATABASE_PASSWORD_FILE=/run/secrets/db_pass. Other
+// providers, remote ones in particular, cannot make the loader read local
+// files this way. Fields tagged fromFile:"true" opt in for every provider.
+func WithFileSecrets() Option {
+	return func(o *options) {
+		o.fileSecrets = true
+	}
+}
+
+// fileSecretKeys maps the keys that may be read from a <KEY>_FILE variable
+// to whether their field opts in with fromFile, listing every key T reads so
+// a field that is itself named like *_FILE is left alone.
+func fileSecretKeys[T any](o *options) map[string]bool {
+	t, err := resolveStructType[T]()
+	if err != nil {
+		return nil
+	}
+	keys := make(map[string]bool)
+	collectFileSecretKeys(t, "", o, keys)
+	return keys
+}
+
+func collectFileSecretKeys(t reflect.Type, path string, o *options, keys map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if isSection(field.Type) {
+			collectFileSecretKeys(field.Type, path+fieldName(field)+"_", o, keys)
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			collectFileSecretKeys(field.Type.Elem(), path+fieldName(field)+"_", o, keys)
+			continue
+		}
+
+		key := path + fieldName(field)
+		if o.prefix != "" {
+			key = o.prefix + "_" + key
+		}
+		keys[key] = field.Tag.Get("fromFile") == "true"
+	}
+}
+
+// applyFileSecrets replaces <KEY>_FILE entries of one provider's values with
+// the contents of the files they name, for opted-in keys or, when all is
+// set, every key. Trailing line breaks are dropped, as secret files usually
+// end with one.
+func applyFileSecrets(values map[string]any, keys map[string]bool, all bool) (map[string]any, []error) {
+	var out map[string]any
+	var errs []error
+	for key, optIn := range keys {
+		if !optIn && !all {
+			continue
+		}
+		ref, ok := values[key+"_FILE"]
+		if !ok || ref == nil {
+			continue
+		}
+		if v, set := values[key]; set && v != nil {
+			continue
+		}
+
+		if out == nil {
+			out = make(map[string]any, len(values))
+			for k, v := range values {
+				out[k] = v
+			}
+		}
+		if _, field := keys[key+"_FILE"]; !field {
+			delete(out, key+"_FILE")
+		}
+
+		data, err := os.ReadFile(strings.TrimSpace(fmt.Sprint(ref)))
+		if err != nil {
+			errs = append(errs, &Error{Field: key + "_FILE", Err: fmt.Errorf("%w: %v", ErrParse, err)})
+			continue
+		}
+		out[key] = strings.TrimRight(string(data), "\r\n")
+	}
+	if out == nil {
+		return values, errs
+	}
+	return out, errs
+}
+
+// readsLocalEnv reports whether p is Env or EnvFile, the providers
+// WithFileSecrets honors <KEY>_FILE variables from.
+func readsLocalEnv(p Provider) bool {
+	if _, ok := providerAs[*envProvider](p); ok {
+		return true
+	}
+	_, ok := providerAs[*envFileProvider](p)
+	return ok
+}
@@ -0,0 +1,45 @@
+package envx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"sort"
+)
+
+// Fingerprint returns a stable SHA-256 of cfg's resolved values. Secret
+// fields contribute their key but not their value, so the result is safe to
+// log or expose.
+func Fingerprint[T any](cfg *T) string {
+	return fingerprint(cfg, false)
+}
+
+// FingerprintWithSecrets is like Fingerprint but also hashes secret values,
+// detecting differences in credentials without revealing them.
+func FingerprintWithSecrets[T any](cfg *T) string {
+	return fingerprint(cfg, true)
+}
+
+func fingerprint(cfg any, withSecrets bool) string {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ""
+	}
+	v = v.Elem()
+
+	kvs := flattenConfig(v, v.Type(), "")
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].key < kvs[j].key })
+
+	h := sha256.New()
+	for _, kv := range kvs {
+		val := kv.value
+		if !withSecrets && isSecret(kv.field) {
+			val = "<secret>"
+		}
+		h.Write([]byte(kv.key))
+		h.Write([]byte{'='})
+		h.Write([]byte(val))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,29 @@
+package envx
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// FingerprintInfo is the payload served by FingerprintHandler.
+type FingerprintInfo struct {
+	Fingerprint string `json:"fingerprint"`
+	Version     int64  `json:"version"`
+}
+
+// FingerprintHandler serves the loader's current fingerprint as JSON, meant
+// to be mounted at a path such as /debug/config.
+func FingerprintHandler[T any](l *Loader[T]) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snap := l.Pin()
+		if snap.Config() == nil {
+			http.Error(w, ErrNotLoaded.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(FingerprintInfo{
+			Fingerprint: Fingerprint(snap.Config()),
+			Version:     snap.Version(),
+		})
+	})
+}
@@ -0,0 +1,99 @@
+//go:build !envx_minimal && !envx_no_fleet
+
+package envx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+func init() { registerSubsystem("fleet") }
+
+// PeerFingerprint is the result of querying one peer.
+type PeerFingerprint struct {
+	Peer string
+	FingerprintInfo
+	Err error
+}
+
+// FleetReport groups peers by the fingerprint they reported.
+type FleetReport struct {
+	Peers []PeerFingerprint
+}
+
+// Groups maps each fingerprint to the peers reporting it. Unreachable peers
+// are left out.
+func (r FleetReport) Groups() map[string][]string {
+	groups := make(map[string][]string)
+	for _, p := range r.Peers {
+		if p.Err == nil {
+			groups[p.Fingerprint] = append(groups[p.Fingerprint], p.Peer)
+		}
+	}
+	return groups
+}
+
+// Diverged reports whether reachable peers disagree on their configuration.
+func (r FleetReport) Diverged() bool {
+	return len(r.Groups()) > 1
+}
+
+// Failed returns the peers that could not be queried.
+func (r FleetReport) Failed() []PeerFingerprint {
+	var failed []PeerFingerprint
+	for _, p := range r.Peers {
+		if p.Err != nil {
+			failed = append(failed, p)
+		}
+	}
+	return failed
+}
+
+// CheckFingerprints queries every peer URL (served by FingerprintHandler)
+// concurrently and reports which configuration each one runs.
+func CheckFingerprints(ctx context.Context, client *http.Client, peers []string) FleetReport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	results := make([]PeerFingerprint, len(peers))
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		wg.Add(1)
+		go func(i int, peer string) {
+			defer wg.Done()
+			info, err := fetchFingerprint(ctx, client, peer)
+			results[i] = PeerFingerprint{Peer: peer, FingerprintInfo: info, Err: err}
+		}(i, peer)
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Peer < results[j].Peer })
+	return FleetReport{Peers: results}
+}
+
+func fetchFingerprint(ctx context.Context, client *http.Client, url string) (FingerprintInfo, error) {
+	var info FingerprintInfo
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return info, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return info, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return info, fmt.Errorf("envx: %s: unexpected status %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return info, fmt.Errorf("envx: %s: %w", url, err)
+	}
+	return info, nil
+}
@@ -0,0 +1,221 @@
+//go:build !envx_minimal && !envx_no_git
+
+package envx
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+func init() { registerSubsystem("git") }
+
+// gitCachedRefs holds, per ref, the last fetched commit so the provider can
+// serve the cached file when the remote is unreachable. Providers for other
+// refs of the same repository share the cache without overwriting it.
+const gitCachedRefs = "refs/envx/cache/"
+
+type gitProvider struct {
+	repo     string
+	ref      string
+	path     string
+	cacheDir string
+	config   []string // key=value settings, passed in the environment
+	env      []string
+
+	mu     sync.Mutex
+	commit string
+}
+
+// GitOption configures a Git provider.
+type GitOption func(*gitProvider)
+
+// GitCacheDir sets where the repository is cached. It defaults to a
+// directory per repository under the user cache directory.
+func GitCacheDir(dir string) GitOption {
+	return func(p *gitProvider) {
+		p.cacheDir = dir
+	}
+}
+
+// GitBasicAuth authenticates HTTP(S) remotes, e.g. with a username and an
+// access token. The header reaches git through GIT_CONFIG_* environment
+// variables, which need git 2.31 or later, and never its command line.
+func GitBasicAuth(username, password string) GitOption {
+	return func(p *gitProvider) {
+		token := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		p.config = append(p.config, "http.extraHeader=Authorization: Basic "+token)
+	}
+}
+
+// GitSSHKey authenticates SSH remotes with the given private key file.
+func GitSSHKey(path string) GitOption {
+	return func(p *gitProvider) {
+		p.env = append(p.env, "GIT_SSH_COMMAND=ssh -i "+quotePOSIX(path)+" -o IdentitiesOnly=yes")
+	}
+}
+
+// Git returns a provider that reads the file at path (JSON, .env or
+// .properties) from ref (a branch, tag or commit) of a git repository. The
+// repository is fetched shallowly into a local cache using the git command;
+// if a later fetch fails, the last fetched version is served. The provider
+// implements ChangeDetector, so WithWatchProvider reloads when ref moves.
+// Each git command is given 30 seconds.
+func Git(repoURL, ref, path string, opts ...GitOption) Provider {
+	p := &gitProvider{repo: repoURL, ref: ref, path: strings.TrimPrefix(path, "/")}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.cacheDir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		sum := sha256.Sum256([]byte(repoURL))
+		p.cacheDir = filepath.Join(base, "envx", "git", hex.EncodeToString(sum[:8]))
+	}
+	return p
+}
+
+func (p *gitProvider) Name() string { return "git" }
+
+func (p *gitProvider) StrictKeys() bool { return true }
+
+func (p *gitProvider) Describe() ProviderInfo {
+	return ProviderInfo{Source: displayURL(p.repo) + "@" + p.ref + ":" + p.path}
+}
+
+func (p *gitProvider) Values() (map[string]any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.ensureRepo(); err != nil {
+		return nil, err
+	}
+
+	commit, fetchErr := p.fetch()
+	if fetchErr != nil {
+		cached, err := p.git("rev-parse", "--verify", "-q", p.cachedRef())
+		if err != nil {
+			return nil, fetchErr
+		}
+		commit = cached
+	}
+
+	data, err := p.gitBytes("show", commit+":"+p.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEncoding(data, strings.HasSuffix(p.path, ".properties")); err != nil {
+		return nil, &Error{Field: p.repo + "/" + p.path, Err: err}
+	}
+	data, _ = normalizeText(data)
+
+	values, err := decodeFile(data, strings.ToLower(filepath.Ext(p.path)))
+	if err != nil {
+		return nil, err
+	}
+	p.commit = commit
+	return values, nil
+}
+
+// Changed reports whether ref now points to a different commit than the
+// one last loaded. A ref given as a full commit hash never changes.
+func (p *gitProvider) Changed() (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.commit == "" || p.commit == p.ref {
+		return false, nil
+	}
+	if err := p.ensureRepo(); err != nil {
+		return false, err
+	}
+
+	out, err := p.git("ls-remote", "origin", p.ref)
+	if err != nil {
+		return false, err
+	}
+	head, _, _ := strings.Cut(out, "\t")
+	if head == "" {
+		return false, fmt.Errorf("envx: git %s: ref %q not found", p.repo, p.ref)
+	}
+	return head != p.commit, nil
+}
+
+func (p *gitProvider) ensureRepo() error {
+	if _, err := os.Stat(filepath.Join(p.cacheDir, "HEAD")); os.IsNotExist(err) {
+		if err := os.MkdirAll(p.cacheDir, 0o700); err != nil {
+			return err
+		}
+		if _, err := p.git("init", "--bare", "-q"); err != nil {
+			return err
+		}
+	}
+	_, err := p.git("config", "remote.origin.url", p.repo)
+	return err
+}
+
+// fetch updates the cached ref straight from the remote rather than through
+// FETCH_HEAD, which providers sharing the cache would race on.
+func (p *gitProvider) fetch() (string, error) {
+	ref := p.cachedRef()
+	if _, err := p.git("fetch", "-q", "--depth", "1", "--no-tags", "origin", "+"+p.ref+":"+ref); err != nil {
+		return "", err
+	}
+	return p.git("rev-parse", "--verify", "-q", ref)
+}
+
+func (p *gitProvider) cachedRef() string {
+	sum := sha256.Sum256([]byte(p.ref))
+	return gitCachedRefs + hex.EncodeToString(sum[:8])
+}
+
+func (p *gitProvider) git(args ...string) (string, error) {
+	out, err := p.gitBytes(args...)
+	return strings.TrimSpace(string(out)), err
+}
+
+func (p *gitProvider) gitBytes(args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := p.command(ctx, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("envx: git %s: %w: %s", args[0], err, msg)
+		}
+		return nil, fmt.Errorf("envx: git %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// command builds a git invocation on the cache, killed when ctx is done.
+// Settings such as the Authorization header go in the environment, where
+// other local users cannot read them from the process list.
+func (p *gitProvider) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"--git-dir", p.cacheDir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if len(p.config) > 0 {
+		cmd.Env = append(cmd.Env, "GIT_CONFIG_COUNT="+strconv.Itoa(len(p.config)))
+		for i, c := range p.config {
+			key, val, _ := strings.Cut(c, "=")
+			n := strconv.Itoa(i)
+			cmd.Env = append(cmd.Env, "GIT_CONFIG_KEY_"+n+"="+key, "GIT_CONFIG_VALUE_"+n+"="+val)
+		}
+	}
+	cmd.Env = append(cmd.Env, p.env...)
+	return cmd
+}
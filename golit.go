@@ -0,0 +1,256 @@
+package envx
+
+import (
+	"encoding"
+	"fmt"
+	"go/format"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType      = reflect.TypeOf(time.Duration(0))
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// GoLiteralOption configures GoLiteral.
+type GoLiteralOption func(*goLiteralWriter)
+
+// GoLiteralPlaceholders replaces secret strings with their variable name in
+// angle brackets, e.g. "<DATABASE_PASSWORD>", instead of masking them.
+func GoLiteralPlaceholders() GoLiteralOption {
+	return func(w *goLiteralWriter) {
+		w.placeholders = true
+	}
+}
+
+// GoLiteral renders cfg as a gofmt-formatted Go composite literal that
+// compiles in the package declaring T, e.g. to turn a real environment into
+// a unit-test fixture. Zero fields are omitted and secret strings are masked
+// as Print does; other secrets are left at their zero value.
+func GoLiteral[T any](cfg *T, opts ...GoLiteralOption) string {
+	w := goLiteralWriter{mask: true}
+	for _, opt := range opts {
+		opt(&w)
+	}
+	src, _ := goLiteral(reflect.ValueOf(cfg).Elem(), w)
+	return strings.TrimSuffix(string(src), "\n")
+}
+
+// goLiteralWriter renders values as Go expressions. Types declared in the
+// package of the configuration type are left unqualified, so the literal
+// compiles inside that package, e.g. in its tests.
+type goLiteralWriter struct {
+	pkg          string
+	mask         bool
+	placeholders bool
+	path         string // key prefix of the section being written
+}
+
+// goLiteral renders v as a gofmt-formatted composite literal, or unformatted
+// should gofmt reject it.
+func goLiteral(v reflect.Value, w goLiteralWriter) ([]byte, error) {
+	w.pkg = v.Type().PkgPath()
+	const head = "package p\n\nvar _ = "
+	expr := w.value(v)
+	src, err := format.Source([]byte(head + expr + "\n"))
+	if err != nil {
+		return []byte(expr + "\n"), fmt.Errorf("envx: go literal: %w", err)
+	}
+	return src[len(head):], nil
+}
+
+func (w goLiteralWriter) typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" || t.PkgPath() == w.pkg {
+			return t.Name()
+		}
+		return t.String()
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + w.typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + w.typeName(t.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(t.Len()) + "]" + w.typeName(t.Elem())
+	case reflect.Map:
+		return "map[" + w.typeName(t.Key()) + "]" + w.typeName(t.Elem())
+	case reflect.Struct:
+		// Tags are part of the type, so they are spelled out for the literal
+		// to match the field it is assigned to.
+		fields := make([]string, t.NumField())
+		for i := range fields {
+			f := t.Field(i)
+			decl := w.typeName(f.Type)
+			if !f.Anonymous {
+				decl = f.Name + " " + decl
+			}
+			if f.Tag != "" {
+				decl += " " + strconv.Quote(string(f.Tag))
+			}
+			fields[i] = decl
+		}
+		return "struct{" + strings.Join(fields, "; ") + "}"
+	}
+	return t.String()
+}
+
+func (w goLiteralWriter) value(v reflect.Value) string {
+	t := v.Type()
+	if t == durationType {
+		return durationLiteral(time.Duration(v.Int()))
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return floatLiteral(v.Float(), t.Bits())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return "complex(" + floatLiteral(real(c), t.Bits()/2) + ", " + floatLiteral(imag(c), t.Bits()/2) + ")"
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Pointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		if t.Elem().Kind() == reflect.Struct {
+			return "&" + w.value(v.Elem())
+		}
+		elem := w.typeName(t.Elem())
+		return "func() " + w.typeName(t) + " { v := " + elem + "(" + w.value(v.Elem()) + "); return &v }()"
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		return w.typeName(v.Elem().Type()) + "(" + w.value(v.Elem()) + ")"
+	case reflect.Slice:
+		if v.IsNil() {
+			return "nil"
+		}
+		return w.elements(t, v.Len(), func(i int) string { return w.value(v.Index(i)) })
+	case reflect.Array:
+		return w.elements(t, v.Len(), func(i int) string { return w.value(v.Index(i)) })
+	case reflect.Map:
+		if v.IsNil() {
+			return "nil"
+		}
+		keys := v.MapKeys()
+		items := make([]string, len(keys))
+		for i, k := range keys {
+			items[i] = w.value(k) + ": " + w.value(v.MapIndex(k))
+		}
+		sort.Strings(items)
+		return w.elements(t, len(items), func(i int) string { return items[i] })
+	case reflect.Struct:
+		return w.structValue(v)
+	}
+	// Channels and functions do not survive a snapshot.
+	return "nil"
+}
+
+func (w goLiteralWriter) structValue(v reflect.Value) string {
+	t := v.Type()
+	name := w.typeName(t)
+
+	exported := true
+	for i := 0; i < t.NumField(); i++ {
+		exported = exported && t.Field(i).IsExported()
+	}
+	if !exported && t.Implements(textMarshalerType) && reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		// Types such as time.Time or netip.Addr cannot be built from their
+		// fields outside their package, but round trip through text.
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err == nil {
+			return "func() " + name + " { var v " + name + "; _ = v.UnmarshalText([]byte(" + strconv.Quote(string(text)) + ")); return v }()"
+		}
+	}
+
+	var items []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+		if !field.IsExported() || fv.IsZero() {
+			continue
+		}
+		if isSection(field.Type) || isOptionalSection(field.Type) {
+			section := w
+			section.path = w.path + fieldName(field) + "_"
+			items = append(items, field.Name+": "+section.value(fv))
+			continue
+		}
+		val := w.value(fv)
+		if (w.mask || w.placeholders) && isSecret(field) {
+			switch {
+			case fv.Kind() != reflect.String:
+				continue
+			case w.placeholders:
+				val = strconv.Quote("<" + w.path + fieldName(field) + ">")
+			default:
+				val = strconv.Quote(maskSecretValue(fv.String()))
+			}
+		}
+		items = append(items, field.Name+": "+val)
+	}
+	return w.elements(t, len(items), func(i int) string { return items[i] })
+}
+
+// elements writes a composite literal of type t with one element per line.
+func (w goLiteralWriter) elements(t reflect.Type, n int, item func(int) string) string {
+	if n == 0 {
+		return w.typeName(t) + "{}"
+	}
+	var b strings.Builder
+	b.WriteString(w.typeName(t) + "{\n")
+	for i := 0; i < n; i++ {
+		b.WriteString(item(i) + ",\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// durationLiteral writes d in the largest unit that divides it, e.g.
+// "90 * time.Second".
+func durationLiteral(d time.Duration) string {
+	units := []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, u := range units {
+		if d != 0 && d%u.d == 0 {
+			if d == u.d {
+				return u.name
+			}
+			return strconv.FormatInt(int64(d/u.d), 10) + " * " + u.name
+		}
+	}
+	return strconv.FormatInt(int64(d), 10)
+}
+
+func floatLiteral(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return "math.NaN()"
+	case math.IsInf(f, 1):
+		return "math.Inf(1)"
+	case math.IsInf(f, -1):
+		return "math.Inf(-1)"
+	}
+	return strconv.FormatFloat(f, 'g', -1, bits)
+}
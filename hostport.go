@@ -0,0 +1,198 @@
+package envx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HostPort is a network endpoint parsed from "host:port". Fields tagged
+// `resolve:"srv"` also accept a bare SRV name such as
+// "_postgres._tcp.db.example.com", which WithResolve replaces with the
+// highest-priority target.
+type HostPort struct {
+	Host string
+	Port int
+}
+
+var hostPortType = reflect.TypeOf(HostPort{})
+
+func (hp HostPort) String() string {
+	if hp.Port == 0 {
+		return hp.Host
+	}
+	return net.JoinHostPort(hp.Host, strconv.Itoa(hp.Port))
+}
+
+// MarshalText encodes the endpoint in the same form it is parsed from.
+func (hp HostPort) MarshalText() ([]byte, error) {
+	return []byte(hp.String()), nil
+}
+
+func parseHostPort(s string) (HostPort, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "_") && !strings.Contains(s, ":") {
+		return HostPort{Host: s}, nil
+	}
+
+	host, portStr, err := net.SplitHostPort(s)
+	if err != nil {
+		return HostPort{}, err
+	}
+	if host == "" {
+		return HostPort{}, fmt.Errorf("missing host in %q", s)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return HostPort{}, fmt.Errorf("invalid port %q", portStr)
+	}
+	return HostPort{Host: host, Port: port}, nil
+}
+
+func setHostPort(fv reflect.Value, val any) error {
+	hp, err := parseHostPort(fmt.Sprint(val))
+	if err != nil {
+		return err
+	}
+	fv.Set(reflect.ValueOf(hp))
+	return nil
+}
+
+// checkSRVNames rejects bare SRV names, which leave the port unset, on
+// HostPort fields that are not tagged resolve:"srv".
+func checkSRVNames(field reflect.StructField, fv reflect.Value) error {
+	if field.Tag.Get("resolve") == "srv" {
+		return nil
+	}
+	var endpoints []HostPort
+	switch {
+	case fv.Type() == hostPortType:
+		endpoints = append(endpoints, fv.Interface().(HostPort))
+	case fv.Kind() == reflect.Slice && fv.Type().Elem() == hostPortType:
+		for j := 0; j < fv.Len(); j++ {
+			endpoints = append(endpoints, fv.Index(j).Interface().(HostPort))
+		}
+	}
+	for _, hp := range endpoints {
+		if hp.Port == 0 {
+			return fmt.Errorf("%w: missing port in %q", ErrParse, hp.Host)
+		}
+	}
+	return nil
+}
+
+// ResolveMode controls how WithResolve reports endpoints that do not resolve.
+type ResolveMode int
+
+const (
+	ResolveOff ResolveMode = iota
+	ResolveWarn
+	ResolveError
+)
+
+// WithResolve looks up every HostPort at load time, each lookup bounded by
+// timeout (none if zero), and logs (ResolveWarn) or fails with ErrValidation (ResolveError)
+// when an endpoint does not resolve. SRV-tagged fields are replaced by their
+// target.
+func WithResolve(mode ResolveMode, timeout time.Duration) Option {
+	return func(o *options) {
+		o.resolveMode = mode
+		o.resolveTimeout = timeout
+	}
+}
+
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+var resolver hostResolver = net.DefaultResolver
+
+func resolveEndpoints(cfg any, o *options) error {
+	if o.resolveMode == ResolveOff {
+		return nil
+	}
+	v := reflect.ValueOf(cfg).Elem()
+	return resolveStruct(v, v.Type(), "", o)
+}
+
+func resolveStruct(v reflect.Value, t reflect.Type, path string, o *options) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+
+		if isSection(field.Type) {
+			if err := resolveStruct(fv, field.Type, path+fieldName(field)+"_", o); err != nil {
+				return err
+			}
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			if fv.IsNil() {
+				continue
+			}
+			if err := resolveStruct(fv.Elem(), field.Type.Elem(), path+fieldName(field)+"_", o); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := path + fieldName(field)
+		srv := field.Tag.Get("resolve") == "srv"
+		switch {
+		case field.Type == hostPortType:
+			if err := resolveHostPort(fv, key, srv, o); err != nil {
+				return err
+			}
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem() == hostPortType:
+			for j := 0; j < fv.Len(); j++ {
+				if err := resolveHostPort(fv.Index(j), key, srv, o); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func resolveHostPort(fv reflect.Value, key string, srv bool, o *options) error {
+	hp := fv.Interface().(HostPort)
+	if hp.Host == "" || net.ParseIP(hp.Host) != nil {
+		return nil
+	}
+
+	ctx := context.Background()
+	if o.resolveTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.resolveTimeout)
+		defer cancel()
+	}
+
+	var err error
+	if srv && hp.Port == 0 {
+		var addrs []*net.SRV
+		if _, addrs, err = resolver.LookupSRV(ctx, "", "", hp.Host); err == nil {
+			if len(addrs) == 0 {
+				err = fmt.Errorf("no SRV records")
+			} else {
+				target := addrs[0]
+				fv.Set(reflect.ValueOf(HostPort{Host: strings.TrimSuffix(target.Target, "."), Port: int(target.Port)}))
+			}
+		}
+	} else {
+		_, err = resolver.LookupHost(ctx, hp.Host)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if o.resolveMode == ResolveWarn {
+		o.logger.Printf("envx: WARNING: %s: cannot resolve %s: %v\n", key, hp, err)
+		return nil
+	}
+	return &Error{Field: key, Err: fmt.Errorf("%w: cannot resolve %s: %v", ErrValidation, hp, err)}
+}
@@ -0,0 +1,163 @@
+//go:build !envx_minimal && !envx_no_http
+
+package envx
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"sync"
+)
+
+func init() {
+	registerSubsystem("http")
+	bootstrapRemotes["http"] = func(b *Bootstrap) Provider { return HTTP(b.HTTPURL) }
+}
+
+type httpProvider struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	sum          [sha256.Size]byte
+	values       map[string]any
+}
+
+// HTTPOption configures an HTTP provider.
+type HTTPOption func(*httpProvider)
+
+// HTTPHeader adds a header to every request, e.g. an Authorization token.
+func HTTPHeader(key, value string) HTTPOption {
+	return func(p *httpProvider) {
+		p.headers.Add(key, value)
+	}
+}
+
+// HTTPClient sets the HTTP client used to fetch the config, e.g. one with
+// client certificates. The default client times out after 30 seconds.
+func HTTPClient(c *http.Client) HTTPOption {
+	return func(p *httpProvider) {
+		p.client = c
+	}
+}
+
+// HTTP returns a provider that fetches a JSON object from url and flattens
+// it like a JSON file. Requests after the first are conditional on the
+// response's ETag or Last-Modified, so an unchanged config costs a 304. The
+// provider implements ChangeDetector, so WithWatchProvider polls it at the
+// given interval.
+func HTTP(url string, opts ...HTTPOption) Provider {
+	p := &httpProvider{url: url, headers: make(http.Header), client: &http.Client{Timeout: remoteTimeout}}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *httpProvider) Name() string { return "http" }
+
+func (p *httpProvider) StrictKeys() bool { return true }
+
+func (p *httpProvider) Describe() ProviderInfo {
+	return ProviderInfo{Source: displayURL(p.url)}
+}
+
+func (p *httpProvider) Values() (map[string]any, error) {
+	return p.ValuesContext(context.Background())
+}
+
+func (p *httpProvider) ValuesContext(ctx context.Context) (map[string]any, error) {
+	if _, err := p.fetch(ctx); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return maps.Clone(p.values), nil
+}
+
+// Changed fetches the config and reports whether it differs from the last
+// one fetched. Servers that send neither ETag nor Last-Modified are compared
+// by content.
+func (p *httpProvider) Changed() (bool, error) {
+	return p.ChangedContext(context.Background())
+}
+
+func (p *httpProvider) ChangedContext(ctx context.Context) (bool, error) {
+	p.mu.Lock()
+	loaded := p.values != nil
+	p.mu.Unlock()
+
+	if !loaded {
+		return false, nil
+	}
+	return p.fetch(ctx)
+}
+
+// fetch refreshes p.values, reporting whether the content changed. p.mu is
+// not held during the request, so a hung server cannot block other calls.
+func (p *httpProvider) fetch(ctx context.Context) (bool, error) {
+	p.mu.Lock()
+	loaded, etag, lastModified, lastSum := p.values != nil, p.etag, p.lastModified, p.sum
+	p.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return false, fmt.Errorf("envx: http %s: %w", p.url, err)
+	}
+	for k, vs := range p.headers {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Accept", "application/json")
+	if loaded {
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("envx: http %s: %w", p.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && loaded {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("envx: http %s: unexpected status %s", p.url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("envx: http %s: %w", p.url, err)
+	}
+	sum := sha256.Sum256(data)
+	if loaded && sum == lastSum {
+		return false, nil
+	}
+	if err := checkEncoding(data, false); err != nil {
+		return false, &Error{Field: p.url, Err: err}
+	}
+	normalized, _ := normalizeText(data)
+	values, err := decodeFile(normalized, ".json")
+	if err != nil {
+		return false, fmt.Errorf("envx: http %s: %w", p.url, err)
+	}
+
+	p.mu.Lock()
+	p.values = values
+	p.sum = sum
+	p.etag = resp.Header.Get("ETag")
+	p.lastModified = resp.Header.Get("Last-Modified")
+	p.mu.Unlock()
+	return true, nil
+}
@@ -0,0 +1,84 @@
+package envx
+
+import (
+	"reflect"
+	"strings"
+)
+
+func inheritances[T any](prefix string) map[string][]string {
+	t, err := resolveStructType[T]()
+	if err != nil {
+		return nil
+	}
+	return inheritedSections(t, "", prefix)
+}
+
+// inheritedSections maps each shared block named by an `inherit` tag to the
+// key paths of the sections that inherit from it, e.g.
+// `inherit:"DATABASE_COMMON"` on Primary and Replica maps DATABASE_COMMON_
+// to PRIMARY_ and REPLICA_.
+func inheritedSections(t reflect.Type, path string, prefix string) map[string][]string {
+	inherits := make(map[string][]string)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		st := field.Type
+		if isOptionalSection(st) {
+			st = st.Elem()
+		}
+		if !isSection(st) {
+			continue
+		}
+
+		nestedPath := path + fieldName(field) + "_"
+		if common := strings.ToUpper(field.Tag.Get("inherit")); common != "" {
+			if prefix != "" {
+				common = prefix + "_" + common
+			}
+			instance := nestedPath
+			if prefix != "" {
+				instance = prefix + "_" + instance
+			}
+			inherits[common+"_"] = append(inherits[common+"_"], instance)
+		}
+		for k, v := range inheritedSections(st, nestedPath, prefix) {
+			inherits[k] = append(inherits[k], v...)
+		}
+	}
+	return inherits
+}
+
+// applyInherited copies shared keys onto every inheriting section that the
+// same provider did not set explicitly, so per-instance values win within a
+// source and provider precedence still applies across sources.
+func applyInherited(values map[string]any, inherits map[string][]string) map[string]any {
+	if len(inherits) == 0 {
+		return values
+	}
+
+	var out map[string]any
+	for k, v := range values {
+		for common, instances := range inherits {
+			suffix, ok := strings.CutPrefix(k, common)
+			if !ok || suffix == "" {
+				continue
+			}
+			for _, instance := range instances {
+				if _, set := values[instance+suffix]; set {
+					continue
+				}
+				if out == nil {
+					out = make(map[string]any, len(values))
+					for k, v := range values {
+						out[k] = v
+					}
+				}
+				out[instance+suffix] = v
+			}
+		}
+	}
+	if out == nil {
+		return values
+	}
+	return out
+}
@@ -1,9 +1,45 @@
 package envx
 
+import "context"
+
 type Provider interface {
 	Values() (map[string]any, error)
 }
 
+// ChangeDetector is implemented by providers that can cheaply tell whether
+// their source changed since the last Values call, so a Loader can poll
+// them with WithWatchProvider.
+type ChangeDetector interface {
+	Changed() (bool, error)
+}
+
+// ChangeNotifier is implemented by providers whose source pushes change
+// events, so a Loader reloads on each event instead of polling. Watch
+// blocks until ctx is done, calling notify after every change, and returns
+// an error if the event stream breaks.
+type ChangeNotifier interface {
+	Watch(ctx context.Context, notify func()) error
+}
+
 type Validator interface {
 	Validate() error
 }
+
+// ProviderInfo describes a provider in the resolved chain.
+type ProviderInfo struct {
+	Name        string
+	PrefixAware bool
+	// Source locates the data, e.g. a file path or URL, when known.
+	Source string
+	// Secret is set for sources meant to hold secrets.
+	Secret bool
+	// Watchable is set for providers a Loader can watch for changes.
+	Watchable bool
+}
+
+// Describer is implemented by providers that describe themselves for
+// diagnostics such as Loader.Providers and Loader.Status. Fields left zero
+// are derived from the provider's other methods.
+type Describer interface {
+	Describe() ProviderInfo
+}
@@ -0,0 +1,64 @@
+//go:build js && wasm
+
+package envx
+
+import "syscall/js"
+
+type jsGlobalProvider struct {
+	name string
+}
+
+// JSGlobal returns a provider reading the JavaScript object
+// globalThis[name], typically set by the host page or runtime before the
+// module starts. Nested objects are flattened like JSON files, so
+// {db: {poolSize: 5}} sets DB_POOL_SIZE. A missing global yields no values.
+func JSGlobal(name string) Provider {
+	return &jsGlobalProvider{name: name}
+}
+
+func (p *jsGlobalProvider) Name() string { return "js" }
+
+func (p *jsGlobalProvider) Describe() ProviderInfo {
+	return ProviderInfo{Source: "globalThis." + p.name}
+}
+
+func (p *jsGlobalProvider) Values() (map[string]any, error) {
+	values := make(map[string]any)
+	obj := js.Global().Get(p.name)
+	if obj.Type() != js.TypeObject {
+		return values, nil
+	}
+	if m, ok := jsToGo(obj).(map[string]any); ok {
+		flattenMap("", m, values)
+	}
+	return values, nil
+}
+
+// jsToGo converts a JavaScript value into the shapes encoding/json
+// produces.
+func jsToGo(v js.Value) any {
+	switch v.Type() {
+	case js.TypeString:
+		return v.String()
+	case js.TypeNumber:
+		return v.Float()
+	case js.TypeBoolean:
+		return v.Bool()
+	case js.TypeObject:
+		if js.Global().Get("Array").Call("isArray", v).Bool() {
+			items := make([]any, v.Length())
+			for i := range items {
+				items[i] = jsToGo(v.Index(i))
+			}
+			return items
+		}
+		keys := js.Global().Get("Object").Call("keys", v)
+		m := make(map[string]any, keys.Length())
+		for i := 0; i < keys.Length(); i++ {
+			k := keys.Index(i).String()
+			m[k] = jsToGo(v.Get(k))
+		}
+		return m
+	}
+	return nil
+}
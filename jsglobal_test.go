@@ -0,0 +1,41 @@
+//go:build js && wasm
+
+package envx
+
+import (
+	"syscall/js"
+	"testing"
+)
+
+func TestJSGlobalProvider(t *testing.T) {
+	js.Global().Set("envxTestConfig", map[string]any{
+		"port":  8080,
+		"debug": true,
+		"db":    map[string]any{"poolSize": 5, "host": "db.internal"},
+		"hosts": []any{"a", "b"},
+	})
+	defer js.Global().Delete("envxTestConfig")
+
+	type DB struct {
+		PoolSize int
+		Host     string
+	}
+	type Config struct {
+		Port  int
+		Debug bool
+		DB    DB
+		Hosts []string
+	}
+	cfg, err := Load[Config](WithProvider(JSGlobal("envxTestConfig")))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Port != 8080 || !cfg.Debug || cfg.DB.PoolSize != 5 || cfg.DB.Host != "db.internal" || len(cfg.Hosts) != 2 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	cfg, err = Load[Config](WithProvider(JSGlobal("envxMissing")))
+	if err != nil || cfg.Port != 0 {
+		t.Fatalf("expected empty config for a missing global, got %+v, %v", cfg, err)
+	}
+}
@@ -0,0 +1,82 @@
+package envx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// KeyFor returns the variable name the loader derives for a path of Go
+// field names, e.g. "Database.MaxConns" becomes DATABASE_MAX_CONNS, or
+// APP_DATABASE_MAX_CONNS with WithPrefix("app"). Acronyms stay together, so
+// "APIKey" becomes API_KEY. It knows nothing of env tags; FieldKey does.
+func KeyFor(fieldPath string, opts ...Option) string {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	parts := strings.Split(fieldPath, ".")
+	for i, part := range parts {
+		parts[i] = toScreamingSnake(part)
+	}
+	key := strings.Join(parts, "_")
+	if o.prefix != "" {
+		key = o.prefix + "_" + key
+	}
+	return key
+}
+
+// FieldKey is KeyFor for a field of T, honoring env tags. It fails if the
+// path does not name a field T reads.
+func FieldKey[T any](fieldPath string, opts ...Option) (string, error) {
+	t, err := resolveStructType[T]()
+	if err != nil {
+		return "", err
+	}
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var key string
+	names := strings.Split(fieldPath, ".")
+	for i, name := range names {
+		field, ok := exportedField(t, name)
+		if !ok {
+			return "", &Error{Field: fieldPath, Err: fmt.Errorf("%s has no field %s", t, name)}
+		}
+		key += fieldName(field)
+
+		ft := field.Type
+		if isOptionalSection(ft) {
+			ft = ft.Elem()
+		}
+		last := i == len(names)-1
+		switch {
+		case last && isSection(ft):
+			return "", &Error{Field: fieldPath, Err: fmt.Errorf("%s is a section, not a value", name)}
+		case !last && !isSection(ft):
+			return "", &Error{Field: fieldPath, Err: fmt.Errorf("%s is not a section", name)}
+		}
+		key += "_"
+		t = ft
+	}
+
+	key = strings.TrimSuffix(key, "_")
+	if o.prefix != "" {
+		key = o.prefix + "_" + key
+	}
+	return key, nil
+}
+
+// exportedField looks up a field declared directly on t, ignoring fields
+// promoted from embedded structs, which the loader treats as sections.
+func exportedField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.Name == name && field.IsExported() {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,97 @@
+package envx
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// WithSizeLimits caps the length of any single value (maxValue bytes) and
+// the combined size of all keys and values (maxTotal bytes). File providers
+// are also checked before they are read, so an accidentally huge watched
+// file fails the load instead of being pulled into memory. Zero disables a
+// limit. Violations wrap ErrLimit.
+func WithSizeLimits(maxValue int, maxTotal int64) Option {
+	return func(o *options) {
+		o.maxValue = maxValue
+		o.maxTotal = maxTotal
+	}
+}
+
+// sizedProvider is implemented by providers that can report the size of
+// their source before reading it.
+type sizedProvider interface {
+	size() (int64, error)
+}
+
+func (p *fileProvider) size() (int64, error) {
+	info, err := os.Stat(p.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// checkSourceSize rejects providers whose source alone exceeds the total
+// limit.
+func checkSourceSize(p Provider, o *options) error {
+	if o.maxTotal <= 0 {
+		return nil
+	}
+	sp, ok := providerAs[sizedProvider](p)
+	if !ok {
+		return nil
+	}
+	n, err := sp.size()
+	if err != nil || n <= o.maxTotal {
+		return nil
+	}
+	return &Error{Field: providerName(p), Err: fmt.Errorf("%w: source is %d bytes, limit is %d", ErrLimit, n, o.maxTotal)}
+}
+
+// checkValueSizes enforces both limits on the merged values of the keys t
+// reads, so unrelated environment variables do not count.
+func checkValueSizes(t reflect.Type, values map[string]any, o *options) error {
+	if o.maxValue <= 0 && o.maxTotal <= 0 || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var total int64
+	for _, k := range configKeys(t, "") {
+		if o.prefix != "" {
+			k = o.prefix + "_" + k
+		}
+		v, ok := values[k]
+		if !ok {
+			continue
+		}
+		n := valueSize(v)
+		if o.maxValue > 0 && n > o.maxValue {
+			return &Error{Field: k, Err: fmt.Errorf("%w: value is %d bytes, limit is %d", ErrLimit, n, o.maxValue)}
+		}
+		total += int64(len(k) + n)
+	}
+	if o.maxTotal > 0 && total > o.maxTotal {
+		return &Error{Field: "config", Err: fmt.Errorf("%w: values total %d bytes, limit is %d", ErrLimit, total, o.maxTotal)}
+	}
+	return nil
+}
+
+func valueSize(v any) int {
+	switch val := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return len(val)
+	case []any:
+		n := 0
+		for _, item := range val {
+			n += valueSize(item)
+		}
+		return n
+	}
+	return len(fmt.Sprint(v))
+}
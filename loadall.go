@@ -0,0 +1,113 @@
+package envx
+
+import (
+	"context"
+	"maps"
+	"reflect"
+)
+
+// Target is a configuration struct for LoadAll to fill, created with Into,
+// or options shared by every target, created with Shared.
+type Target struct {
+	opts []Option
+	load func(opts []Option) error
+}
+
+// Into targets dst, which is only written when its load succeeds. opts
+// apply to this target after the Shared ones, e.g. WithPrefix to scope it
+// or WithValidator.
+func Into[T any](dst *T, opts ...Option) Target {
+	return Target{opts: opts, load: func(opts []Option) error {
+		_, cfg, err := loadInternal[T](append(opts, summarize)...)
+		if err != nil {
+			return err
+		}
+		*dst = *cfg
+		return nil
+	}}
+}
+
+// Shared holds options applied to every Into target of a LoadAll call,
+// typically the provider chain.
+func Shared(opts ...Option) Target {
+	return Target{opts: opts}
+}
+
+// LoadAll fills several configuration structs from one provider chain in a
+// single pass: each provider is fetched once and each ref+ reference is
+// resolved once, however many targets read them. Errors of all targets are
+// joined; a failing provider or a done ctx stops the pass, and ctx also
+// cancels fetches in flight.
+//
+//	err := envx.LoadAll(ctx,
+//		envx.Shared(envx.WithProvider(envx.Env()), envx.WithProvider(envx.File("config.json"))),
+//		envx.Into(&db, envx.WithPrefix("DB")),
+//		envx.Into(&cache, envx.WithPrefix("CACHE")),
+//	)
+func LoadAll(ctx context.Context, targets ...Target) error {
+	var shared []Option
+	for _, t := range targets {
+		if t.load == nil {
+			shared = append(shared, t.opts...)
+		}
+	}
+
+	pass := &fetchPass{ctx: ctx, results: make(map[Provider]fetchResult), refs: make(map[string]string)}
+	withPass := func(o *options) { o.pass = pass }
+
+	var errs []error
+	for _, t := range targets {
+		if t.load == nil {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		opts := append(append(append([]Option(nil), shared...), withPass, withContext(ctx)), t.opts...)
+		err := t.load(opts)
+		if pass.err != nil {
+			return pass.err
+		}
+		errs = appendErrors(errs, err)
+	}
+	return joinErrors(errs)
+}
+
+// fetchPass shares provider results and resolved references between the
+// targets of one LoadAll call.
+type fetchPass struct {
+	ctx     context.Context
+	results map[Provider]fetchResult
+	refs    map[string]string
+	err     error
+}
+
+type fetchResult struct {
+	values map[string]any
+	err    error
+}
+
+// values returns p's values, calling fetch only the first time p is seen.
+// Each caller gets its own copy, since loading rewrites provider maps.
+func (f *fetchPass) values(p Provider, fetch func() (map[string]any, error)) (map[string]any, error) {
+	if err := f.ctx.Err(); err != nil {
+		f.err = err
+		return nil, err
+	}
+
+	var r fetchResult
+	if reflect.TypeOf(p).Comparable() {
+		var ok bool
+		if r, ok = f.results[p]; !ok {
+			r.values, r.err = fetch()
+			f.results[p] = r
+		}
+	} else {
+		r.values, r.err = fetch()
+	}
+	if r.err != nil {
+		f.err = r.err
+		return nil, r.err
+	}
+	return maps.Clone(r.values), nil
+}
@@ -1,67 +1,223 @@
 package envx
 
 import (
+	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 func Load[T any](opts ...Option) (*T, error) {
-	_, cfg, err := loadInternal[T](opts...)
+	_, cfg, err := loadInternal[T](append(opts[:len(opts):len(opts)], summarize)...)
 	return cfg, err
 }
 
+// LoadWith is Load configured by an Options struct.
+func LoadWith[T any](opts Options) (*T, error) {
+	return Load[T](opts.Option())
+}
+
+// LoadTyped is Load restricted to options bound to T.
+func LoadTyped[T any](opts ...OptionFor[T]) (*T, error) {
+	return Load[T](untyped(opts)...)
+}
+
 func LoadFromEnv[T any](opts ...Option) (*T, error) {
 	withEnv := func(o *options) {
 		o.providers = append([]Provider{
-			DefaultsWithPrefix[T](o.prefix),
-			File(".env"),
-			Env(),
+			&layeredProvider{Provider: DefaultsWithPrefix[T](o.prefix), layer: LayerDefaults},
+			&layeredProvider{Provider: File(".env"), layer: LayerFile},
+			&layeredProvider{Provider: Env(), layer: LayerEnv},
 		}, o.providers...)
 	}
 	return Load[T](append(opts, withEnv)...)
 }
 
 func loadInternal[T any](opts ...Option) (map[string]any, *T, error) {
+	return loadCached[T](nil, false, opts...)
+}
+
+// loadCached is loadInternal reusing the buffers in c, if any. With
+// skipUnchanged it returns errUnchanged before parsing when the merged
+// values match those of the last successful load.
+func loadCached[T any](c *valueCache, skipUnchanged bool, opts ...Option) (_ map[string]any, _ *T, err error) {
 	o := prepareOptions[T](opts)
+	defer func() { err = localizeError(err, o.catalog()) }()
+	if len(o.errs) > 0 {
+		return nil, nil, joinErrors(o.errs)
+	}
+
+	allowed := sourceRestrictions[T](o.prefix)
+	inherits := inheritances[T](o.prefix)
+	known := knownKeys[T](o, inherits)
+	secretFiles := fileSecretKeys[T](o)
 
-	values := make(map[string]any)
+	var values map[string]any
+	if c != nil {
+		values = c.reset()
+	} else {
+		values = make(map[string]any)
+	}
+	var sources map[string]string
+	if o.summarize && o.summaryLogger != nil {
+		sources = make(map[string]string)
+	}
+	var sourceErrs []error
 	for _, p := range o.providers {
-		v, err := p.Values()
+		if err := checkSourceSize(p, o); err != nil {
+			return nil, nil, err
+		}
+		v, err := providerValues[T](p, values, o)
 		if err != nil {
 			return nil, nil, err
 		}
-		pa, ok := p.(prefixAware)
-		if o.prefix != "" && (!ok || !pa.PrefixAware()) {
+		if o.prefix != "" && !isPrefixAware(p) {
 			v = applyPrefix(v, o.prefix)
 		}
+		v, errs := applyFileSecrets(v, secretFiles, o.fileSecrets && readsLocalEnv(p))
+		sourceErrs = append(sourceErrs, errs...)
+		name := providerName(p)
+		if checksKeys(p) {
+			sourceErrs = append(sourceErrs, checkUnknownKeys(v, known, name, o)...)
+		}
+		v = applyInherited(v, inherits)
+		trackSet := c != nil && setsKeys(p)
+		var label string
+		if c != nil {
+			label = sourceLabel(p)
+		}
 		for k, val := range v {
+			if !sourceAllowed(allowed, k, p, name) {
+				continue
+			}
+			if c != nil {
+				k = c.key(k)
+			}
+			if sources != nil && val != nil {
+				sources[k] = name
+			}
+			if trackSet && val != nil {
+				c.set[k] = true
+			}
+			if c != nil && val != nil {
+				c.origin[k] = label
+			}
 			values[k] = val
 		}
 	}
+	if err := joinErrors(sourceErrs); err != nil {
+		return nil, nil, err
+	}
+	if c != nil && o.removals != nil {
+		if err := applyRemovals[T](values, c, o.removals, o); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	var cfg T
-	if err := parse(&cfg, values, o.prefix); err != nil {
+	if err := resolveRefs(reflect.TypeOf(cfg), values, o); err != nil {
+		return nil, nil, err
+	}
+
+	var hash uint64
+	if c != nil {
+		hash = c.sum(values)
+		if skipUnchanged && c.valid && hash == c.hash {
+			return values, nil, errUnchanged
+		}
+		c.valid = false
+	}
+
+	if err := checkValueSizes(reflect.TypeOf(cfg), values, o); err != nil {
 		return nil, nil, err
 	}
 
-	if err := validateRequired(&cfg); err != nil {
+	if err := checkSchema(reflect.TypeOf(cfg), values, o); err != nil {
 		return nil, nil, err
 	}
 
-	if err := runOptionValidator(o.validator, &cfg); err != nil {
+	// Field-level problems are collected together; validators only see a
+	// configuration that passed them.
+	errs := appendErrors(nil, parse(&cfg, values, o.prefix))
+	errs = appendErrors(errs, resolveEndpoints(&cfg, o))
+	fillDevSecrets(&cfg, o)
+	errs = appendErrors(errs, validateRequired(&cfg, o.profile))
+	errs = appendErrors(errs, validateTags(&cfg, values, o.prefix))
+	errs = appendErrors(errs, validateExpressions(&cfg))
+	if err := joinErrors(dedupeFieldErrors(errs, o.prefix)); err != nil {
 		return nil, nil, err
 	}
 
-	if err := runTypeValidator(&cfg); err != nil {
+	errs = appendErrors(nil, runOptionValidator(o.validator, &cfg))
+	errs = appendErrors(errs, runTypeValidator(&cfg))
+	if err := joinErrors(errs); err != nil {
 		return nil, nil, err
 	}
 
+	if err := checkPolicies(&cfg, o); err != nil {
+		return nil, nil, err
+	}
+
+	if c != nil {
+		c.hash, c.valid = hash, true
+	}
+	if sources != nil {
+		logStartupSummary(o, &cfg, sources)
+	}
 	return values, &cfg, nil
 }
 
+// dedupeFieldErrors keeps the first error reported for each field, so a
+// value that failed to parse is not also reported as missing. Parse errors
+// name the prefixed key, later checks the unprefixed one.
+func dedupeFieldErrors(errs []error, prefix string) []error {
+	seen := make(map[string]bool)
+	out := errs[:0]
+	for _, err := range errs {
+		if e, ok := err.(*Error); ok {
+			field := e.Field
+			if prefix != "" {
+				field = strings.TrimPrefix(field, prefix+"_")
+			}
+			if seen[field] {
+				continue
+			}
+			seen[field] = true
+		}
+		out = append(out, err)
+	}
+	return out
+}
+
+func providerValues[T any](p Provider, current map[string]any, o *options) (map[string]any, error) {
+	if rp, ok := providerAs[resolvingProvider](p); ok {
+		return rp.resolve(reflect.TypeOf((*T)(nil)).Elem(), current, o)
+	}
+	if o.pass != nil {
+		return o.pass.values(p, func() (map[string]any, error) { return fetchValues(p, o) })
+	}
+	return fetchValues(p, o)
+}
+
+func fetchValues(p Provider, o *options) (map[string]any, error) {
+	if np, ok := providerAs[normalizingProvider](p); ok && o.warnNormalized {
+		values, notes, err := np.normalizedValues()
+		for _, note := range notes {
+			o.logger.Printf("envx: WARNING: %s\n", note)
+		}
+		return values, err
+	}
+	return fetchProvider(p, o)
+}
+
 func prepareOptions[T any](opts []Option) *options {
 	o := defaultOptions()
 	for _, opt := range opts {
@@ -72,63 +228,273 @@ func prepareOptions[T any](opts []Option) *options {
 }
 
 func finalizeOptions[T any](o *options) {
+	applyEnvSettings(o)
 	if o.logger == nil {
 		o.logger = newWriterLogger(os.Stdout)
 	}
-	if len(o.providers) == 0 {
-		o.providers = []Provider{
+	// Override layers sit on top of the chain rather than replace it, so
+	// they alone do not opt out of the implicit one.
+	if onlyOverrides(o.providers) {
+		o.providers = append([]Provider{
 			DefaultsWithPrefix[T](o.prefix),
 			Env(),
+		}, o.providers...)
+	}
+	o.providers = orderByLayer(o.providers)
+	if len(o.precedence) > 0 {
+		o.providers = orderByPrecedence(o.providers, o.precedence)
+	}
+}
+
+// onlyOverrides reports whether every provider, if any, is in the
+// override layer.
+func onlyOverrides(providers []Provider) bool {
+	for _, p := range providers {
+		if providerLayer(p) != LayerOverride {
+			return false
 		}
 	}
+	return true
+}
+
+func orderByLayer(providers []Provider) []Provider {
+	ordered := make([]Provider, len(providers))
+	copy(ordered, providers)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return providerLayer(ordered[i]) < providerLayer(ordered[j])
+	})
+	return ordered
+}
+
+func orderByPrecedence(providers []Provider, names []string) []Provider {
+	rank := make(map[string]int, len(names))
+	for i, name := range names {
+		rank[name] = i + 1
+	}
+
+	ordered := make([]Provider, len(providers))
+	copy(ordered, providers)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank[providerName(ordered[i])] < rank[providerName(ordered[j])]
+	})
+	return ordered
 }
 
 func (l *Loader[T]) reloadConfig(o *options) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	oldConfig := l.config
-	_, newConfig, err := loadInternal[T](l.opts...)
+	if delay := l.reloadDelay(o, time.Now()); delay > 0 {
+		l.deferReload(o, delay)
+		return
+	}
 
-	if err != nil {
+	if err := l.reloadLocked(o); err != nil {
 		l.logReloadError(o, "reload failed", err)
+	}
+}
+
+// scheduleReload reloads after a change was detected: at once, or with
+// WithReloadDebounce once no further change arrived for the window.
+func (l *Loader[T]) scheduleReload(o *options) {
+	if o.reloadDebounce <= 0 {
+		l.reloadConfig(o)
 		return
 	}
 
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.debounceTimer != nil {
+		l.debounceTimer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(o.reloadDebounce, func() {
+		l.mu.Lock()
+		if l.debounceTimer != timer {
+			// Superseded by a later change.
+			l.mu.Unlock()
+			return
+		}
+		l.debounceTimer = nil
+		l.mu.Unlock()
+		l.reloadConfig(o)
+	})
+	l.debounceTimer = timer
+}
+
+// Reload reloads the configuration from all sources now and returns the
+// error instead of logging it, e.g. for an admin endpoint. It is not
+// delayed by WithMaxReloadRate or WithReloadWindow, but with WithApproval
+// the result is staged like any reload. Before the first Load it loads.
+func (l *Loader[T]) Reload() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.config == nil {
+		_, err := l.loadLocked()
+		return err
+	}
+	return l.reloadLocked(prepareOptions[T](l.opts))
+}
+
+func (l *Loader[T]) reloadLocked(o *options) error {
+	if err := l.ensureOverrides(o); err != nil {
+		l.lastErr = err
+		return err
+	}
+
+	oldConfig := l.config
+	opts := l.loadOptions()
+	opts = append(opts[:len(opts):len(opts)], withRemovals(l.removalState))
+	if o.ctx != nil {
+		opts = append(opts, withContext(o.ctx))
+	}
+	values, newConfig, err := loadCached[T](&l.cache, true, opts...)
+	if err == errUnchanged {
+		l.noteKept()
+		return nil
+	}
+	l.lastErr = err
+	if err != nil {
+		l.recordEvent(EventReloadFailed, nil, err)
+		return err
+	}
+
 	if reflect.DeepEqual(oldConfig, newConfig) {
-		return
+		l.pending = nil
+		l.sources = l.captureSources()
+		l.removalState = l.removals(values)
+		return nil
+	}
+
+	if o.approval {
+		if !reflect.DeepEqual(l.pending, newConfig) {
+			l.pending = newConfig
+			l.pendingSources = l.captureSources()
+			l.pendingRemovals = l.removals(values)
+			o.logger.Printf("envx: reload staged, awaiting approval\n")
+			l.recordEvent(EventStaged, diffConfigs(oldConfig, newConfig), nil)
+		}
+		return nil
 	}
 
 	l.config = newConfig
+	l.sources = l.captureSources()
+	l.removalState = l.removals(values)
 	l.version++
-	l.triggerOnReload(oldConfig, newConfig)
+	l.recordEvent(EventReload, diffConfigs(oldConfig, newConfig), nil)
+	l.recordReload(o, time.Now())
+	l.triggerOnReload(o, oldConfig, newConfig)
+	return nil
+}
+
+// reloadDelay returns how long a reload must wait to honor
+// WithMaxReloadRate and WithReloadWindow, or zero if it may run now.
+func (l *Loader[T]) reloadDelay(o *options, now time.Time) time.Duration {
+	delay := l.rateDelay(o, now)
+	if o.reloadWindow != nil {
+		delay = max(delay, o.reloadWindow.delay(now))
+	}
+	return delay
+}
+
+func (l *Loader[T]) rateDelay(o *options, now time.Time) time.Duration {
+	if o.reloadLimit <= 0 || o.reloadPer <= 0 {
+		return 0
+	}
+
+	cutoff := now.Add(-o.reloadPer)
+	recent := l.reloads[:0]
+	for _, at := range l.reloads {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+	l.reloads = recent
+
+	if len(l.reloads) < o.reloadLimit {
+		return 0
+	}
+	return l.reloads[0].Add(o.reloadPer).Sub(now)
+}
+
+func (l *Loader[T]) recordReload(o *options, at time.Time) {
+	if o.reloadLimit > 0 {
+		l.reloads = append(l.reloads, at)
+	}
+}
+
+func (l *Loader[T]) deferReload(o *options, delay time.Duration) {
+	if l.reloadTimer != nil {
+		return
+	}
+	l.reloadTimer = time.AfterFunc(delay, func() {
+		l.mu.Lock()
+		l.reloadTimer = nil
+		l.mu.Unlock()
+		l.reloadConfig(o)
+	})
 }
 
 func (l *Loader[T]) logReloadError(o *options, msg string, err error) {
 	o.logger.Printf("envx: %s: %v\n", msg, err)
-	if o.onReloadError != nil {
+	if o.onReloadError == nil {
+		return
+	}
+	var panicErr error
+	func() {
+		defer recoverPanic("OnReloadError", &panicErr)
 		o.onReloadError(err)
+	}()
+	if panicErr != nil {
+		o.logger.Printf("envx: %v\n", panicErr)
+	}
+}
+
+// triggerOnReload runs the reload callbacks in their own goroutine, where a
+// panic is reported like a failed reload instead of crashing the process,
+// and notifies subscribers. The caller holds l.mu.
+func (l *Loader[T]) triggerOnReload(o *options, oldConfig, newConfig *T) {
+	defer l.publish(ChangeEvent[T]{Old: oldConfig, New: newConfig, Version: l.version})
+	if l.onReload == nil && len(o.fieldChanges) == 0 {
+		return
 	}
+	go func() {
+		if l.onReload != nil {
+			l.runCallback(o, "OnReload", func() { l.onReload(oldConfig, newConfig) })
+		}
+		for _, fc := range o.fieldChanges {
+			l.runCallback(o, "OnFieldChange", func() { fc.notify(oldConfig, newConfig) })
+		}
+	}()
 }
 
-func (l *Loader[T]) triggerOnReload(oldConfig, newConfig *T) {
-	if l.onReload != nil {
-		go l.onReload(oldConfig, newConfig)
+func (l *Loader[T]) runCallback(o *options, name string, fn func()) {
+	var err error
+	func() {
+		defer recoverPanic(name, &err)
+		fn()
+	}()
+	if err != nil {
+		l.logReloadError(o, "reload callback failed", err)
 	}
 }
 
-func runOptionValidator[T any](validator func(any) error, cfg *T) error {
+func runOptionValidator[T any](validator func(any) error, cfg *T) (err error) {
 	if validator == nil {
 		return nil
 	}
+	defer recoverPanic("validator", &err)
 	return wrapValidationError(validator(cfg))
 }
 
-func runTypeValidator[T any](cfg *T) error {
+func runTypeValidator[T any](cfg *T) (err error) {
 	v, ok := any(cfg).(Validator)
 	if !ok {
 		return nil
 	}
+	defer recoverPanic("Validate", &err)
 	return wrapValidationError(v.Validate())
 }
 
@@ -161,22 +527,154 @@ type Loader[T any] struct {
 	version    int64
 	stop       chan struct{}
 	watchWG    sync.WaitGroup
+	watchers   atomic.Int32
 	mu         sync.RWMutex
 	isWatching bool
 	onReload   func(any, any)
+
+	reloads       []time.Time
+	reloadTimer   *time.Timer
+	debounceTimer *time.Timer
+
+	overrides       map[string]string
+	overridesLoaded bool
+
+	lastErr error
+
+	readyMu sync.Mutex
+	ready   chan struct{}
+
+	cancelMu    sync.Mutex
+	cancelWatch context.CancelFunc
+
+	subMu       sync.Mutex
+	subscribers map[chan ChangeEvent[T]]struct{}
+
+	pending *T
+
+	// removalState describes the applied configuration for onRemove, and
+	// pendingRemovals the staged one.
+	removalState    *removalState
+	pendingRemovals *removalState
+
+	// sources labels where each key of the applied configuration came
+	// from, and pendingSources those of the staged one.
+	sources        map[string]string
+	pendingSources map[string]string
+
+	events       []Event
+	eventLogSize int
+
+	cache valueCache
+
+	statusMu sync.Mutex
+	status   []*watchState
 }
 
 type prefixAware interface {
 	PrefixAware() bool
 }
 
+type namedProvider interface {
+	Name() string
+}
+
+type wrappedProvider interface {
+	unwrap() Provider
+}
+
+type layeredProvider struct {
+	Provider
+	layer Layer
+}
+
+func (p *layeredProvider) unwrap() Provider { return p.Provider }
+
+func providerLayer(p Provider) Layer {
+	if lp, ok := p.(*layeredProvider); ok {
+		return lp.layer
+	}
+	return LayerFile
+}
+
+// providerAs finds the outermost provider in p's wrapper chain that
+// implements I.
+func providerAs[I any](p Provider) (I, bool) {
+	for {
+		if i, ok := p.(I); ok {
+			return i, true
+		}
+		w, ok := p.(wrappedProvider)
+		if !ok {
+			var zero I
+			return zero, false
+		}
+		p = w.unwrap()
+	}
+}
+
+func isPrefixAware(p Provider) bool {
+	pa, ok := providerAs[prefixAware](p)
+	return ok && pa.PrefixAware()
+}
+
+func describeProvider(p Provider) ProviderInfo {
+	var info ProviderInfo
+	if d, ok := providerAs[Describer](p); ok {
+		info = d.Describe()
+	}
+	info.Name = providerName(p)
+	info.PrefixAware = isPrefixAware(p)
+	if _, ok := providerAs[ChangeDetector](p); ok {
+		info.Watchable = true
+	}
+	if _, ok := providerAs[ChangeNotifier](p); ok {
+		info.Watchable = true
+	}
+	return info
+}
+
+func providerName(p Provider) string {
+	if n, ok := providerAs[namedProvider](p); ok {
+		return n.Name()
+	}
+	if d, ok := providerAs[Describer](p); ok {
+		if name := d.Describe().Name; name != "" {
+			return name
+		}
+	}
+	for {
+		w, ok := p.(wrappedProvider)
+		if !ok {
+			return fmt.Sprintf("%T", p)
+		}
+		p = w.unwrap()
+	}
+}
+
 func NewLoader[T any](opts ...Option) *Loader[T] {
 	l := &Loader[T]{opts: opts}
 	o := prepareOptions[T](opts)
 	l.onReload = o.onReload
+	l.eventLogSize = o.eventLogSize
+	for _, w := range o.watches {
+		if problem := watchPathProblem(o, w.path); problem != "" {
+			o.logger.Printf("envx: WARNING: %s, reloads will not see its changes\n", problem)
+		}
+	}
 	return l
 }
 
+// NewLoaderWith is NewLoader configured by an Options struct.
+func NewLoaderWith[T any](opts Options) *Loader[T] {
+	return NewLoader[T](opts.Option())
+}
+
+// NewTypedLoader is NewLoader restricted to options bound to T.
+func NewTypedLoader[T any](opts ...OptionFor[T]) *Loader[T] {
+	return NewLoader[T](untyped(opts)...)
+}
+
 func (l *Loader[T]) Load() (*T, error) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -184,17 +682,52 @@ func (l *Loader[T]) Load() (*T, error) {
 }
 
 func (l *Loader[T]) loadLocked() (*T, error) {
-	_, cfg, err := loadInternal[T](l.opts...)
+	if err := l.ensureOverrides(prepareOptions[T](l.opts)); err != nil {
+		return nil, err
+	}
+
+	opts := l.loadOptions()
+	if l.config == nil {
+		opts = append(opts[:len(opts):len(opts)], summarize)
+	}
+	values, cfg, err := loadCached[T](&l.cache, false, opts...)
+	l.lastErr = err
 	if err != nil {
+		l.recordEvent(EventLoadFailed, nil, err)
 		return nil, err
 	}
 
+	var changes []Change
+	if l.config != nil {
+		changes = diffConfigs(l.config, cfg)
+	}
 	l.config = cfg
 	l.version++
+	l.removalState = l.removals(values)
+	l.sources = l.captureSources()
+	l.markReady()
+	l.recordEvent(EventLoad, changes, nil)
 
 	return cfg, nil
 }
 
+func (l *Loader[T]) load() error {
+	_, err := l.Load()
+	return err
+}
+
+// Err returns the error of the most recent load or reload, ErrNotLoaded if
+// nothing has been loaded yet, or nil if the current configuration is healthy.
+func (l *Loader[T]) Err() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.lastErr == nil && l.config == nil {
+		return ErrNotLoaded
+	}
+	return l.lastErr
+}
+
 func (l *Loader[T]) MustLoad() *T {
 	cfg, err := l.Load()
 	if err != nil {
@@ -209,6 +742,39 @@ func (l *Loader[T]) Get() *T {
 	return l.config
 }
 
+// Providers returns the resolved provider chain, from lowest to highest
+// precedence.
+func (l *Loader[T]) Providers() []ProviderInfo {
+	l.mu.RLock()
+	o := prepareOptions[T](l.loadOptions())
+	l.mu.RUnlock()
+
+	infos := make([]ProviderInfo, len(o.providers))
+	for i, p := range o.providers {
+		infos[i] = describeProvider(p)
+	}
+	return infos
+}
+
+// Snapshot is a configuration paired with the version it was loaded as.
+// Reloads never modify a pinned configuration; callers must not either.
+type Snapshot[T any] struct {
+	config  *T
+	version int64
+}
+
+func (s Snapshot[T]) Config() *T { return s.config }
+
+func (s Snapshot[T]) Version() int64 { return s.version }
+
+// Pin returns the current configuration and version as one consistent
+// snapshot, for operations that must not observe reloads mid-flight.
+func (l *Loader[T]) Pin() Snapshot[T] {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return Snapshot[T]{config: l.config, version: l.version}
+}
+
 func (l *Loader[T]) Version() int64 {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
@@ -225,7 +791,7 @@ func (l *Loader[T]) StartWatching() error {
 
 	o := prepareOptions[T](l.opts)
 
-	if o.watchPath == "" {
+	if len(o.watches) == 0 && len(o.watchProviders) == 0 && len(o.reloadSignals) == 0 {
 		return nil
 	}
 
@@ -233,47 +799,176 @@ func (l *Loader[T]) StartWatching() error {
 		return err
 	}
 
-	if o.watchEvery <= 0 {
-		err := fmt.Errorf("envx: watch interval must be greater than zero")
-		o.logger.Printf("%v\n", err)
-		return err
+	for _, w := range o.watches {
+		if w.interval <= 0 {
+			err := fmt.Errorf("envx: watch interval must be greater than zero")
+			o.logger.Printf("%v\n", err)
+			return err
+		}
+	}
+	for _, w := range o.watchProviders {
+		if w.interval <= 0 {
+			err := fmt.Errorf("envx: watch interval must be greater than zero")
+			o.logger.Printf("%v\n", err)
+			return err
+		}
+	}
+
+	// Browsers running js/wasm have no filesystem; skip file watches
+	// instead of polling paths that can never be read.
+	var files []fileWatch
+	for _, w := range o.watches {
+		if _, err := watchStat(w.path); errors.Is(err, errors.ErrUnsupported) {
+			o.logger.Printf("envx: WARNING: file watching is not supported on this platform, not watching %s\n", w.path)
+			continue
+		}
+		files = append(files, w)
+	}
+	if len(files) == 0 && len(o.watchProviders) == 0 && len(o.reloadSignals) == 0 {
+		return nil
 	}
 
 	l.stop = make(chan struct{})
 	l.watchWG = sync.WaitGroup{}
-	l.watchWG.Add(1)
 	l.isWatching = true
 
-	watcher := newWatchLoop(l, o, os.Stat)
-	go watcher.run(l.stop, &l.watchWG)
+	// Reloads started by the watchers fetch under this context, so
+	// StopWatching can abandon a fetch from a source that hangs.
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancelMu.Lock()
+	l.cancelWatch = cancel
+	l.cancelMu.Unlock()
+	o.ctx = ctx
+	stop := l.stop
+
+	l.statusMu.Lock()
+	l.status = l.status[:0]
+	l.statusMu.Unlock()
+
+	for _, w := range files {
+		watcher := newWatchLoop(l, o, w, watchStat)
+		l.spawnWatcher(func() { watcher.run(stop) })
+	}
+	for _, w := range o.watchProviders {
+		state := l.trackWatch(watchSource(w.provider), w.interval)
+		if w.notifier != nil {
+			l.spawnWatcher(func() { l.followProvider(o, w, state, stop) })
+			continue
+		}
+		l.spawnWatcher(func() { l.pollProvider(o, w, state, stop) })
+	}
+	if len(o.reloadSignals) > 0 {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, o.reloadSignals...)
+		l.spawnWatcher(func() { l.reloadOnSignal(o, signals, stop) })
+	}
 
 	return nil
 }
 
+// reloadOnSignal reloads whenever one of the WithReloadSignal signals
+// arrives.
+func (l *Loader[T]) reloadOnSignal(o *options, signals chan os.Signal, stop <-chan struct{}) {
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case sig := <-signals:
+			o.logger.Printf("envx: received %s, reloading\n", sig)
+			l.reloadConfig(o)
+		}
+	}
+}
+
+// pollProvider reloads whenever w's provider reports a change.
+func (l *Loader[T]) pollProvider(o *options, w providerWatch, state *watchState, stop <-chan struct{}) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			changed, err := detectChange(o, w.detector)
+			l.recordCheck(state, changed, err)
+			if err != nil {
+				l.logReloadError(o, "change check failed", err)
+				continue
+			}
+			if changed {
+				l.scheduleReload(o)
+			}
+		}
+	}
+}
+
+// followProvider reloads on every event w's provider pushes. When the
+// event stream breaks it waits w.interval, reloads in case events were
+// missed, and reconnects.
+func (l *Loader[T]) followProvider(o *options, w providerWatch, state *watchState, stop <-chan struct{}) {
+	ctx := o.ctx
+	for {
+		err := w.notifier.Watch(ctx, func() {
+			l.recordCheck(state, true, nil)
+			l.scheduleReload(o)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("event stream closed")
+		}
+		l.recordCheck(state, false, err)
+		l.logReloadError(o, "change stream failed", err)
+
+		timer := time.NewTimer(w.interval)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		l.reloadConfig(o)
+	}
+}
+
 type statFunc func(string) (os.FileInfo, error)
 
+var watchStat statFunc = os.Stat
+
+var watchRead = os.ReadFile
+
 type watchLoop[T any] struct {
 	loader   *Loader[T]
 	opts     *options
 	path     string
 	interval time.Duration
 	stat     statFunc
+	state    *watchState
 }
 
-func newWatchLoop[T any](loader *Loader[T], opts *options, stat statFunc) watchLoop[T] {
+func newWatchLoop[T any](loader *Loader[T], opts *options, w fileWatch, stat statFunc) watchLoop[T] {
 	return watchLoop[T]{
 		loader:   loader,
 		opts:     opts,
-		path:     opts.watchPath,
-		interval: opts.watchEvery,
+		path:     w.path,
+		interval: w.interval,
 		stat:     stat,
+		state:    loader.trackWatch(w.path, w.interval),
 	}
 }
 
-func (w watchLoop[T]) run(stop <-chan struct{}, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	lastMod := w.modTime()
+func (w watchLoop[T]) run(stop <-chan struct{}) {
+	var lastMod time.Time
+	var lastSum [sha256.Size]byte
+	if w.opts.watchContent {
+		lastSum, _ = w.contentSum()
+	} else {
+		lastMod = w.modTime()
+	}
 	ticker := time.NewTicker(w.interval)
 	defer ticker.Stop()
 
@@ -282,22 +977,56 @@ func (w watchLoop[T]) run(stop <-chan struct{}, wg *sync.WaitGroup) {
 		case <-stop:
 			return
 		case <-ticker.C:
-			info, err := w.stat(w.path)
-			if err != nil {
-				continue
+			var changed bool
+			var err error
+			if w.opts.watchContent {
+				changed, err = w.contentChanged(&lastSum)
+			} else {
+				changed, err = w.modTimeChanged(&lastMod)
 			}
-
-			modTime := info.ModTime()
-			if !modTime.After(lastMod) {
-				continue
+			w.loader.recordCheck(w.state, changed, err)
+			if changed {
+				w.loader.scheduleReload(w.opts)
 			}
-
-			lastMod = modTime
-			w.loader.reloadConfig(w.opts)
 		}
 	}
 }
 
+func (w watchLoop[T]) modTimeChanged(lastMod *time.Time) (bool, error) {
+	info, err := w.stat(w.path)
+	if err != nil {
+		return false, err
+	}
+	modTime := info.ModTime()
+	if !modTime.After(*lastMod) {
+		return false, nil
+	}
+	*lastMod = modTime
+	return true, nil
+}
+
+// contentChanged reads the file, following symlinks, and compares its hash
+// with the last one seen.
+func (w watchLoop[T]) contentChanged(lastSum *[sha256.Size]byte) (bool, error) {
+	sum, err := w.contentSum()
+	if err != nil {
+		return false, err
+	}
+	if sum == *lastSum {
+		return false, nil
+	}
+	*lastSum = sum
+	return true, nil
+}
+
+func (w watchLoop[T]) contentSum() ([sha256.Size]byte, error) {
+	data, err := watchRead(w.path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
+
 func (w watchLoop[T]) modTime() time.Time {
 	info, err := w.stat(w.path)
 	if err != nil {
@@ -320,6 +1049,15 @@ func (l *Loader[T]) ensureConfigLoaded(o *options) error {
 }
 
 func (l *Loader[T]) StopWatching() {
+	// Cancel before taking l.mu, which a reload blocked on a hung source
+	// holds.
+	l.cancelMu.Lock()
+	if l.cancelWatch != nil {
+		l.cancelWatch()
+		l.cancelWatch = nil
+	}
+	l.cancelMu.Unlock()
+
 	l.mu.Lock()
 	if !l.isWatching {
 		l.mu.Unlock()
@@ -331,6 +1069,14 @@ func (l *Loader[T]) StopWatching() {
 
 	l.stop = nil
 	l.isWatching = false
+	if l.reloadTimer != nil {
+		l.reloadTimer.Stop()
+		l.reloadTimer = nil
+	}
+	if l.debounceTimer != nil {
+		l.debounceTimer.Stop()
+		l.debounceTimer = nil
+	}
 	l.mu.Unlock()
 
 	if stop != nil {
@@ -0,0 +1,164 @@
+package envx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Locale is a BCP 47 language tag such as "pt-BR" or "zh-Hant-TW". Values
+// are checked for well-formed syntax and stored in canonical case; whether
+// the subtags are registered is not checked, which avoids a dependency on
+// golang.org/x/text.
+type Locale string
+
+var localeType = reflect.TypeOf(Locale(""))
+
+// ParseLocale validates s as a well-formed BCP 47 tag and returns it in
+// canonical case. Underscores are accepted as separators ("pt_BR").
+func ParseLocale(s string) (Locale, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "_", "-")
+	if s == "" {
+		return "", fmt.Errorf("empty language tag")
+	}
+
+	subtags := strings.Split(s, "-")
+	for _, st := range subtags {
+		if st == "" || len(st) > 8 || !isAlnum(st) {
+			return "", fmt.Errorf("invalid language tag %q", s)
+		}
+	}
+
+	out := make([]string, 0, len(subtags))
+	i := 0
+	lang := strings.ToLower(subtags[0])
+	switch {
+	case lang == "x":
+		// private use only, e.g. x-whatever
+	case (len(lang) >= 2 && len(lang) <= 3 || len(lang) >= 5) && isAlpha(lang):
+		out = append(out, lang)
+		i = 1
+		for n := 0; n < 3 && i < len(subtags) && len(subtags[i]) == 3 && isAlpha(subtags[i]); n++ {
+			out = append(out, strings.ToLower(subtags[i])) // extlang
+			i++
+		}
+		if i < len(subtags) && len(subtags[i]) == 4 && isAlpha(subtags[i]) {
+			st := strings.ToLower(subtags[i])
+			out = append(out, strings.ToUpper(st[:1])+st[1:]) // script
+			i++
+		}
+		if i < len(subtags) && (len(subtags[i]) == 2 && isAlpha(subtags[i]) || len(subtags[i]) == 3 && isDigits(subtags[i])) {
+			out = append(out, strings.ToUpper(subtags[i])) // region
+			i++
+		}
+		for i < len(subtags) && isVariant(subtags[i]) {
+			out = append(out, strings.ToLower(subtags[i]))
+			i++
+		}
+	default:
+		return "", fmt.Errorf("invalid language tag %q: bad language subtag %q", s, subtags[0])
+	}
+
+	for i < len(subtags) {
+		singleton := strings.ToLower(subtags[i])
+		if len(singleton) != 1 {
+			return "", fmt.Errorf("invalid language tag %q: unexpected subtag %q", s, subtags[i])
+		}
+		out = append(out, singleton)
+		i++
+		start := i
+		for i < len(subtags) && (singleton == "x" || len(subtags[i]) > 1) {
+			out = append(out, strings.ToLower(subtags[i]))
+			i++
+		}
+		if i == start {
+			return "", fmt.Errorf("invalid language tag %q: empty %q extension", s, singleton)
+		}
+	}
+	return Locale(strings.Join(out, "-")), nil
+}
+
+// Language returns the primary language subtag, e.g. "pt" for "pt-BR".
+func (l Locale) Language() string {
+	lang, _, _ := strings.Cut(string(l), "-")
+	if lang == "x" {
+		return ""
+	}
+	return lang
+}
+
+// Region returns the region subtag, e.g. "BR" for "pt-BR", or "".
+func (l Locale) Region() string {
+	for _, st := range l.leadingSubtags() {
+		if len(st) == 2 && isAlpha(st) || len(st) == 3 && isDigits(st) {
+			return st
+		}
+	}
+	return ""
+}
+
+// Script returns the script subtag, e.g. "Hant" for "zh-Hant-TW", or "".
+func (l Locale) Script() string {
+	for _, st := range l.leadingSubtags() {
+		if len(st) == 4 && isAlpha(st) {
+			return st
+		}
+	}
+	return ""
+}
+
+// leadingSubtags returns the subtags between the language and the first
+// extension.
+func (l Locale) leadingSubtags() []string {
+	subtags := strings.Split(string(l), "-")
+	for i := 1; i < len(subtags); i++ {
+		if len(subtags[i]) == 1 {
+			return subtags[1:i]
+		}
+	}
+	if len(subtags) == 0 || subtags[0] == "x" {
+		return nil
+	}
+	return subtags[1:]
+}
+
+func setLocale(fv reflect.Value, val any) error {
+	l, err := ParseLocale(fmt.Sprint(val))
+	if err != nil {
+		return err
+	}
+	fv.SetString(string(l))
+	return nil
+}
+
+func isVariant(s string) bool {
+	return len(s) >= 5 && len(s) <= 8 || len(s) == 4 && s[0] >= '0' && s[0] <= '9'
+}
+
+func isAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i] | 0x20
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isAlpha(s[i:i+1]) && !isDigits(s[i:i+1]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+package envx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MarshalJSON encodes cfg as indented JSON keyed by Go field names, as
+// WriteFileAtomic writes it, with secret fields masked by the same rules as
+// Print. The result is safe to attach to bug reports or startup logs.
+func MarshalJSON[T any](cfg *T) ([]byte, error) {
+	m, err := maskedConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return marshalJSON(m)
+}
+
+// MarshalYAML is MarshalJSON in YAML. Keys are sorted and strings are
+// quoted wherever YAML would otherwise read them as another type.
+func MarshalYAML[T any](cfg *T) ([]byte, error) {
+	m, err := maskedConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return marshalYAML(m)
+}
+
+func marshalJSON(m map[string]any) ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func marshalYAML(m map[string]any) ([]byte, error) {
+	// A JSON round trip reduces values such as net.IP or time.Time to the
+	// scalars and collections the YAML writer handles.
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree map[string]any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeYAMLMap(&buf, tree, "")
+	return buf.Bytes(), nil
+}
+
+func maskedConfig[T any](cfg *T) (map[string]any, error) {
+	v := reflect.ValueOf(cfg).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: configuration type must be a struct", ErrUnsupportedType)
+	}
+	return maskSecrets(structToMap(v), v.Type()), nil
+}
+
+func writeYAMLMap(buf *bytes.Buffer, m map[string]any, indent string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		buf.WriteString(indent + yamlString(k) + ":")
+		writeYAMLValue(buf, m[k], indent)
+	}
+}
+
+// writeYAMLValue writes val after a "key:" or "-", in block style for
+// non-empty collections.
+func writeYAMLValue(buf *bytes.Buffer, val any, indent string) {
+	switch val := val.(type) {
+	case map[string]any:
+		if len(val) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeYAMLMap(buf, val, indent+"  ")
+	case []any:
+		if len(val) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteString("\n")
+		for _, item := range val {
+			buf.WriteString(indent + "  -")
+			writeYAMLValue(buf, item, indent+"  ")
+		}
+	case nil:
+		buf.WriteString(" null\n")
+	case bool:
+		buf.WriteString(" " + strconv.FormatBool(val) + "\n")
+	case json.Number:
+		buf.WriteString(" " + val.String() + "\n")
+	case string:
+		buf.WriteString(" " + yamlString(val) + "\n")
+	default:
+		buf.WriteString(" " + yamlString(fmt.Sprint(val)) + "\n")
+	}
+}
+
+// yamlString returns s as a plain scalar when YAML reads it back as the
+// same string, and double-quoted otherwise.
+func yamlString(s string) string {
+	if yamlPlain(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+func yamlPlain(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) || strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f || r == '\u2028' || r == '\u2029' || r == '\ufeff' {
+			return false
+		}
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~", ".nan", ".inf", "-.inf", "+.inf":
+		return false
+	}
+	// Whatever might read as a number, a date or a time, such as "8080",
+	// "1e3", "2024-01-01" or "12:30", stays quoted.
+	return !(s[0] >= '0' && s[0] <= '9' || s[0] == '+' || s[0] == '.')
+}
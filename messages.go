@@ -0,0 +1,151 @@
+package envx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Messages translates the error kinds of load errors, keyed by sentinel
+// (ErrRequired, ErrParse, ...). Details such as the offending value or the
+// strconv error are kept as they are.
+type Messages map[error]string
+
+// catalogs holds the built-in translations, keyed by language subtag.
+var catalogs = map[string]Messages{
+	"de": {
+		ErrRequired:        "Pflichtfeld ist leer",
+		ErrParse:           "ungültiger Wert",
+		ErrValidation:      "Validierung fehlgeschlagen",
+		ErrUnknownKey:      "unbekannter Schlüssel",
+		ErrReference:       "nicht aufgelöste Referenz",
+		ErrLimit:           "Größenlimit überschritten",
+		ErrEncoding:        "nicht unterstützte Kodierung",
+		ErrUnsupportedType: "nicht unterstützter Typ",
+		ErrRemoved:         "Schlüssel aus der Quelle entfernt",
+	},
+	"es": {
+		ErrRequired:        "campo obligatorio vacío",
+		ErrParse:           "valor no válido",
+		ErrValidation:      "validación fallida",
+		ErrUnknownKey:      "clave desconocida",
+		ErrReference:       "referencia no resuelta",
+		ErrLimit:           "límite de tamaño superado",
+		ErrEncoding:        "codificación no admitida",
+		ErrUnsupportedType: "tipo no admitido",
+		ErrRemoved:         "clave eliminada de su origen",
+	},
+	"fr": {
+		ErrRequired:        "champ obligatoire vide",
+		ErrParse:           "valeur invalide",
+		ErrValidation:      "échec de la validation",
+		ErrUnknownKey:      "clé inconnue",
+		ErrReference:       "référence non résolue",
+		ErrLimit:           "limite de taille dépassée",
+		ErrEncoding:        "encodage non pris en charge",
+		ErrUnsupportedType: "type non pris en charge",
+		ErrRemoved:         "clé supprimée de sa source",
+	},
+	"pt": {
+		ErrRequired:        "campo obrigatório não preenchido",
+		ErrParse:           "valor inválido",
+		ErrValidation:      "falha na validação",
+		ErrUnknownKey:      "chave desconhecida",
+		ErrReference:       "referência não resolvida",
+		ErrLimit:           "limite de tamanho excedido",
+		ErrEncoding:        "codificação não suportada",
+		ErrUnsupportedType: "tipo não suportado",
+		ErrRemoved:         "chave removida da origem",
+	},
+}
+
+// messageOrder fixes the order sentinels are translated in, so an error
+// wrapping several kinds renders the same way every time.
+var messageOrder = []error{
+	ErrRequired, ErrParse, ErrValidation, ErrUnknownKey, ErrReference,
+	ErrLimit, ErrEncoding, ErrUnsupportedType, ErrRemoved, ErrPanic, ErrInvalidOptions,
+}
+
+// WithLocale renders load errors in the language of tag, a BCP 47 tag such
+// as "pt-BR". Built-in catalogs cover German, Spanish, French and
+// Portuguese; other languages keep the English messages unless WithMessages
+// supplies them. errors.Is and errors.As match the same sentinels whatever
+// the locale.
+func WithLocale(tag string) Option {
+	return func(o *options) {
+		l, err := ParseLocale(tag)
+		if err != nil {
+			o.errs = append(o.errs, &Error{Field: "WithLocale", Err: fmt.Errorf("%w: %v", ErrInvalidOptions, err)})
+			return
+		}
+		o.locale = l
+	}
+}
+
+// WithMessages adds or replaces translations on top of the WithLocale
+// catalog, e.g. for a language envx does not ship.
+func WithMessages(msgs Messages) Option {
+	return func(o *options) {
+		if o.messages == nil {
+			o.messages = make(Messages, len(msgs))
+		}
+		for k, v := range msgs {
+			o.messages[k] = v
+		}
+	}
+}
+
+// catalog returns the translations in effect, or nil for English.
+func (o *options) catalog() Messages {
+	base := catalogs[o.locale.Language()]
+	if len(o.messages) == 0 {
+		return base
+	}
+	merged := make(Messages, len(base)+len(o.messages))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range o.messages {
+		merged[k] = v
+	}
+	return merged
+}
+
+// localizeError translates the field errors in err, leaving the error
+// chain intact.
+func localizeError(err error, msgs Messages) error {
+	if err == nil || len(msgs) == 0 {
+		return err
+	}
+	switch e := err.(type) {
+	case *MultiError:
+		out := make([]error, len(e.Errors))
+		for i, err := range e.Errors {
+			out[i] = localizeError(err, msgs)
+		}
+		return &MultiError{Errors: out}
+	case *Error:
+		msg := e.Err.Error()
+		translated := false
+		for _, sentinel := range messageOrder {
+			if t, ok := msgs[sentinel]; ok && errors.Is(e.Err, sentinel) {
+				msg = strings.Replace(msg, sentinel.Error(), t, 1)
+				translated = true
+			}
+		}
+		if !translated {
+			return err
+		}
+		return &Error{Field: e.Field, Err: &localizedError{msg: msg, err: e.Err}}
+	}
+	return err
+}
+
+type localizedError struct {
+	msg string
+	err error
+}
+
+func (e *localizedError) Error() string { return e.msg }
+
+func (e *localizedError) Unwrap() error { return e.err }
@@ -0,0 +1,185 @@
+//go:build !envx_minimal && !envx_no_metadata
+
+package envx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+func init() { registerSubsystem("metadata") }
+
+type metadataProvider struct {
+	name     string
+	endpoint string
+	client   *http.Client
+	fetch    func(p *metadataProvider) (map[string]any, error)
+}
+
+// MetadataOption configures a cloud metadata provider.
+type MetadataOption func(*metadataProvider)
+
+// MetadataEndpoint replaces the metadata service's base URL.
+func MetadataEndpoint(endpoint string) MetadataOption {
+	return func(p *metadataProvider) {
+		p.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// MetadataHTTPClient sets the HTTP client used to query the metadata
+// service. The default client times out after two seconds.
+func MetadataHTTPClient(c *http.Client) MetadataOption {
+	return func(p *metadataProvider) {
+		p.client = c
+	}
+}
+
+func newMetadataProvider(name, endpoint string, fetch func(*metadataProvider) (map[string]any, error), opts []MetadataOption) Provider {
+	p := &metadataProvider{
+		name:     name,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 2 * time.Second},
+		fetch:    fetch,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// EC2Metadata returns a provider exposing the EC2 instance identity through
+// IMDSv2 as REGION, AVAILABILITY_ZONE, INSTANCE_ID and INSTANCE_TYPE.
+func EC2Metadata(opts ...MetadataOption) Provider {
+	return newMetadataProvider("ec2", "http://169.254.169.254", fetchEC2, opts)
+}
+
+// GCEMetadata returns a provider exposing the Compute Engine instance
+// identity as PROJECT_ID, REGION, ZONE, INSTANCE_ID and INSTANCE_TYPE.
+func GCEMetadata(opts ...MetadataOption) Provider {
+	return newMetadataProvider("gce", "http://metadata.google.internal", fetchGCE, opts)
+}
+
+// ECSMetadata returns a provider exposing the ECS task identity as CLUSTER,
+// TASK_ARN, TASK_FAMILY, TASK_REVISION, REGION and AVAILABILITY_ZONE. The
+// endpoint defaults to ECS_CONTAINER_METADATA_URI_V4.
+func ECSMetadata(opts ...MetadataOption) Provider {
+	return newMetadataProvider("ecs", os.Getenv("ECS_CONTAINER_METADATA_URI_V4"), fetchECS, opts)
+}
+
+func (p *metadataProvider) Name() string { return p.name }
+
+func (p *metadataProvider) Describe() ProviderInfo {
+	return ProviderInfo{Source: p.endpoint}
+}
+
+func (p *metadataProvider) Values() (map[string]any, error) {
+	if p.endpoint == "" {
+		return nil, fmt.Errorf("envx: %s metadata: no endpoint", p.name)
+	}
+	return p.fetch(p)
+}
+
+func (p *metadataProvider) get(method, path string, header map[string]string) (string, error) {
+	req, err := http.NewRequest(method, p.endpoint+path, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("envx: %s metadata: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("envx: %s metadata %s: unexpected status %s", p.name, path, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("envx: %s metadata: %w", p.name, err)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+func fetchEC2(p *metadataProvider) (map[string]any, error) {
+	token, err := p.get("PUT", "/latest/api/token", map[string]string{"X-aws-ec2-metadata-token-ttl-seconds": "60"})
+	if err != nil {
+		return nil, err
+	}
+	header := map[string]string{"X-aws-ec2-metadata-token": token}
+
+	values := make(map[string]any)
+	for key, path := range map[string]string{
+		"REGION":            "/latest/meta-data/placement/region",
+		"AVAILABILITY_ZONE": "/latest/meta-data/placement/availability-zone",
+		"INSTANCE_ID":       "/latest/meta-data/instance-id",
+		"INSTANCE_TYPE":     "/latest/meta-data/instance-type",
+	} {
+		val, err := p.get("GET", path, header)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = val
+	}
+	return values, nil
+}
+
+func fetchGCE(p *metadataProvider) (map[string]any, error) {
+	header := map[string]string{"Metadata-Flavor": "Google"}
+
+	values := make(map[string]any)
+	for key, path := range map[string]string{
+		"PROJECT_ID":    "/computeMetadata/v1/project/project-id",
+		"ZONE":          "/computeMetadata/v1/instance/zone",
+		"INSTANCE_ID":   "/computeMetadata/v1/instance/id",
+		"INSTANCE_TYPE": "/computeMetadata/v1/instance/machine-type",
+	} {
+		val, err := p.get("GET", path, header)
+		if err != nil {
+			return nil, err
+		}
+		// Zones and machine types come back as resource paths.
+		values[key] = val[strings.LastIndex(val, "/")+1:]
+	}
+	zone := values["ZONE"].(string)
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		values["REGION"] = zone[:i]
+	}
+	return values, nil
+}
+
+func fetchECS(p *metadataProvider) (map[string]any, error) {
+	body, err := p.get("GET", "/task", nil)
+	if err != nil {
+		return nil, err
+	}
+	var task struct {
+		Cluster          string
+		TaskARN          string
+		Family           string
+		Revision         string
+		AvailabilityZone string
+	}
+	if err := json.Unmarshal([]byte(body), &task); err != nil {
+		return nil, fmt.Errorf("envx: ecs metadata: %w", err)
+	}
+
+	values := map[string]any{
+		"CLUSTER":           task.Cluster,
+		"TASK_ARN":          task.TaskARN,
+		"TASK_FAMILY":       task.Family,
+		"TASK_REVISION":     task.Revision,
+		"AVAILABILITY_ZONE": task.AvailabilityZone,
+	}
+	// arn:aws:ecs:<region>:<account>:task/...
+	if parts := strings.SplitN(task.TaskARN, ":", 5); len(parts) == 5 {
+		values["REGION"] = parts[3]
+	}
+	return values, nil
+}
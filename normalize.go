@@ -0,0 +1,66 @@
+package envx
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	normalizersMu sync.RWMutex
+	normalizers   = map[string]func(string) string{
+		"trim":  strings.TrimSpace,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}
+)
+
+// RegisterNormalizer makes fn available to the normalize tag under name,
+// replacing any normalizer already registered with that name.
+func RegisterNormalizer(name string, fn func(string) string) {
+	normalizersMu.Lock()
+	defer normalizersMu.Unlock()
+	normalizers[name] = fn
+}
+
+// normalizeValue applies the comma-separated normalizers of a normalize tag,
+// in order, to string values and to the string items of list values.
+func normalizeValue(tag string, val any) (any, error) {
+	if tag == "" {
+		return val, nil
+	}
+
+	var fns []func(string) string
+	normalizersMu.RLock()
+	for _, name := range splitTagList(tag) {
+		fn, ok := normalizers[name]
+		if !ok {
+			normalizersMu.RUnlock()
+			return nil, fmt.Errorf("unknown normalizer %q", name)
+		}
+		fns = append(fns, fn)
+	}
+	normalizersMu.RUnlock()
+
+	apply := func(s string) string {
+		for _, fn := range fns {
+			s = fn(s)
+		}
+		return s
+	}
+
+	switch v := val.(type) {
+	case string:
+		return apply(v), nil
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			if s, ok := item.(string); ok {
+				item = apply(s)
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return val, nil
+}
@@ -0,0 +1,338 @@
+//go:build !envx_minimal && !envx_no_oci
+
+package envx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+func init() { registerSubsystem("oci") }
+
+const (
+	ociManifestType = "application/vnd.oci.image.manifest.v1+json"
+	ociTitleKey     = "org.opencontainers.image.title"
+
+	// ociMaxManifestSize is the largest manifest read, the size the
+	// distribution spec asks registries to accept.
+	ociMaxManifestSize = 4 << 20
+	// ociMaxLayerSize caps the config file layer, which is read into
+	// memory whole.
+	ociMaxLayerSize = 16 << 20
+	// ociMaxTokenSize caps a token endpoint's response.
+	ociMaxTokenSize = 1 << 20
+)
+
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type ociProvider struct {
+	scheme   string
+	registry string
+	repo     string
+	ref      string
+	file     string
+	client   *http.Client
+	username string
+	password string
+
+	mu     sync.Mutex
+	token  string
+	digest string
+}
+
+// OCIOption configures an OCI provider.
+type OCIOption func(*ociProvider)
+
+// OCIBasicAuth sets registry credentials, used directly or to obtain a
+// bearer token.
+func OCIBasicAuth(username, password string) OCIOption {
+	return func(p *ociProvider) {
+		p.username = username
+		p.password = password
+	}
+}
+
+// OCIHTTPClient sets the HTTP client used to talk to the registry. The
+// default client times out after 30 seconds.
+func OCIHTTPClient(c *http.Client) OCIOption {
+	return func(p *ociProvider) {
+		p.client = c
+	}
+}
+
+// OCIPlainHTTP talks to the registry over plain HTTP, for local registries.
+func OCIPlainHTTP() OCIOption {
+	return func(p *ociProvider) {
+		p.scheme = "http"
+	}
+}
+
+// OCIFile selects the artifact layer by its file name (the
+// org.opencontainers.image.title annotation set by oras push). By default
+// the first layer is used.
+func OCIFile(name string) OCIOption {
+	return func(p *ociProvider) {
+		p.file = name
+	}
+}
+
+// OCI returns a provider that pulls a config artifact from an OCI registry,
+// as pushed by oras, e.g. "registry.example.com/team/app-config:prod" or
+// "...@sha256:<digest>". The manifest is checked against a digest
+// reference and the layer against its descriptor digest before decoding it
+// by file name (JSON, .env or .properties; JSON without a name). The provider
+// implements ChangeDetector for tag references.
+func OCI(reference string, opts ...OCIOption) Provider {
+	p := &ociProvider{scheme: "https", client: &http.Client{Timeout: remoteTimeout}}
+	p.registry, p.repo, p.ref = splitOCIReference(reference)
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func splitOCIReference(ref string) (registry, repo, tag string) {
+	registry, rest, _ := strings.Cut(ref, "/")
+	if i := strings.Index(rest, "@"); i >= 0 {
+		return registry, rest[:i], rest[i+1:]
+	}
+	if i := strings.LastIndex(rest, ":"); i >= 0 {
+		return registry, rest[:i], rest[i+1:]
+	}
+	return registry, rest, "latest"
+}
+
+func (p *ociProvider) Name() string { return "oci" }
+
+func (p *ociProvider) StrictKeys() bool { return true }
+
+func (p *ociProvider) Describe() ProviderInfo {
+	sep := ":"
+	if strings.HasPrefix(p.ref, "sha256:") {
+		sep = "@"
+	}
+	return ProviderInfo{Source: p.registry + "/" + p.repo + sep + p.ref}
+}
+
+func (p *ociProvider) Values() (map[string]any, error) {
+	return p.ValuesContext(context.Background())
+}
+
+func (p *ociProvider) ValuesContext(ctx context.Context) (map[string]any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.registry == "" || p.repo == "" {
+		return nil, p.errorf("invalid reference")
+	}
+
+	body, digest, err := p.fetch(ctx, "GET", "manifests/"+p.ref, ociManifestType, ociMaxManifestSize)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(p.ref, ":") && digest != p.ref {
+		return nil, p.errorf("manifest digest %s does not match %s", digest, p.ref)
+	}
+
+	var manifest ociManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, p.errorf("invalid manifest: %v", err)
+	}
+	layer, err := p.pickLayer(manifest.Layers)
+	if err != nil {
+		return nil, err
+	}
+
+	if layer.Size > ociMaxLayerSize {
+		return nil, p.errorf("layer %s is %d bytes, limit is %d", layer.Digest, layer.Size, ociMaxLayerSize)
+	}
+	data, blobDigest, err := p.fetch(ctx, "GET", "blobs/"+layer.Digest, "", layer.Size)
+	if err != nil {
+		return nil, err
+	}
+	if blobDigest != layer.Digest || int64(len(data)) != layer.Size {
+		return nil, p.errorf("layer %s failed verification", layer.Digest)
+	}
+
+	name := layer.Annotations[ociTitleKey]
+	if err := checkEncoding(data, strings.HasSuffix(name, ".properties")); err != nil {
+		return nil, &Error{Field: p.registry + "/" + p.repo, Err: err}
+	}
+	data, _ = normalizeText(data)
+	values, err := decodeFile(data, strings.ToLower(filepath.Ext(name)))
+	if err != nil {
+		return nil, err
+	}
+	p.digest = digest
+	return values, nil
+}
+
+// Changed reports whether the tag now points to a different manifest.
+func (p *ociProvider) Changed() (bool, error) {
+	return p.ChangedContext(context.Background())
+}
+
+func (p *ociProvider) ChangedContext(ctx context.Context) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.digest == "" || p.digest == p.ref {
+		return false, nil
+	}
+	_, digest, err := p.fetch(ctx, "HEAD", "manifests/"+p.ref, ociManifestType, 0)
+	if err != nil {
+		return false, err
+	}
+	if digest == "" {
+		// Registries need not report the digest on HEAD; hash the manifest
+		// as Values does instead.
+		if _, digest, err = p.fetch(ctx, "GET", "manifests/"+p.ref, ociManifestType, ociMaxManifestSize); err != nil {
+			return false, err
+		}
+	}
+	return digest != p.digest, nil
+}
+
+func (p *ociProvider) pickLayer(layers []ociDescriptor) (ociDescriptor, error) {
+	for _, l := range layers {
+		if p.file == "" || l.Annotations[ociTitleKey] == p.file {
+			return l, nil
+		}
+	}
+	if p.file != "" {
+		return ociDescriptor{}, p.errorf("no layer named %q", p.file)
+	}
+	return ociDescriptor{}, p.errorf("artifact has no layers")
+}
+
+// fetch performs a registry request, authenticating on demand, and returns
+// the body with its sha256 digest, or the registry-reported digest for HEAD.
+// A body longer than limit bytes is an error.
+func (p *ociProvider) fetch(ctx context.Context, method, path, accept string, limit int64) ([]byte, string, error) {
+	u := fmt.Sprintf("%s://%s/v2/%s/%s", p.scheme, p.registry, p.repo, path)
+
+	resp, err := p.do(ctx, method, u, accept)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := p.authenticate(ctx, challenge); err != nil {
+			return nil, "", err
+		}
+		if resp, err = p.do(ctx, method, u, accept); err != nil {
+			return nil, "", err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", p.errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if method == "HEAD" {
+		return nil, resp.Header.Get("Docker-Content-Digest"), nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(body)) > limit {
+		return nil, "", p.errorf("%s %s: response exceeds %d bytes", method, path, limit)
+	}
+	sum := sha256.Sum256(body)
+	return body, "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+func (p *ociProvider) do(ctx context.Context, method, u, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	switch {
+	case p.token != "":
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	case p.username != "":
+		req.SetBasicAuth(p.username, p.password)
+	}
+	return p.client.Do(req)
+}
+
+// authenticate obtains a bearer token as described by a registry's
+// WWW-Authenticate challenge.
+func (p *ociProvider) authenticate(ctx context.Context, challenge string) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return p.errorf("unauthorized")
+	}
+
+	attrs := make(map[string]string)
+	for _, part := range strings.Split(params, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		attrs[k] = strings.Trim(v, `"`)
+	}
+	if attrs["realm"] == "" {
+		return p.errorf("unauthorized: no token realm")
+	}
+
+	q := url.Values{}
+	for _, k := range []string{"service", "scope"} {
+		if attrs[k] != "" {
+			q.Set(k, attrs[k])
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", attrs["realm"]+"?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if p.username != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return p.errorf("token request: %s", resp.Status)
+	}
+
+	var tok struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, ociMaxTokenSize)).Decode(&tok); err != nil {
+		return p.errorf("token response: %v", err)
+	}
+	p.token = tok.Token
+	if p.token == "" {
+		p.token = tok.AccessToken
+	}
+	return nil
+}
+
+func (p *ociProvider) errorf(format string, args ...any) error {
+	return fmt.Errorf("envx: oci %s/%s: %s", p.registry, p.repo, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,65 @@
+//go:build !envx_minimal && !envx_no_opa
+
+package envx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func init() { registerSubsystem("opa") }
+
+type opaPolicy struct {
+	url    string
+	client *http.Client
+}
+
+// OPA returns a Policy that queries an Open Policy Agent server, typically a
+// sidecar serving the organization's bundle, through its data API:
+// POST {endpoint}/v1/data/{path} with the input. The document at path must
+// be a set or array of denial messages, e.g. "envx/deny" for
+//
+//	package envx
+//	deny contains msg if {
+//	    input.profile == "prod"
+//	    input.config.Debug
+//	    msg := "debug must be off in prod"
+//	}
+func OPA(endpoint, path string) Policy {
+	return &opaPolicy{
+		url:    strings.TrimSuffix(endpoint, "/") + "/v1/data/" + strings.Trim(path, "/"),
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (p *opaPolicy) Evaluate(input map[string]any) ([]string, error) {
+	body, err := json.Marshal(map[string]any{"input": input})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Post(p.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("envx: opa: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("envx: opa %s: unexpected status %s", p.url, resp.Status)
+	}
+
+	var out struct {
+		Result *[]string `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("envx: opa %s: %w", p.url, err)
+	}
+	// An undefined document means the policy is not loaded; failing closed
+	// beats silently allowing everything.
+	if out.Result == nil {
+		return nil, fmt.Errorf("envx: opa %s: policy not found", p.url)
+	}
+	return *out.Result, nil
+}
@@ -1,25 +1,101 @@
 package envx
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 )
 
 type Option func(*options)
 
+// Layer declares where a provider sits in the precedence order. Providers in
+// higher layers override lower ones; within a layer registration order wins.
+type Layer int
+
+const (
+	LayerDefaults Layer = iota
+	LayerFile
+	LayerEnv
+	LayerOverride
+)
+
 type options struct {
-	providers     []Provider
-	prefix        string
-	logger        Logger
-	onReload      func(any, any)
-	onReloadError func(error)
-	validator     func(any) error
-	watchPath     string
-	watchEvery    time.Duration
+	providers         []Provider
+	prefix            string
+	logger            Logger
+	onReload          func(any, any)
+	onReloadType      reflect.Type
+	fieldChanges      []fieldChange
+	eventLogSize      int
+	reloadDebounce    time.Duration
+	summaryLogger     Logger
+	summarize         bool
+	watchContent      bool
+	ignoreEnvSettings bool
+	locale            Locale
+	messages          Messages
+	onReloadError     func(error)
+	validator         func(any) error
+	validatorType     reflect.Type
+	watches           []fileWatch
+	precedence        []string
+	reloadLimit       int
+	reloadPer         time.Duration
+	overridesPath     string
+	profile           string
+	devFill           *devPlaceholders
+	errs              []error
+
+	resolveMode    ResolveMode
+	resolveTimeout time.Duration
+
+	maxValue int
+	maxTotal int64
+
+	warnNormalized bool
+
+	watchProviders []providerWatch
+	reloadSignals  []os.Signal
+
+	pass *fetchPass
+
+	fetchTimeout time.Duration
+	ctx          context.Context
+
+	removals *removalState
+
+	reloadWindow *reloadWindow
+
+	approval bool
+
+	policies []Policy
+
+	schema *jsonSchema
+
+	strict       bool
+	onUnknownKey func(key, source string)
+
+	fileSecrets bool
+
+	resolvers map[string]Resolver
+}
+
+type fileWatch struct {
+	path     string
+	interval time.Duration
+}
+
+type providerWatch struct {
+	provider Provider
+	detector ChangeDetector
+	notifier ChangeNotifier
+	interval time.Duration
 }
 
 func WithProvider(p Provider) Option {
@@ -28,6 +104,14 @@ func WithProvider(p Provider) Option {
 	}
 }
 
+// WithLayer registers p in the given layer. Providers added with WithProvider
+// belong to LayerFile.
+func WithLayer(p Provider, layer Layer) Option {
+	return func(o *options) {
+		o.providers = append(o.providers, &layeredProvider{Provider: p, layer: layer})
+	}
+}
+
 func WithPrefix(prefix string) Option {
 	return func(o *options) {
 		o.prefix = strings.ToUpper(prefix)
@@ -48,6 +132,7 @@ func WithLogger(logger Logger) Option {
 
 func WithOnReload[T any](fn func(old *T, new *T)) Option {
 	return func(o *options) {
+		o.onReloadType = reflect.TypeOf((*T)(nil)).Elem()
 		o.onReload = func(old any, new any) {
 			oCfg, ok1 := old.(*T)
 			nCfg, ok2 := new.(*T)
@@ -66,6 +151,7 @@ func WithOnReloadError(fn func(error)) Option {
 
 func WithValidator[T any](fn func(*T) error) Option {
 	return func(o *options) {
+		o.validatorType = reflect.TypeOf((*T)(nil)).Elem()
 		o.validator = func(cfg any) error {
 			c, ok := cfg.(*T)
 			if !ok {
@@ -76,15 +162,336 @@ func WithValidator[T any](fn func(*T) error) Option {
 	}
 }
 
+// WithWatch polls path every interval once StartWatching is called,
+// reloading when it changes. Each watched path has its own interval; watching
+// the same path again replaces its interval.
 func WithWatch(path string, interval time.Duration) Option {
 	return func(o *options) {
-		o.watchPath, _ = filepath.Abs(path)
-		o.watchEvery = interval
+		abs, _ := filepath.Abs(path)
+		for i, w := range o.watches {
+			if w.path == abs {
+				o.watches[i].interval = interval
+				return
+			}
+		}
+		o.watches = append(o.watches, fileWatch{path: abs, interval: interval})
+	}
+}
+
+// WithReloadSignal reloads the configuration whenever the process receives
+// one of sigs, typically syscall.SIGHUP, from StartWatching until
+// StopWatching. It needs no file or provider watch.
+func WithReloadSignal(sigs ...os.Signal) Option {
+	return func(o *options) {
+		o.reloadSignals = append(o.reloadSignals, sigs...)
+	}
+}
+
+// WithWatchProvider registers p like WithProvider and, once StartWatching
+// is called, polls it every interval, reloading when it reports a change.
+// p must implement ChangeDetector or ChangeNotifier; a ChangeNotifier is
+// not polled but followed, and interval is the delay before reconnecting
+// after its event stream breaks.
+func WithWatchProvider(p Provider, interval time.Duration) Option {
+	return func(o *options) {
+		o.providers = append(o.providers, p)
+		w := providerWatch{provider: p, interval: interval}
+		if cn, ok := providerAs[ChangeNotifier](p); ok {
+			w.notifier = cn
+		} else if cd, ok := providerAs[ChangeDetector](p); ok {
+			w.detector = cd
+		} else {
+			o.errs = append(o.errs, &Error{Field: "WithWatchProvider", Err: fmt.Errorf("%w: %s provider cannot detect changes", ErrInvalidOptions, providerName(p))})
+			return
+		}
+		o.watchProviders = append(o.watchProviders, w)
+	}
+}
+
+// WithPrecedence reorders the registered providers by name, from lowest to
+// highest precedence. Providers not listed keep their relative order and sit
+// below the listed ones.
+func WithPrecedence(names ...string) Option {
+	return func(o *options) {
+		o.precedence = names
+	}
+}
+
+// WithContentWatch makes WithWatch compare a SHA-256 of each file's contents
+// instead of its modification time. A touch, an rsync that preserves
+// content, or a ConfigMap symlink swap to an older file then neither
+// triggers a spurious reload nor hides a real change, at the cost of
+// reading the file on every poll.
+func WithContentWatch() Option {
+	return func(o *options) {
+		o.watchContent = true
+	}
+}
+
+// WithMaxReloadRate applies at most n reloads per period. Changes detected
+// while the limit is reached are coalesced into a single reload once the
+// window frees up, so the loader always converges to the latest values.
+func WithMaxReloadRate(n int, per time.Duration) Option {
+	return func(o *options) {
+		o.reloadLimit = n
+		o.reloadPer = per
+	}
+}
+
+// WithReloadDebounce waits until watched sources have been quiet for d
+// before reloading, so a burst of writes, e.g. an editor saving a file in
+// several steps, triggers one reload instead of one per write. Each change
+// detected within d of the previous one restarts the wait. Reload signals
+// are not debounced.
+func WithReloadDebounce(d time.Duration) Option {
+	return func(o *options) {
+		o.reloadDebounce = d
+	}
+}
+
+// WithProfile sets the active profile (e.g. "local", "staging", "prod"),
+// enabling profile-scoped tags such as required:"prod".
+func WithProfile(profile string) Option {
+	return func(o *options) {
+		o.profile = profile
+	}
+}
+
+// WithSetFlags parses KEY=VALUE strings, as collected from a repeated --set
+// flag, into an override-layer provider. Keys are full variable names,
+// including any prefix. Malformed entries make loading fail.
+func WithSetFlags(flags []string) Option {
+	return func(o *options) {
+		values := make(map[string]string, len(flags))
+		for _, flag := range flags {
+			key, val, ok := strings.Cut(flag, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				o.errs = append(o.errs, &Error{Field: "set", Err: fmt.Errorf("%w: invalid override %q, want KEY=VALUE", ErrParse, flag)})
+				continue
+			}
+			values[key] = val
+		}
+		o.providers = append(o.providers, &layeredProvider{Provider: &setProvider{values: values}, layer: LayerOverride})
 	}
 }
 
+type setProvider struct {
+	values map[string]string
+}
+
+func (p *setProvider) Name() string { return "set" }
+
+func (p *setProvider) PrefixAware() bool { return true }
+
+func (p *setProvider) Values() (map[string]any, error) {
+	values := make(map[string]any, len(p.values))
+	for k, v := range p.values {
+		values[k] = v
+	}
+	return values, nil
+}
+
 func defaultOptions() *options {
 	return &options{
-		logger: newWriterLogger(os.Stdout),
+		logger:       newWriterLogger(os.Stdout),
+		eventLogSize: defaultEventLogSize,
+	}
+}
+
+// Options is a declarative alternative to functional options, for settings
+// built from data or serialized. Zero fields leave the setting untouched.
+// It covers a subset of the options: those taking callbacks, policies,
+// resolvers or other values that are not plain data, such as WithValidator,
+// WithOnReload, WithPolicy or WithWatchProvider, are passed alongside
+// Option's result.
+type Options struct {
+	Providers      []Provider    `json:"-"`
+	Prefix         string        `json:"prefix,omitempty"`
+	Profile        string        `json:"profile,omitempty"`
+	WatchPath      string        `json:"watchPath,omitempty"`
+	WatchPaths     []string      `json:"watchPaths,omitempty"`
+	WatchInterval  time.Duration `json:"watchInterval,omitempty"`
+	WatchContent   bool          `json:"watchContent,omitempty"`
+	Precedence     []string      `json:"precedence,omitempty"`
+	MaxReloads     int           `json:"maxReloads,omitempty"`
+	ReloadPer      time.Duration `json:"reloadPer,omitempty"`
+	ReloadDebounce time.Duration `json:"reloadDebounce,omitempty"`
+	ReloadWindow   string        `json:"reloadWindow,omitempty"`
+	// ReloadLocation is the time zone of ReloadWindow, time.Local if nil.
+	ReloadLocation *time.Location  `json:"-"`
+	Approval       bool            `json:"approval,omitempty"`
+	FetchTimeout   time.Duration   `json:"fetchTimeout,omitempty"`
+	MaxValueSize   int             `json:"maxValueSize,omitempty"`
+	MaxTotalSize   int64           `json:"maxTotalSize,omitempty"`
+	Schema         json.RawMessage `json:"schema,omitempty"`
+	Strict         bool            `json:"strict,omitempty"`
+	FileSecrets    bool            `json:"fileSecrets,omitempty"`
+	OverridesFile  string          `json:"overridesFile,omitempty"`
+	DevFill        bool            `json:"devFill,omitempty"`
+	Set            []string        `json:"set,omitempty"`
+	Locale         string          `json:"locale,omitempty"`
+	Logger         Logger          `json:"-"`
+	OnReloadError  func(error)     `json:"-"`
+}
+
+// Option converts opts into a single functional option, so both styles can
+// be mixed.
+func (opts Options) Option() Option {
+	var list []Option
+	for _, p := range opts.Providers {
+		list = append(list, WithProvider(p))
+	}
+	if opts.Prefix != "" {
+		list = append(list, WithPrefix(opts.Prefix))
+	}
+	if opts.Profile != "" {
+		list = append(list, WithProfile(opts.Profile))
+	}
+	if opts.WatchPath != "" {
+		list = append(list, WithWatch(opts.WatchPath, opts.WatchInterval))
+	}
+	for _, path := range opts.WatchPaths {
+		list = append(list, WithWatch(path, opts.WatchInterval))
+	}
+	if opts.WatchContent {
+		list = append(list, WithContentWatch())
+	}
+	if len(opts.Precedence) > 0 {
+		list = append(list, WithPrecedence(opts.Precedence...))
+	}
+	if opts.MaxReloads > 0 {
+		list = append(list, WithMaxReloadRate(opts.MaxReloads, opts.ReloadPer))
+	}
+	if opts.ReloadDebounce > 0 {
+		list = append(list, WithReloadDebounce(opts.ReloadDebounce))
+	}
+	if opts.ReloadWindow != "" {
+		list = append(list, WithReloadWindow(opts.ReloadWindow, opts.ReloadLocation))
+	}
+	if opts.Approval {
+		list = append(list, WithApproval())
+	}
+	if opts.FetchTimeout > 0 {
+		list = append(list, WithFetchTimeout(opts.FetchTimeout))
+	}
+	if opts.MaxValueSize > 0 || opts.MaxTotalSize > 0 {
+		list = append(list, WithSizeLimits(opts.MaxValueSize, opts.MaxTotalSize))
+	}
+	if len(opts.Schema) > 0 {
+		list = append(list, WithSchemaValidation(opts.Schema))
+	}
+	if opts.Strict {
+		list = append(list, WithStrict())
+	}
+	if opts.FileSecrets {
+		list = append(list, WithFileSecrets())
+	}
+	if opts.OverridesFile != "" {
+		list = append(list, WithOverridesFile(opts.OverridesFile))
+	}
+	if opts.DevFill {
+		list = append(list, WithDevFill())
+	}
+	if len(opts.Set) > 0 {
+		list = append(list, WithSetFlags(opts.Set))
+	}
+	if opts.Locale != "" {
+		list = append(list, WithLocale(opts.Locale))
+	}
+	if opts.Logger != nil {
+		list = append(list, WithLogger(opts.Logger))
+	}
+	if opts.OnReloadError != nil {
+		list = append(list, WithOnReloadError(opts.OnReloadError))
+	}
+
+	return func(o *options) {
+		for _, opt := range list {
+			opt(o)
+		}
+	}
+}
+
+// OptionFor is an Option bound to the config type T. Typed entry points such
+// as LoadTyped only accept options for their own T, so a callback written
+// for another config type fails to compile instead of never firing. It wraps
+// the option in a struct tagged with T, so neither an Option nor an
+// OptionFor for another type converts to it.
+type OptionFor[T any] struct {
+	_     [0]*T
+	apply Option
+}
+
+// For binds untyped options, which do not depend on the config type, to T.
+// Since the compiler cannot check what an Option wraps, a callback among
+// opts written for another type, e.g. WithOnReload[Other], fails the load
+// with ErrInvalidOptions.
+func For[T any](opts ...Option) OptionFor[T] {
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	return OptionFor[T]{apply: func(o *options) {
+		onReload, validator, fieldChanges := o.onReloadType, o.validatorType, len(o.fieldChanges)
+		o.onReloadType, o.validatorType = nil, nil
+		for _, opt := range opts {
+			opt(o)
+		}
+
+		mismatch := func(option string, typ reflect.Type) {
+			o.errs = append(o.errs, &Error{Field: option, Err: fmt.Errorf("%w: For[%s] given a callback for %s", ErrInvalidOptions, target, typ)})
+		}
+		switch o.onReloadType {
+		case nil:
+			o.onReloadType = onReload
+		case target:
+		default:
+			mismatch("WithOnReload", o.onReloadType)
+		}
+		switch o.validatorType {
+		case nil:
+			o.validatorType = validator
+		case target:
+		default:
+			mismatch("WithValidator", o.validatorType)
+		}
+		for _, fc := range o.fieldChanges[fieldChanges:] {
+			if fc.typ != target {
+				mismatch("WithOnFieldChange", fc.typ)
+			}
+		}
+	}}
+}
+
+// OnReloadFor is the typed form of WithOnReload.
+func OnReloadFor[T any](fn func(old *T, new *T)) OptionFor[T] {
+	return OptionFor[T]{apply: WithOnReload(fn)}
+}
+
+// ValidatorFor is the typed form of WithValidator.
+func ValidatorFor[T any](fn func(*T) error) OptionFor[T] {
+	return OptionFor[T]{apply: WithValidator(fn)}
+}
+
+func untyped[T any](opts []OptionFor[T]) []Option {
+	out := make([]Option, 0, len(opts))
+	for _, opt := range opts {
+		if opt.apply != nil {
+			out = append(out, opt.apply)
+		}
+	}
+	return out
+}
+
+// normalizingProvider is implemented by providers that clean up their input
+// and can report what they changed.
+type normalizingProvider interface {
+	normalizedValues() (map[string]any, []string, error)
+}
+
+// WithNormalizationWarnings logs a warning whenever a file had to be
+// normalized to load, e.g. a UTF-8 byte order mark was stripped or CRLF line
+// endings were converted.
+func WithNormalizationWarnings() Option {
+	return func(o *options) {
+		o.warnNormalized = true
 	}
 }
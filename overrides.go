@@ -0,0 +1,215 @@
+package envx
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const overridesProviderName = "override"
+
+type overridesProvider struct {
+	values map[string]string
+}
+
+func (p *overridesProvider) PrefixAware() bool { return true }
+
+func (p *overridesProvider) Name() string { return overridesProviderName }
+
+func (p *overridesProvider) Values() (map[string]any, error) {
+	values := make(map[string]any, len(p.values))
+	for k, v := range p.values {
+		values[k] = v
+	}
+	return values, nil
+}
+
+// WithOverridesFile persists values set through Loader.Override to path as
+// a flat JSON object and restores them on the next load. A new file is
+// created readable by its owner only, as overrides may hold secrets.
+func WithOverridesFile(path string) Option {
+	return func(o *options) {
+		o.overridesPath = path
+	}
+}
+
+// Override sets key (the full variable name, including any prefix) above
+// every other provider and reloads. The override is discarded if the
+// resulting configuration fails to load.
+func (l *Loader[T]) Override(key, value string) error {
+	return l.override("", key, value)
+}
+
+// override is Override on behalf of actor, who is named in the event log.
+func (l *Loader[T]) override(actor, key, value string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	o := prepareOptions[T](l.opts)
+	if err := l.ensureOverrides(o); err != nil {
+		return err
+	}
+
+	prev, had := l.overrides[key]
+	l.overrides[key] = value
+	if err := l.applyOverrides(o, actor); err != nil {
+		if had {
+			l.overrides[key] = prev
+		} else {
+			delete(l.overrides, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// Overrides returns a copy of the active runtime overrides.
+func (l *Loader[T]) Overrides() map[string]string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	out := make(map[string]string, len(l.overrides))
+	for k, v := range l.overrides {
+		out[k] = v
+	}
+	return out
+}
+
+// ClearOverrides drops every runtime override, removes the overrides file
+// if one is configured, and reloads.
+func (l *Loader[T]) ClearOverrides() error {
+	return l.clearOverrides("")
+}
+
+func (l *Loader[T]) clearOverrides(actor string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	o := prepareOptions[T](l.opts)
+	l.overrides = make(map[string]string)
+	l.overridesLoaded = true
+
+	if o.overridesPath != "" {
+		if err := os.Remove(o.overridesPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return l.applyOverrides(o, actor)
+}
+
+func (l *Loader[T]) ensureOverrides(o *options) error {
+	if l.overridesLoaded {
+		return nil
+	}
+
+	l.overrides = make(map[string]string)
+	if o.overridesPath != "" {
+		data, err := os.ReadFile(o.overridesPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &l.overrides); err != nil {
+				return &Error{Field: "overrides", Err: fmt.Errorf("%w: %v", ErrParse, err)}
+			}
+		}
+	}
+	l.overridesLoaded = true
+	return nil
+}
+
+func (l *Loader[T]) applyOverrides(o *options, actor string) (err error) {
+	defer func() {
+		if err != nil {
+			err = redactValues(err, l.secretOverrides())
+			l.appendEvent(Event{Kind: EventOverride, Err: err, Actor: actor})
+		}
+	}()
+
+	oldConfig := l.config
+	opts := l.loadOptions()
+	opts = append(opts[:len(opts):len(opts)], withRemovals(l.removalState))
+	values, newConfig, err := loadCached[T](&l.cache, false, opts...)
+	if err != nil {
+		return err
+	}
+
+	if o.overridesPath != "" && len(l.overrides) > 0 {
+		data, err := json.MarshalIndent(l.overrides, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(o.overridesPath, append(data, '\n'), 0600); err != nil {
+			return err
+		}
+	}
+
+	l.sources = l.captureSources()
+	l.removalState = l.removals(values)
+	if reflect.DeepEqual(oldConfig, newConfig) {
+		l.appendEvent(Event{Kind: EventOverride, Actor: actor})
+		return nil
+	}
+
+	l.config = newConfig
+	l.version++
+	l.markReady()
+	l.appendEvent(Event{Kind: EventOverride, Changes: diffConfigs(oldConfig, newConfig), Actor: actor})
+	if oldConfig != nil {
+		l.triggerOnReload(o, oldConfig, newConfig)
+	}
+	return nil
+}
+
+// secretOverrides returns the override values set on secret fields, which
+// a rejected override must not echo into the event log or the admin page.
+// The caller holds l.mu.
+func (l *Loader[T]) secretOverrides() []string {
+	var values []string
+	for _, d := range Docs[T](l.opts...) {
+		if v := l.overrides[d.Key]; d.Secret && v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// loadOptions returns the loader options plus the runtime overrides layer.
+func (l *Loader[T]) loadOptions() []Option {
+	if len(l.overrides) == 0 {
+		return l.opts
+	}
+
+	values := make(map[string]string, len(l.overrides))
+	for k, v := range l.overrides {
+		values[k] = v
+	}
+	opts := make([]Option, 0, len(l.opts)+1)
+	opts = append(opts, l.opts...)
+	return append(opts, WithLayer(&overridesProvider{values: values}, LayerOverride))
+}
+
+// redactedError replaces secret values in the message of err, keeping err
+// for errors.Is and errors.As.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string { return e.msg }
+
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactValues masks every occurrence of values in the message of err.
+func redactValues(err error, values []string) error {
+	msg := err.Error()
+	for _, v := range values {
+		msg = strings.ReplaceAll(msg, v, "***")
+	}
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{err: err, msg: msg}
+}
@@ -0,0 +1,15 @@
+package envx
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// recoverPanic, deferred around a user callback, turns a panic into an
+// ErrPanic error for the named callback, carrying the panic value and the
+// stack. It leaves *errp alone when nothing panicked.
+func recoverPanic(callback string, errp *error) {
+	if r := recover(); r != nil {
+		*errp = &Error{Field: callback, Err: fmt.Errorf("%w: %v\n%s", ErrPanic, r, debug.Stack())}
+	}
+}
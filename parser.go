@@ -1,6 +1,7 @@
 package envx
 
 import (
+	"encoding"
 	"encoding/csv"
 	"fmt"
 	"reflect"
@@ -24,6 +25,7 @@ func parse(cfg any, values map[string]any, prefix string) error {
 }
 
 func parseStruct(v reflect.Value, t reflect.Type, path string, values map[string]any, prefix string) error {
+	var errs []error
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		fv := v.Field(i)
@@ -32,15 +34,28 @@ func parseStruct(v reflect.Value, t reflect.Type, path string, values map[string
 			continue
 		}
 
-		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
-			nestedPath := path + toScreamingSnake(field.Name) + "_"
-			if err := parseStruct(fv, field.Type, nestedPath, values, prefix); err != nil {
-				return err
+		if isSection(field.Type) {
+			nestedPath := path + fieldName(field) + "_"
+			errs = appendErrors(errs, parseStruct(fv, field.Type, nestedPath, values, prefix))
+			continue
+		}
+
+		if isOptionalSection(field.Type) {
+			nestedPath := path + fieldName(field) + "_"
+			if !sectionPresent(values, prefix, field.Type.Elem(), nestedPath) {
+				continue
 			}
+			section := reflect.New(field.Type.Elem())
+			if err := applyTagDefaults(section.Elem(), field.Type.Elem(), nestedPath); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			errs = appendErrors(errs, parseStruct(section.Elem(), field.Type.Elem(), nestedPath, values, prefix))
+			fv.Set(section)
 			continue
 		}
 
-		key := path + toScreamingSnake(field.Name)
+		key := path + fieldName(field)
 		if prefix != "" {
 			key = prefix + "_" + key
 		}
@@ -50,37 +65,239 @@ func parseStruct(v reflect.Value, t reflect.Type, path string, values map[string
 			continue
 		}
 
+		if tag := field.Tag.Get("normalize"); tag != "" {
+			if fv.Kind() == reflect.Slice {
+				if items, err := normalizeSliceInput(val); err == nil {
+					val = items
+				}
+			}
+			normalized, err := normalizeValue(tag, val)
+			if err != nil {
+				errs = append(errs, &Error{Field: key, Err: fmt.Errorf("%w: %v", ErrParse, err)})
+				continue
+			}
+			val = normalized
+		}
+
 		if err := setField(fv, val); err != nil {
-			return &Error{Field: key, Err: fmt.Errorf("%w: %v", ErrParse, err)}
+			errs = append(errs, &Error{Field: key, Err: fmt.Errorf("%w: %v", ErrParse, err)})
+			continue
+		}
+
+		if err := checkSRVNames(field, fv); err != nil {
+			errs = append(errs, &Error{Field: key, Err: err})
+			continue
+		}
+
+		if err := checkSchemes(field, fv); err != nil {
+			errs = append(errs, &Error{Field: key, Err: err})
+			continue
+		}
+
+		if err := checkFormat(field, fv); err != nil {
+			errs = append(errs, &Error{Field: key, Err: err})
+		}
+	}
+	return joinErrors(errs)
+}
+
+// valueStructs are struct types parsed from a single value rather than
+// walked as sections, besides those implementing encoding.TextUnmarshaler.
+var valueStructs = map[reflect.Type]bool{
+	urlType:          true,
+	hostPortType:     true,
+	timeOfDayType:    true,
+	cronScheduleType: true,
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// isTextUnmarshaler reports whether *t implements encoding.TextUnmarshaler.
+func isTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// isSection reports whether t is a nested configuration struct rather than
+// a value type.
+func isSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !valueStructs[t] && !isTextUnmarshaler(t)
+}
+
+// isOptionalSection reports whether t is a pointer to a section, which stays
+// nil unless at least one of its keys is provided.
+func isOptionalSection(t reflect.Type) bool {
+	return t.Kind() == reflect.Pointer && isSection(t.Elem())
+}
+
+// sectionPresent reports whether any key the section t at path reads was
+// provided. Other keys that merely share its prefix, such as REDIS_URL next
+// to a Redis section, do not count.
+func sectionPresent(values map[string]any, prefix string, t reflect.Type, path string) bool {
+	for _, k := range configKeys(t, path) {
+		if prefix != "" {
+			k = prefix + "_" + k
+		}
+		if values[k] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTagDefaults sets the default tags of a freshly allocated optional
+// section, which the Defaults provider does not cover.
+func applyTagDefaults(v reflect.Value, t reflect.Type, path string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+
+		if !fv.CanSet() {
+			continue
+		}
+
+		if isSection(field.Type) {
+			if err := applyTagDefaults(fv, field.Type, path+fieldName(field)+"_"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		def := field.Tag.Get("default")
+		if def == "" {
+			continue
+		}
+		if err := setField(fv, def); err != nil {
+			return &Error{Field: path + fieldName(field), Err: fmt.Errorf("%w: %v", ErrParse, err)}
 		}
 	}
 	return nil
 }
 
-func validateRequired(cfg any) error {
+func validateRequired(cfg any, profile string) error {
 	v := reflect.ValueOf(cfg).Elem()
 	t := v.Type()
-	return checkRequired(v, t, "")
+	errs := appendErrors(nil, checkRequired(v, t, "", profile))
+	return joinErrors(appendErrors(errs, checkRequiredGroups(v, t)))
+}
+
+// requiredGroup collects the fields sharing a requiredAny tag.
+type requiredGroup struct {
+	name string
+	keys []string
+	set  bool
+}
+
+// checkRequiredGroups enforces `requiredAny` tags: at least one field of each
+// named group must be non-zero.
+func checkRequiredGroups(v reflect.Value, t reflect.Type) error {
+	var groups []*requiredGroup
+	collectRequiredGroups(v, t, "", &groups)
+	var errs []error
+	for _, g := range groups {
+		if !g.set {
+			errs = append(errs, &Error{Field: g.name, Err: fmt.Errorf("%w: set at least one of %s", ErrRequired, strings.Join(g.keys, ", "))})
+		}
+	}
+	return joinErrors(errs)
 }
 
-func checkRequired(v reflect.Value, t reflect.Type, path string) error {
+func collectRequiredGroups(v reflect.Value, t reflect.Type, path string, groups *[]*requiredGroup) {
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		fv := v.Field(i)
 
-		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
-			nestedPath := path + toScreamingSnake(field.Name) + "_"
-			if err := checkRequired(fv, field.Type, nestedPath); err != nil {
-				return err
+		if isSection(field.Type) {
+			if !sectionDisabled(fv) {
+				collectRequiredGroups(fv, field.Type, path+fieldName(field)+"_", groups)
+			}
+			continue
+		}
+
+		if isOptionalSection(field.Type) {
+			if !fv.IsNil() && !sectionDisabled(fv.Elem()) {
+				collectRequiredGroups(fv.Elem(), field.Type.Elem(), path+fieldName(field)+"_", groups)
 			}
 			continue
 		}
 
-		if field.Tag.Get("required") == "true" && isZero(fv) {
-			return &Error{Field: path + toScreamingSnake(field.Name), Err: ErrRequired}
+		name := field.Tag.Get("requiredAny")
+		if name == "" {
+			continue
 		}
+
+		var g *requiredGroup
+		for _, existing := range *groups {
+			if existing.name == name {
+				g = existing
+				break
+			}
+		}
+		if g == nil {
+			g = &requiredGroup{name: name}
+			*groups = append(*groups, g)
+		}
+		g.keys = append(g.keys, path+fieldName(field))
+		g.set = g.set || !isZero(fv)
 	}
-	return nil
+}
+
+func checkRequired(v reflect.Value, t reflect.Type, path string, profile string) error {
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+
+		if isSection(field.Type) {
+			if sectionDisabled(fv) {
+				continue
+			}
+			nestedPath := path + fieldName(field) + "_"
+			errs = appendErrors(errs, checkRequired(fv, field.Type, nestedPath, profile))
+			continue
+		}
+
+		if isOptionalSection(field.Type) {
+			if fv.IsNil() || sectionDisabled(fv.Elem()) {
+				continue
+			}
+			nestedPath := path + fieldName(field) + "_"
+			errs = appendErrors(errs, checkRequired(fv.Elem(), field.Type.Elem(), nestedPath, profile))
+			continue
+		}
+
+		if isRequired(field.Tag.Get("required"), profile) && isZero(fv) {
+			errs = append(errs, &Error{Field: path + fieldName(field), Err: ErrRequired})
+		}
+	}
+	return joinErrors(errs)
+}
+
+// sectionDisabled reports whether a section has an Enabled bool field set
+// to false, in which case its required fields are not enforced.
+func sectionDisabled(v reflect.Value) bool {
+	enabled := v.FieldByName("Enabled")
+	return enabled.IsValid() && enabled.Kind() == reflect.Bool && !enabled.Bool()
+}
+
+// isRequired interprets the required tag: "true" always requires the field,
+// while a list of profiles (e.g. "staging,prod") requires it only when the
+// active profile is listed.
+func isRequired(tag string, profile string) bool {
+	switch tag {
+	case "", "false":
+		return false
+	case "true":
+		return true
+	}
+	if profile == "" {
+		return false
+	}
+	for _, p := range splitTagList(tag) {
+		if strings.EqualFold(p, profile) {
+			return true
+		}
+	}
+	return false
 }
 
 func isZero(v reflect.Value) bool {
@@ -91,8 +308,26 @@ func isZero(v reflect.Value) bool {
 }
 
 func setField(fv reflect.Value, val any) error {
+	if fv.Type() == urlType || fv.Type() == urlPtrType {
+		return setURL(fv, val)
+	}
+	if fv.Kind() == reflect.Pointer && fv.Type().Implements(textUnmarshalerType) {
+		ptr := reflect.New(fv.Type().Elem())
+		if err := unmarshalText(ptr, val); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	if fv.CanAddr() && isTextUnmarshaler(fv.Type()) {
+		return unmarshalText(fv.Addr(), val)
+	}
+
 	switch fv.Kind() {
 	case reflect.String:
+		if fv.Type() == localeType {
+			return setLocale(fv, val)
+		}
 		fv.SetString(fmt.Sprintf("%v", val))
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -110,7 +345,21 @@ func setField(fv reflect.Value, val any) error {
 	case reflect.Bool:
 		return setBoolValue(fv, val)
 
+	case reflect.Struct:
+		switch fv.Type() {
+		case hostPortType:
+			return setHostPort(fv, val)
+		case timeOfDayType:
+			return setTimeOfDay(fv, val)
+		case cronScheduleType:
+			return setCronSchedule(fv, val)
+		}
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, fv.Type())
+
 	case reflect.Slice:
+		if fv.Type() == urlListType {
+			return setURLList(fv, val)
+		}
 		items, err := normalizeSliceInput(val)
 		if err != nil {
 			return err
@@ -130,6 +379,20 @@ func setField(fv reflect.Value, val any) error {
 	return nil
 }
 
+// unmarshalText delegates to the UnmarshalText method of ptr.
+func unmarshalText(ptr reflect.Value, val any) error {
+	var s string
+	switch v := val.(type) {
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		s = fmt.Sprintf("%v", v)
+	}
+	return ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+}
+
 func setDuration(fv reflect.Value, val any) error {
 	switch v := val.(type) {
 	case string:
@@ -234,6 +497,13 @@ func normalizeSliceInput(val any) ([]any, error) {
 	return items, nil
 }
 
+// SplitCSV splits a list value the way slice fields are parsed: as one CSV
+// record, so items may be quoted to contain commas. Input that is not valid
+// CSV is split on every comma instead.
+func SplitCSV(s string) []string {
+	return splitCSV(s)
+}
+
 func splitCSV(s string) []string {
 	r := csv.NewReader(strings.NewReader(s))
 	parts, err := r.Read()
@@ -0,0 +1,74 @@
+package envx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// pluginProtocolVersion is sent to plugins so they can reject requests they
+// do not understand.
+const pluginProtocolVersion = 1
+
+type pluginRequest struct {
+	Version int `json:"version"`
+}
+
+type pluginResponse struct {
+	Values map[string]any `json:"values"`
+	Error  string         `json:"error"`
+}
+
+type pluginProvider struct {
+	path string
+	args []string
+}
+
+// Plugin returns a provider backed by an external executable. envx writes
+// {"version":1} to the plugin's stdin and expects a JSON object on stdout of
+// the form {"values":{...}} or {"error":"..."}. Nested values are flattened
+// like JSON files.
+func Plugin(path string, args ...string) Provider {
+	return &pluginProvider{path: path, args: args}
+}
+
+func (p *pluginProvider) Name() string { return filepath.Base(p.path) }
+
+func (p *pluginProvider) Describe() ProviderInfo {
+	return ProviderInfo{Source: p.path}
+}
+
+func (p *pluginProvider) Values() (map[string]any, error) {
+	req, err := json.Marshal(pluginRequest{Version: pluginProtocolVersion})
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(p.path, p.args...)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("envx: plugin %s: %w: %s", p.Name(), err, msg)
+		}
+		return nil, fmt.Errorf("envx: plugin %s: %w", p.Name(), err)
+	}
+
+	var resp pluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("envx: plugin %s: invalid response: %w", p.Name(), err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("envx: plugin %s: %s", p.Name(), resp.Error)
+	}
+
+	values := make(map[string]any)
+	flattenMap("", resp.Values, values)
+	return values, nil
+}
@@ -0,0 +1,94 @@
+package envx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Policy evaluates a loaded configuration and returns the reasons it is
+// denied, if any. The input holds "config", the configuration as nested
+// objects keyed by Go field names with secrets masked, and "profile".
+type Policy interface {
+	Evaluate(input map[string]any) (deny []string, err error)
+}
+
+// PolicyFunc adapts a function to Policy.
+type PolicyFunc func(input map[string]any) ([]string, error)
+
+func (f PolicyFunc) Evaluate(input map[string]any) ([]string, error) { return f(input) }
+
+// WithPolicy runs p after validation on every load and reload. A denial or
+// an evaluation error fails the load, so a reload that breaks policy keeps
+// the previous configuration.
+func WithPolicy(p Policy) Option {
+	return func(o *options) {
+		o.policies = append(o.policies, p)
+	}
+}
+
+func checkPolicies[T any](cfg *T, o *options) error {
+	if len(o.policies) == 0 {
+		return nil
+	}
+
+	v := reflect.ValueOf(cfg).Elem()
+	input := map[string]any{
+		"config":  maskSecrets(structToMap(v), v.Type()),
+		"profile": o.profile,
+	}
+	// Round-trip through JSON so in-process policies see the same shapes an
+	// OPA server would.
+	data, err := json.Marshal(input)
+	if err != nil {
+		return &Error{Field: "policy", Err: err}
+	}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return &Error{Field: "policy", Err: err}
+	}
+
+	var denials []string
+	for _, p := range o.policies {
+		deny, err := evaluatePolicy(p, input)
+		if errors.Is(err, ErrPanic) {
+			return err
+		}
+		if err != nil {
+			return &Error{Field: "policy", Err: err}
+		}
+		denials = append(denials, deny...)
+	}
+	if len(denials) > 0 {
+		return &Error{Field: "policy", Err: fmt.Errorf("%w: denied: %s", ErrValidation, strings.Join(denials, "; "))}
+	}
+	return nil
+}
+
+// maskSecrets masks the secret fields of a structToMap result in place.
+func maskSecrets(m map[string]any, t reflect.Type) map[string]any {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		val, ok := m[field.Name]
+		if !ok {
+			continue
+		}
+		switch {
+		case isSection(field.Type):
+			maskSecrets(val.(map[string]any), field.Type)
+		case isOptionalSection(field.Type):
+			maskSecrets(val.(map[string]any), field.Type.Elem())
+		case isSecret(field):
+			if s := fmt.Sprint(val); s != "" {
+				m[field.Name] = maskSecretValue(s)
+			}
+		}
+	}
+	return m
+}
+
+func evaluatePolicy(p Policy, input map[string]any) (deny []string, err error) {
+	defer recoverPanic("policy", &err)
+	return p.Evaluate(input)
+}
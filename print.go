@@ -5,46 +5,185 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 var secretMarkers = []string{"SECRET", "PASSWORD", "TOKEN", "KEY"}
 
-func Print[T any](cfg *T) {
-	PrintTo(os.Stdout, cfg)
+var printRule = strings.Repeat("─", 50) + "\n"
+
+func Print[T any](cfg *T, opts ...PrintOption) {
+	PrintTo(os.Stdout, cfg, opts...)
 }
 
-func PrintTo[T any](w io.Writer, cfg *T) {
+func PrintTo[T any](w io.Writer, cfg *T, opts ...PrintOption) {
+	var o printOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 	v := reflect.ValueOf(cfg).Elem()
-	t := v.Type()
 
-	fmt.Fprintln(w, "Configuration:")
-	fmt.Fprintln(w, strings.Repeat("─", 50))
-	printStruct(w, v, t, "")
-	fmt.Fprintln(w, strings.Repeat("─", 50))
+	bp := printBuffers.Get().(*[]byte)
+	buf := append((*bp)[:0], "Configuration:\n"...)
+	buf = append(buf, printRule...)
+	buf = planFor(v.Type()).append(buf, v, o.sources)
+	buf = append(buf, printRule...)
+	w.Write(buf)
+
+	*bp = buf
+	printBuffers.Put(bp)
 }
 
-func printStruct(w io.Writer, v reflect.Value, t reflect.Type, indent string) {
+// PrintOption configures Print and PrintTo.
+type PrintOption func(*printOptions)
+
+type printOptions struct {
+	sources map[string]string
+}
+
+// PrintSources annotates each value with where it came from, as reported
+// by Loader.Sources, e.g. "PORT = 9090 (env)". Values no source set are
+// marked "default".
+func PrintSources(sources map[string]string) PrintOption {
+	return func(o *printOptions) {
+		if sources == nil {
+			sources = map[string]string{}
+		}
+		o.sources = sources
+	}
+}
+
+var printBuffers = sync.Pool{New: func() any { b := make([]byte, 0, 1024); return &b }}
+
+// printPlan is the precomputed layout of a struct type for PrintTo, so
+// printing on every reload does not repeat tag lookups and name derivation.
+type printPlan struct {
+	fields []printField
+}
+
+type printField struct {
+	index    int
+	key      string     // variable name without the prefix
+	header   string     // "Name:\n" for sections, "NAME = " padded for values
+	nilLine  string     // optional sections that are nil
+	section  *printPlan // nested section layout
+	optional bool
+	secret   bool
+	format   func([]byte, reflect.Value) []byte
+}
+
+var printPlans sync.Map // reflect.Type -> *printPlan
+
+func planFor(t reflect.Type) *printPlan {
+	if p, ok := printPlans.Load(t); ok {
+		return p.(*printPlan)
+	}
+	p, _ := printPlans.LoadOrStore(t, buildPrintPlan(t, "", ""))
+	return p.(*printPlan)
+}
+
+func buildPrintPlan(t reflect.Type, indent, path string) *printPlan {
+	plan := &printPlan{}
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
-		fv := v.Field(i)
+		pf := printField{index: i, key: path + fieldName(field)}
 
-		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
-			fmt.Fprintf(w, "%s%s:\n", indent, field.Name)
-			printStruct(w, fv, field.Type, indent+"  ")
-			continue
+		switch {
+		case isSection(field.Type):
+			pf.header = indent + field.Name + ":\n"
+			pf.section = buildPrintPlan(field.Type, indent+"  ", pf.key+"_")
+		case isOptionalSection(field.Type):
+			pf.header = indent + field.Name + ":\n"
+			pf.nilLine = indent + field.Name + ": <nil>\n"
+			pf.section = buildPrintPlan(field.Type.Elem(), indent+"  ", pf.key+"_")
+			pf.optional = true
+		default:
+			pf.header = fmt.Sprintf("%s%-25s = ", indent, fieldName(field))
+			pf.secret = isSecret(field)
+			pf.format = scalarFormatter(field.Type)
+			if pf.format == nil {
+				pf.format = func(b []byte, v reflect.Value) []byte { return append(b, stringValue(v)...) }
+			}
 		}
+		plan.fields = append(plan.fields, pf)
+	}
+	return plan
+}
+
+// append writes the fields of v, each followed by its source when sources
+// is not nil.
+func (p *printPlan) append(buf []byte, v reflect.Value, sources map[string]string) []byte {
+	for _, pf := range p.fields {
+		fv := v.Field(pf.index)
 
-		name := toScreamingSnake(field.Name)
-		val := fmt.Sprintf("%v", fv.Interface())
+		if pf.section != nil {
+			if pf.optional {
+				if fv.IsNil() {
+					buf = append(buf, pf.nilLine...)
+					continue
+				}
+				fv = fv.Elem()
+			}
+			buf = append(buf, pf.header...)
+			buf = pf.section.append(buf, fv, sources)
+			continue
+		}
 
-		if isSecret(field) && len(val) > 0 {
-			val = maskSecretValue(val)
+		buf = append(buf, pf.header...)
+		if pf.secret {
+			if val := string(pf.format(nil, fv)); len(val) > 0 {
+				buf = append(buf, maskSecretValue(val)...)
+			}
+		} else {
+			buf = pf.format(buf, fv)
 		}
+		if sources != nil {
+			src, ok := sources[pf.key]
+			if !ok {
+				src = "default"
+			}
+			buf = append(append(append(buf, " ("...), src...), ')')
+		}
+		buf = append(buf, '\n')
+	}
+	return buf
+}
+
+var (
+	stringerType  = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	formatterType = reflect.TypeOf((*fmt.Formatter)(nil)).Elem()
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// scalarFormatter returns an fmt-free formatter producing the same text as
+// %v for durations and plain scalar types, or nil for anything else.
+func scalarFormatter(t reflect.Type) func([]byte, reflect.Value) []byte {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return func(b []byte, v reflect.Value) []byte { return append(b, time.Duration(v.Int()).String()...) }
+	}
+	pt := reflect.PointerTo(t)
+	if pt.Implements(stringerType) || pt.Implements(formatterType) || pt.Implements(errorType) {
+		return nil
+	}
 
-		fmt.Fprintf(w, "%s%-25s = %s\n", indent, name, val)
+	switch t.Kind() {
+	case reflect.String:
+		return func(b []byte, v reflect.Value) []byte { return append(b, v.String()...) }
+	case reflect.Bool:
+		return func(b []byte, v reflect.Value) []byte { return strconv.AppendBool(b, v.Bool()) }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(b []byte, v reflect.Value) []byte { return strconv.AppendInt(b, v.Int(), 10) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(b []byte, v reflect.Value) []byte { return strconv.AppendUint(b, v.Uint(), 10) }
+	case reflect.Float32:
+		return func(b []byte, v reflect.Value) []byte { return strconv.AppendFloat(b, v.Float(), 'g', -1, 32) }
+	case reflect.Float64:
+		return func(b []byte, v reflect.Value) []byte { return strconv.AppendFloat(b, v.Float(), 'g', -1, 64) }
 	}
+	return nil
 }
 
 func maskSecretValue(val string) string {
@@ -0,0 +1,231 @@
+package envx
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// resolvingProvider is implemented by providers that depend on the values
+// merged from the providers below them.
+type resolvingProvider interface {
+	resolve(t reflect.Type, current map[string]any, o *options) (map[string]any, error)
+}
+
+type promptProvider struct {
+	in      io.Reader
+	out     io.Writer
+	tty     *os.File
+	persist string
+}
+
+// Prompt asks on the terminal for required fields that no other provider
+// supplied, hiding input for secret fields. It does nothing when stdin is
+// not a terminal, so non-interactive runs fail with the usual ErrRequired.
+// Register it last so it sees every other source.
+func Prompt() Provider {
+	p := &promptProvider{out: os.Stderr}
+	if isTerminal(os.Stdin) {
+		p.in = os.Stdin
+		p.tty = os.Stdin
+	}
+	return p
+}
+
+// PromptAndSave is like Prompt but stores answers as a flat JSON object in
+// path, created readable by its owner only, and reuses them on later runs
+// instead of asking again.
+func PromptAndSave(path string) Provider {
+	p := Prompt().(*promptProvider)
+	p.persist = path
+	return p
+}
+
+func (p *promptProvider) Name() string { return "prompt" }
+
+func (p *promptProvider) PrefixAware() bool { return true }
+
+func (p *promptProvider) Values() (map[string]any, error) {
+	saved, err := p.saved()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]any, len(saved))
+	for k, v := range saved {
+		values[k] = v
+	}
+	return values, nil
+}
+
+func (p *promptProvider) resolve(t reflect.Type, current map[string]any, o *options) (map[string]any, error) {
+	saved, err := p.saved()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(saved))
+	for k, v := range saved {
+		values[k] = v
+	}
+	if p.in == nil {
+		return values, nil
+	}
+
+	st, err := resolveType(t)
+	if err != nil {
+		return values, nil
+	}
+
+	reader := bufio.NewReader(p.in)
+	asked := false
+	for _, f := range missingRequired(st, "", o, current, values) {
+		answer, err := p.ask(reader, f)
+		if err != nil {
+			return nil, err
+		}
+		if answer == "" {
+			continue
+		}
+		values[f.key] = answer
+		saved[f.key] = answer
+		asked = true
+	}
+
+	if asked && p.persist != "" {
+		data, err := json.MarshalIndent(saved, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFileAtomic(p.persist, append(data, '\n'), 0600); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func (p *promptProvider) saved() (map[string]string, error) {
+	saved := make(map[string]string)
+	if p.persist == "" {
+		return saved, nil
+	}
+	data, err := os.ReadFile(p.persist)
+	if os.IsNotExist(err) {
+		return saved, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, &Error{Field: "prompt", Err: fmt.Errorf("%w: %v", ErrParse, err)}
+	}
+	return saved, nil
+}
+
+type promptField struct {
+	key    string
+	secret bool
+}
+
+func missingRequired(t reflect.Type, path string, o *options, sources ...map[string]any) []promptField {
+	var fields []promptField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if isSection(field.Type) {
+			nestedPath := path + fieldName(field) + "_"
+			if gatedOff(field.Type, nestedPath, o, sources) {
+				continue
+			}
+			fields = append(fields, missingRequired(field.Type, nestedPath, o, sources...)...)
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			continue
+		}
+
+		if !isRequired(field.Tag.Get("required"), o.profile) {
+			continue
+		}
+
+		key := path + fieldName(field)
+		if o.prefix != "" {
+			key = o.prefix + "_" + key
+		}
+		if !hasValue(key, sources) {
+			fields = append(fields, promptField{key: key, secret: isSecret(field)})
+		}
+	}
+	return fields
+}
+
+// gatedOff mirrors sectionDisabled for values that have not been parsed yet.
+func gatedOff(t reflect.Type, path string, o *options, sources []map[string]any) bool {
+	field, ok := t.FieldByName("Enabled")
+	if !ok || field.Type.Kind() != reflect.Bool {
+		return false
+	}
+	key := path + "ENABLED"
+	if o.prefix != "" {
+		key = o.prefix + "_" + key
+	}
+	for _, src := range sources {
+		if v, ok := src[key]; ok && v != nil {
+			enabled, err := strconv.ParseBool(fmt.Sprint(v))
+			return err == nil && !enabled
+		}
+	}
+	return true
+}
+
+func hasValue(key string, sources []map[string]any) bool {
+	for _, src := range sources {
+		if v, ok := src[key]; ok && v != nil && fmt.Sprint(v) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *promptProvider) ask(r *bufio.Reader, f promptField) (string, error) {
+	fmt.Fprintf(p.out, "%s: ", f.key)
+	if f.secret && p.tty != nil {
+		if restore := disableEcho(p.tty); restore != nil {
+			defer func() {
+				restore()
+				fmt.Fprintln(p.out)
+			}()
+		}
+	}
+
+	line, err := r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// disableEcho turns off terminal echo via stty and returns a function that
+// restores it, or nil if echo could not be disabled.
+func disableEcho(tty *os.File) func() {
+	off := exec.Command("stty", "-echo")
+	off.Stdin = tty
+	if err := off.Run(); err != nil {
+		return nil
+	}
+	return func() {
+		on := exec.Command("stty", "echo")
+		on.Stdin = tty
+		on.Run()
+	}
+}
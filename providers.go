@@ -1,13 +1,15 @@
 package envx
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
-	"time"
+	"unicode/utf8"
 )
 
 type envProvider struct{}
@@ -18,32 +20,52 @@ func Env() Provider {
 
 func (envProvider) PrefixAware() bool { return true }
 
+func (envProvider) Name() string { return "env" }
+
 func (p *envProvider) Values() (map[string]any, error) {
 	values := make(map[string]any)
+	// Skip entries without a name, such as Windows' "=C:=C:\" drive
+	// variables or malformed entries passed to a WASM runtime.
 	for _, env := range os.Environ() {
-		if i := strings.Index(env, "="); i >= 0 {
+		if i := strings.Index(env, "="); i > 0 {
 			values[env[:i]] = env[i+1:]
 		}
 	}
 	return values, nil
 }
 
-type defaultsProvider[T any] struct {
+const defaultsProviderName = "defaults"
+
+type defaultsProvider struct {
+	typ    reflect.Type
 	prefix string
 }
 
-func (p *defaultsProvider[T]) PrefixAware() bool { return true }
+func (p *defaultsProvider) PrefixAware() bool { return true }
+
+func (p *defaultsProvider) Name() string { return defaultsProviderName }
 
 func Defaults[T any]() Provider {
 	return DefaultsWithPrefix[T]("")
 }
 
 func DefaultsWithPrefix[T any](prefix string) Provider {
-	return &defaultsProvider[T]{prefix: strings.ToUpper(prefix)}
+	return &defaultsProvider{typ: reflect.TypeOf((*T)(nil)).Elem(), prefix: strings.ToUpper(prefix)}
 }
 
-func (p *defaultsProvider[T]) Values() (map[string]any, error) {
-	t, err := resolveStructType[T]()
+// DefaultsOf extracts tag defaults from a struct type known only at runtime.
+func DefaultsOf(t reflect.Type) Provider {
+	return &defaultsProvider{typ: t}
+}
+
+// DefaultsFor extracts tag defaults from the dynamic type of v, which must be
+// a struct or a pointer to one.
+func DefaultsFor(v any) Provider {
+	return DefaultsOf(reflect.TypeOf(v))
+}
+
+func (p *defaultsProvider) Values() (map[string]any, error) {
+	t, err := resolveType(p.typ)
 	if err != nil {
 		return nil, err
 	}
@@ -66,8 +88,8 @@ func extractDefaults(t reflect.Type, path string) map[string]string {
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 
-		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
-			nestedPath := path + toScreamingSnake(field.Name) + "_"
+		if isSection(field.Type) {
+			nestedPath := path + fieldName(field) + "_"
 			for k, v := range extractDefaults(field.Type, nestedPath) {
 				values[k] = v
 			}
@@ -75,12 +97,90 @@ func extractDefaults(t reflect.Type, path string) map[string]string {
 		}
 
 		if def := field.Tag.Get("default"); def != "" {
-			values[path+toScreamingSnake(field.Name)] = def
+			values[path+fieldName(field)] = def
 		}
 	}
 	return values
 }
 
+func sourceRestrictions[T any](prefix string) map[string][]string {
+	t, err := resolveStructType[T]()
+	if err != nil {
+		return nil
+	}
+	return extractSources(t, "", prefix)
+}
+
+func extractSources(t reflect.Type, path string, prefix string) map[string][]string {
+	sources := make(map[string][]string)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if isSection(field.Type) {
+			nestedPath := path + fieldName(field) + "_"
+			for k, v := range extractSources(field.Type, nestedPath, prefix) {
+				sources[k] = v
+			}
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			nestedPath := path + fieldName(field) + "_"
+			for k, v := range extractSources(field.Type.Elem(), nestedPath, prefix) {
+				sources[k] = v
+			}
+			continue
+		}
+
+		from := field.Tag.Get("from")
+		if from == "" {
+			continue
+		}
+
+		key := path + fieldName(field)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		sources[key] = splitTagList(from)
+	}
+	return sources
+}
+
+// sourceAllowed reports whether provider p, named name, may populate key.
+// Struct defaults are always allowed since they are declared on the field
+// itself; a custom provider merely named "defaults" is not.
+func sourceAllowed(allowed map[string][]string, key string, p Provider, name string) bool {
+	names, ok := allowed[key]
+	if !ok || isDefaultsProvider(p) {
+		return true
+	}
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isDefaultsProvider reports whether p serves struct default tags.
+func isDefaultsProvider(p Provider) bool {
+	if _, ok := providerAs[*defaultsProvider](p); ok {
+		return true
+	}
+	_, ok := providerAs[targetDefaults](p)
+	return ok
+}
+
+func splitTagList(tag string) []string {
+	parts := strings.Split(tag, ",")
+	items := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			items = append(items, p)
+		}
+	}
+	return items
+}
+
 type fileProvider struct {
 	path string
 }
@@ -90,23 +190,56 @@ func File(path string) Provider {
 	return &fileProvider{path: absPath}
 }
 
+func (p *fileProvider) Name() string { return "file" }
+
+func (p *fileProvider) StrictKeys() bool { return true }
+
+func (p *fileProvider) Describe() ProviderInfo {
+	return ProviderInfo{Source: p.path, Watchable: true}
+}
+
 func (p *fileProvider) Values() (map[string]any, error) {
+	values, _, err := p.normalizedValues()
+	return values, err
+}
+
+// normalizedValues also reports the clean-ups applied to the file, which
+// WithNormalizationWarnings logs.
+func (p *fileProvider) normalizedValues() (map[string]any, []string, error) {
+	ext := strings.ToLower(filepath.Ext(p.path))
+	if ext != ".env" && ext != ".properties" {
+		if info, err := os.Stat(p.path); err == nil && info.Size() > streamThreshold {
+			return streamJSONFile(p.path)
+		}
+	}
+
 	data, err := os.ReadFile(p.path)
 	if err != nil && os.IsNotExist(err) {
-		return nil, nil
+		return nil, nil, nil
 	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	ext := strings.ToLower(filepath.Ext(p.path))
-	if ext == ".env" {
-		strMap := parseDotEnv(data)
-		values := make(map[string]any)
-		for k, v := range strMap {
-			values[k] = v
-		}
-		return values, nil
+	if err := checkEncoding(data, ext == ".properties"); err != nil {
+		return nil, nil, &Error{Field: p.path, Err: err}
+	}
+
+	data, notes := normalizeText(data)
+	for i, note := range notes {
+		notes[i] = p.path + ": " + note
+	}
+
+	values, err := decodeFile(data, ext)
+	return values, notes, err
+}
+
+func decodeFile(data []byte, ext string) (map[string]any, error) {
+	switch ext {
+	case ".env":
+		return anyValues(parseDotEnv(data)), nil
+	case ".properties":
+		return anyValues(parseProperties(data)), nil
 	}
 
 	var raw map[string]any
@@ -119,12 +252,75 @@ func (p *fileProvider) Values() (map[string]any, error) {
 	return values, nil
 }
 
+func anyValues(m map[string]string) map[string]any {
+	values := make(map[string]any, len(m))
+	for k, v := range m {
+		values[k] = v
+	}
+	return values
+}
+
+// normalizeText strips a UTF-8 byte order mark and converts CRLF line
+// endings to LF, returning a note for each change.
+func normalizeText(data []byte) ([]byte, []string) {
+	var notes []string
+	if bom := []byte{0xEF, 0xBB, 0xBF}; bytes.HasPrefix(data, bom) {
+		data = data[len(bom):]
+		notes = append(notes, "stripped UTF-8 byte order mark")
+	}
+	if bytes.Contains(data, []byte("\r\n")) {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+		notes = append(notes, "converted CRLF line endings")
+	}
+	return data, notes
+}
+
+// checkEncoding rejects content that is not text in the expected encoding:
+// UTF-16/32 files (as saved by some Windows editors), binary data, and
+// invalid UTF-8 unless Latin-1 is acceptable.
+func checkEncoding(data []byte, latin1 bool) error {
+	if err := checkByteOrderMark(data); err != nil {
+		return err
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return fmt.Errorf("%w: NUL byte at offset %d, file looks binary", ErrEncoding, i)
+	}
+	if !latin1 && !utf8.Valid(data) {
+		for i := 0; i < len(data); {
+			r, size := utf8.DecodeRune(data[i:])
+			if r == utf8.RuneError && size == 1 {
+				return fmt.Errorf("%w: invalid UTF-8 at offset %d", ErrEncoding, i)
+			}
+			i += size
+		}
+	}
+	return nil
+}
+
+// checkByteOrderMark rejects data starting with a UTF-16 or UTF-32 byte
+// order mark.
+func checkByteOrderMark(data []byte) error {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE, 0, 0}), bytes.HasPrefix(data, []byte{0, 0, 0xFE, 0xFF}):
+		return fmt.Errorf("%w: file is UTF-32 encoded, save it as UTF-8", ErrEncoding)
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return fmt.Errorf("%w: file is UTF-16 encoded, save it as UTF-8", ErrEncoding)
+	}
+	return nil
+}
+
+// ParseDotEnv parses .env content exactly as the File provider does. It
+// never fails: malformed lines are skipped.
+func ParseDotEnv(data []byte) map[string]string {
+	return parseDotEnv(data)
+}
+
 func parseDotEnv(data []byte) map[string]string {
 	values := make(map[string]string)
 	lines := strings.Split(string(data), "\n")
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
@@ -137,8 +333,25 @@ func parseDotEnv(data []byte) map[string]string {
 		key := strings.TrimSpace(line[:idx])
 		val := strings.TrimSpace(line[idx+1:])
 
-		if len(val) >= 2 && ((strings.HasPrefix(val, "\"") && strings.HasSuffix(val, "\"")) ||
-			(strings.HasPrefix(val, "'") && strings.HasSuffix(val, "'"))) {
+		escaped := false
+		if v, ok := strings.CutSuffix(val, dotEnvEscapedMark); ok {
+			if v = strings.TrimSpace(v); strings.HasPrefix(v, "\"") && closesQuote(v) {
+				val, escaped = v, true
+			}
+		}
+
+		if strings.HasPrefix(val, "\"") && !closesQuote(val) {
+			if end, joined, ok := joinQuotedLines(lines, i, val); ok {
+				i, val = end, joined
+			}
+		}
+
+		if len(val) >= 2 && strings.HasPrefix(val, "\"") && strings.HasSuffix(val, "\"") {
+			val = val[1 : len(val)-1]
+			if escaped {
+				val = unescapeDotEnv(val)
+			}
+		} else if len(val) >= 2 && strings.HasPrefix(val, "'") && strings.HasSuffix(val, "'") {
 			val = val[1 : len(val)-1]
 		}
 
@@ -147,6 +360,225 @@ func parseDotEnv(data []byte) map[string]string {
 	return values
 }
 
+// joinQuotedLines continues a double-quoted value opened on lines[start]
+// over the following lines up to its closing quote. It reports false when
+// the quote is never closed before a line reading as a KEY=value entry,
+// leaving the line to be read on its own.
+func joinQuotedLines(lines []string, start int, val string) (int, string, bool) {
+	for i := start + 1; i < len(lines); i++ {
+		if looksLikeEntry(lines[i]) {
+			break
+		}
+		val += "\n" + strings.TrimRight(lines[i], "\r")
+		if trimmed := strings.TrimRight(val, " \t"); closesQuote(trimmed) {
+			return i, trimmed, true
+		}
+	}
+	return start, val, false
+}
+
+// looksLikeEntry reports whether line reads as a KEY=value assignment. A
+// word followed only by '=' signs, as a line of base64 padding is, does not.
+func looksLikeEntry(line string) bool {
+	line = strings.TrimPrefix(strings.TrimSpace(line), "export ")
+	key, val, ok := strings.Cut(line, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" || strings.Trim(val, "= \t\r") == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '.' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// closesQuote reports whether a value opened with a double quote ends with
+// an unescaped one.
+func closesQuote(val string) bool {
+	if len(val) < 2 || !strings.HasSuffix(val, "\"") {
+		return false
+	}
+	backslashes := 0
+	for i := len(val) - 2; i > 0 && val[i] == '\\'; i-- {
+		backslashes++
+	}
+	return backslashes%2 == 0
+}
+
+// dotEnvEscapedMark follows a double-quoted value EncodeDotEnv wrote with
+// escaped line breaks, the only form whose escapes are expanded.
+const dotEnvEscapedMark = " # envx:escaped"
+
+// unescapeDotEnv expands the \n, \r, \" and \\ escapes of a value marked
+// with dotEnvEscapedMark. A value holding any other backslash sequence is
+// kept literally.
+func unescapeDotEnv(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\n', '\r', '"':
+			return s
+		case '\\':
+		default:
+			b.WriteByte(s[i])
+			continue
+		}
+		if i == len(s)-1 {
+			return s
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case '"', '\\':
+			b.WriteByte(s[i])
+		default:
+			return s
+		}
+	}
+	return b.String()
+}
+
+// parseProperties reads Java .properties content. Keys such as
+// server.maxConns map to SERVER_MAX_CONNS. Input that is not valid UTF-8 is
+// decoded as ISO-8859-1, the format's traditional encoding.
+func parseProperties(data []byte) map[string]string {
+	text := string(data)
+	if !utf8.Valid(data) {
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		text = string(runes)
+	}
+
+	values := make(map[string]string)
+	for _, line := range propertiesLines(text) {
+		key, val := splitProperty(line)
+		if key == "" {
+			continue
+		}
+		values[propertiesKey(unescapeProperty(key))] = unescapeProperty(val)
+	}
+	return values
+}
+
+// propertiesLines returns logical lines, joining continuations and dropping
+// blanks and comments.
+func propertiesLines(text string) []string {
+	var lines []string
+	var current strings.Builder
+	continuing := false
+
+	for _, raw := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := strings.TrimLeft(raw, " \t\f\r")
+		if !continuing && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+		line = strings.TrimRight(line, "\r")
+
+		trailing := len(line) - len(strings.TrimRight(line, "\\"))
+		continuing = trailing%2 == 1
+		if continuing {
+			line = line[:len(line)-1]
+		}
+
+		current.WriteString(line)
+		if !continuing {
+			lines = append(lines, current.String())
+			current.Reset()
+		}
+	}
+	if current.Len() > 0 {
+		lines = append(lines, current.String())
+	}
+	return lines
+}
+
+func splitProperty(line string) (string, string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			end = i
+			break
+		}
+	}
+
+	key := line[:end]
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return key, rest
+}
+
+func unescapeProperty(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 < len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					b.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			b.WriteByte('u')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func propertiesKey(key string) string {
+	parts := strings.FieldsFunc(key, func(r rune) bool { return r == '.' || r == '-' })
+	for i, p := range parts {
+		parts[i] = toScreamingSnake(p)
+	}
+	return strings.Join(parts, "_")
+}
+
+// Flatten converts nested maps, as decoded from JSON, into the flat
+// SCREAMING_SNAKE keys used by providers: {"db":{"poolSize":5}} becomes
+// {"DB_POOL_SIZE":5}. Lists are kept as values.
+func Flatten(m map[string]any) map[string]any {
+	out := make(map[string]any)
+	flattenMap("", m, out)
+	return out
+}
+
 func flattenMap(prefix string, m map[string]any, out map[string]any) {
 	for k, v := range m {
 		key := toScreamingSnake(k)
@@ -176,6 +608,10 @@ func Map(values map[string]string) Provider {
 
 func (mapProvider) PrefixAware() bool { return false }
 
+func (mapProvider) StrictKeys() bool { return true }
+
+func (mapProvider) Name() string { return "map" }
+
 func (p *mapProvider) Values() (map[string]any, error) {
 	values := make(map[string]any)
 	for k, v := range p.values {
@@ -184,10 +620,34 @@ func (p *mapProvider) Values() (map[string]any, error) {
 	return values, nil
 }
 
+// MapPrefixed is like Map but its keys are used as-is when a prefix is set,
+// so they must already carry the prefix.
+func MapPrefixed(values map[string]string) Provider {
+	return PrefixAware(Map(values), true)
+}
+
+// PrefixAware wraps p to control whether WithPrefix is applied to its keys.
+// Prefix-aware providers are expected to return fully prefixed keys.
+func PrefixAware(p Provider, aware bool) Provider {
+	return &prefixAwareProvider{Provider: p, aware: aware}
+}
+
+type prefixAwareProvider struct {
+	Provider
+	aware bool
+}
+
+func (p *prefixAwareProvider) PrefixAware() bool { return p.aware }
+
+func (p *prefixAwareProvider) unwrap() Provider { return p.Provider }
+
 // ============================================================================
 
 func resolveStructType[T any]() (reflect.Type, error) {
-	t := reflect.TypeOf((*T)(nil)).Elem()
+	return resolveType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+func resolveType(t reflect.Type) (reflect.Type, error) {
 	for t != nil && t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
@@ -199,6 +659,15 @@ func resolveStructType[T any]() (reflect.Type, error) {
 	return t, nil
 }
 
+// fieldName returns the variable name segment for field: its env tag when
+// set, otherwise the field name in SCREAMING_SNAKE_CASE.
+func fieldName(field reflect.StructField) string {
+	if name := field.Tag.Get("env"); name != "" {
+		return name
+	}
+	return toScreamingSnake(field.Name)
+}
+
 func toScreamingSnake(s string) string {
 	var b strings.Builder
 	runes := []rune(s)
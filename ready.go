@@ -0,0 +1,70 @@
+package envx
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// WaitReady blocks until the Loader has loaded a configuration, by Load or
+// by a later successful attempt, or until ctx is done. With a deadline it
+// bounds how long startup waits for configuration, e.g. when another
+// goroutine keeps retrying Load while a config service comes up.
+func (l *Loader[T]) WaitReady(ctx context.Context) error {
+	ready := l.readyChan()
+	select {
+	case <-ready:
+		return nil
+	default:
+	}
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *Loader[T]) isReady() bool {
+	select {
+	case <-l.readyChan():
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *Loader[T]) markReady() {
+	ready := l.readyChan()
+	l.readyMu.Lock()
+	defer l.readyMu.Unlock()
+	select {
+	case <-ready:
+	default:
+		close(ready)
+	}
+}
+
+// readyChan returns the channel closed by markReady, creating it on first
+// use so the zero Loader works.
+func (l *Loader[T]) readyChan() chan struct{} {
+	l.readyMu.Lock()
+	defer l.readyMu.Unlock()
+	if l.ready == nil {
+		l.ready = make(chan struct{})
+	}
+	return l.ready
+}
+
+// ReadyHandler answers 200 once l has loaded a configuration and 503 until
+// then, for use as a Kubernetes readiness probe. A failing reload keeps the
+// last configuration in use, so it does not make the handler unready.
+func ReadyHandler[T any](l *Loader[T]) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.isReady() {
+			http.Error(w, ErrNotLoaded.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "ok\n")
+	})
+}
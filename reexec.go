@@ -0,0 +1,80 @@
+package envx
+
+import (
+	"os"
+	"reflect"
+	"strings"
+)
+
+// RestartRequired lists the keys of fields tagged restart:"true" whose
+// values differ between old and new, e.g. a listen address that is only
+// read at startup. Call it from an OnReload callback to decide whether to
+// Reexec.
+func RestartRequired[T any](old, new *T) []string {
+	if old == nil || new == nil {
+		return nil
+	}
+	ov, nv := reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem()
+	if ov.Kind() != reflect.Struct {
+		return nil
+	}
+
+	before := make(map[string]string)
+	for _, kv := range flattenConfig(ov, ov.Type(), "") {
+		before[kv.key] = kv.value
+	}
+
+	var keys []string
+	for _, kv := range flattenConfig(nv, nv.Type(), "") {
+		if kv.field.Tag.Get("restart") != "true" {
+			continue
+		}
+		if old, ok := before[kv.key]; !ok || old != kv.value {
+			keys = append(keys, kv.key)
+		}
+	}
+	return keys
+}
+
+// Reexec replaces the running process with a fresh copy of its executable,
+// started with the same arguments and the current environment updated from
+// envFiles, read like EnvFile with later files winning. It only returns on
+// failure, and always fails with errors.ErrUnsupported where exec is not
+// available, such as on Windows.
+func Reexec(envFiles ...string) error {
+	env, err := reexecEnv(envFiles)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	return execProcess(exe, os.Args, env)
+}
+
+func reexecEnv(envFiles []string) ([]string, error) {
+	env := os.Environ()
+	index := make(map[string]int, len(env))
+	for i, kv := range env {
+		if name, _, ok := strings.Cut(kv, "="); ok {
+			index[name] = i
+		}
+	}
+
+	for _, path := range envFiles {
+		data, err := (&envFileProvider{path: path}).read()
+		if err != nil {
+			return nil, err
+		}
+		for name, val := range parseEnvFile(data) {
+			if i, ok := index[name]; ok {
+				env[i] = name + "=" + val
+				continue
+			}
+			index[name] = len(env)
+			env = append(env, name+"="+val)
+		}
+	}
+	return env, nil
+}
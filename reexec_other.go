@@ -0,0 +1,9 @@
+//go:build !unix
+
+package envx
+
+import "errors"
+
+func execProcess(path string, args, env []string) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,144 @@
+package envx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// A Resolver fetches the secret a reference points at. ref is the
+// reference without its ref+ prefix and #fragment.
+type Resolver func(ref *url.URL) (string, error)
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{}
+)
+
+// RegisterResolver makes fn resolve ref+<scheme>:// references in every
+// load, replacing any resolver already registered for scheme.
+func RegisterResolver(scheme string, fn Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = fn
+}
+
+// WithResolver makes fn resolve ref+<scheme>:// references for this load
+// only, taking precedence over a registered resolver.
+func WithResolver(scheme string, fn Resolver) Option {
+	return func(o *options) {
+		if o.resolvers == nil {
+			o.resolvers = make(map[string]Resolver)
+		}
+		o.resolvers[scheme] = fn
+	}
+}
+
+func lookupResolver(scheme string, o *options) (Resolver, bool) {
+	if fn, ok := o.resolvers[scheme]; ok {
+		return fn, true
+	}
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	fn, ok := resolvers[scheme]
+	return fn, ok
+}
+
+// resolveRefs replaces values of the form ref+<scheme>://<path>[#<key>]
+// with what the scheme's resolver returns, for the keys t reads. A fragment
+// selects a key from a resolved JSON object, with / separating nested keys.
+// Each reference is resolved once per load.
+func resolveRefs(t reflect.Type, values map[string]any, o *options) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs []error
+	resolved := make(map[string]string)
+	if o.pass != nil {
+		resolved = o.pass.refs
+	}
+	for _, k := range configKeys(t, "") {
+		if o.prefix != "" {
+			k = o.prefix + "_" + k
+		}
+		s, ok := values[k].(string)
+		if !ok || !strings.HasPrefix(s, "ref+") {
+			continue
+		}
+		if val, ok := resolved[s]; ok {
+			values[k] = val
+			continue
+		}
+		val, err := resolveRef(strings.TrimPrefix(s, "ref+"), o)
+		if err != nil {
+			errs = append(errs, &Error{Field: k, Err: fmt.Errorf("%w: %s: %w", ErrReference, s, err)})
+			continue
+		}
+		resolved[s] = val
+		values[k] = val
+	}
+	return joinErrors(errs)
+}
+
+func resolveRef(ref string, o *options) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	fn, ok := lookupResolver(u.Scheme, o)
+	if !ok {
+		return "", fmt.Errorf("no resolver for scheme %q", u.Scheme)
+	}
+
+	fragment := u.Fragment
+	u.Fragment, u.RawFragment = "", ""
+	val, err := callResolver(fn, u)
+	if err != nil || fragment == "" {
+		return val, err
+	}
+	return selectRefKey(val, fragment)
+}
+
+func callResolver(fn Resolver, u *url.URL) (val string, err error) {
+	defer recoverPanic("resolver "+u.Scheme, &err)
+	return fn(u)
+}
+
+func selectRefKey(doc, path string) (string, error) {
+	var cur any
+	if err := json.Unmarshal([]byte(doc), &cur); err != nil {
+		return "", fmt.Errorf("secret is not JSON, cannot select %q", path)
+	}
+	for _, key := range strings.Split(strings.Trim(path, "/"), "/") {
+		obj, ok := cur.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("no key %q in secret", path)
+		}
+		if cur, ok = obj[key]; !ok {
+			return "", fmt.Errorf("no key %q in secret", path)
+		}
+	}
+	if s, ok := cur.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(cur)
+	return string(data), err
+}
+
+// ResolveFileRef reads ref+file://relative/path or ref+file:///absolute/path,
+// dropping trailing line breaks. It is not registered by default, since any
+// source could then read local files into the config, e.g. a remote one
+// naming /etc/shadow; opt in with WithResolver("file", ResolveFileRef) when
+// every source is trusted.
+func ResolveFileRef(ref *url.URL) (string, error) {
+	data, err := os.ReadFile(ref.Host + ref.Path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,144 @@
+package envx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+type managedLoader interface {
+	load() error
+	StartWatching() error
+	StopWatching()
+	Err() error
+	WaitReady(ctx context.Context) error
+	isReady() bool
+}
+
+// Registry manages named loaders that share a common set of options, for
+// applications with several configuration structs.
+type Registry struct {
+	mu      sync.Mutex
+	opts    []Option
+	names   []string
+	loaders map[string]managedLoader
+}
+
+// NewRegistry returns a registry whose loaders all receive opts before their
+// own options.
+func NewRegistry(opts ...Option) *Registry {
+	return &Registry{opts: opts, loaders: make(map[string]managedLoader)}
+}
+
+// Register creates a loader for T under name.
+func Register[T any](r *Registry, name string, opts ...Option) (*Loader[T], error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.loaders[name]; ok {
+		return nil, fmt.Errorf("envx: loader %q already registered", name)
+	}
+
+	all := make([]Option, 0, len(r.opts)+len(opts))
+	all = append(all, r.opts...)
+	all = append(all, opts...)
+
+	l := NewLoader[T](all...)
+	r.loaders[name] = l
+	r.names = append(r.names, name)
+	return l, nil
+}
+
+// Lookup returns the loader registered under name if it loads T.
+func Lookup[T any](r *Registry, name string) (*Loader[T], bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.loaders[name].(*Loader[T])
+	return l, ok
+}
+
+// Names returns the registered names in registration order.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.names...)
+}
+
+// Load loads every registered loader and returns their errors, a
+// MultiError if more than one failed.
+func (r *Registry) Load() error {
+	var errs []error
+	for _, name := range r.Names() {
+		if err := r.get(name).load(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return joinErrors(errs)
+}
+
+// StartWatching starts watching for every loader. If any fails, the loaders
+// already started are stopped again.
+func (r *Registry) StartWatching() error {
+	var started []managedLoader
+	for _, name := range r.Names() {
+		l := r.get(name)
+		if err := l.StartWatching(); err != nil {
+			for _, s := range started {
+				s.StopWatching()
+			}
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		started = append(started, l)
+	}
+	return nil
+}
+
+func (r *Registry) StopWatching() {
+	for _, name := range r.Names() {
+		r.get(name).StopWatching()
+	}
+}
+
+// Health reports the last load error of every loader, keyed by name. A nil
+// entry means the loader is healthy.
+func (r *Registry) Health() map[string]error {
+	health := make(map[string]error)
+	for _, name := range r.Names() {
+		health[name] = r.get(name).Err()
+	}
+	return health
+}
+
+// WaitReady blocks until every loader has loaded a configuration, or until
+// ctx is done.
+func (r *Registry) WaitReady(ctx context.Context) error {
+	for _, name := range r.Names() {
+		if err := r.get(name).WaitReady(ctx); err != nil {
+			return fmt.Errorf("envx: loader %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ReadyHandler serves like the package-level ReadyHandler, answering 503
+// until every loader of the registry has loaded.
+func (r *Registry) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for _, name := range r.Names() {
+			if !r.get(name).isReady() {
+				http.Error(w, fmt.Sprintf("%s: %v", name, ErrNotLoaded), http.StatusServiceUnavailable)
+				return
+			}
+		}
+		io.WriteString(w, "ok\n")
+	})
+}
+
+func (r *Registry) get(name string) managedLoader {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.loaders[name]
+}
@@ -0,0 +1,172 @@
+package envx
+
+import (
+	"maps"
+	"reflect"
+)
+
+// onRemove tag values, chosen per field for when a reload finds that no
+// source sets the field's key anymore.
+const (
+	// removeDefault reverts the field to its default tag, or its zero value.
+	removeDefault = "default"
+	// removeKeep keeps the value of the last applied configuration until a
+	// source sets the key again.
+	removeKeep = "keep"
+	// removeFail rejects the reload, so the previous configuration stays.
+	removeFail = "fail"
+)
+
+// removalState is what a reload checks onRemove tags against: the keys
+// sources set for the applied configuration, and the values and source
+// labels of those tagged onRemove:"keep".
+type removalState struct {
+	set    map[string]bool
+	kept   map[string]bool
+	values map[string]any
+	origin map[string]string
+}
+
+func withRemovals(prev *removalState) Option {
+	return func(o *options) {
+		o.removals = prev
+	}
+}
+
+// setsKeys reports whether the keys p provides count as set for onRemove:
+// struct defaults and runtime overrides do not.
+func setsKeys(p Provider) bool {
+	if _, ok := providerAs[*defaultsProvider](p); ok {
+		return false
+	}
+	layer := providerLayer(p)
+	return layer != LayerDefaults && layer != LayerOverride
+}
+
+// applyRemovals enforces the onRemove tags of T on the merged values of a
+// reload before they are parsed, so the result is validated like any other.
+// Keys that no source sets anymore revert to their default tag, keep their
+// value from prev, or fail the reload.
+func applyRemovals[T any](values map[string]any, c *valueCache, prev *removalState, o *options) error {
+	var errs []error
+	for key, field := range removalFields[T](o.prefix) {
+		if !prev.set[key] || c.set[key] {
+			continue
+		}
+
+		switch field.Tag.Get("onRemove") {
+		case removeDefault:
+			if values[key] != nil {
+				continue
+			}
+			if def := field.Tag.Get("default"); def != "" {
+				values[key] = def
+			} else {
+				delete(values, key)
+			}
+		case removeKeep:
+			v, ok := prev.values[key]
+			if !ok {
+				continue
+			}
+			values[key] = v
+			c.set[key] = true
+			c.kept[key] = true
+			c.origin[key] = prev.origin[key]
+			if !prev.kept[key] {
+				o.logger.Printf("envx: %s was removed from its source, keeping its last value\n", key)
+			}
+		case removeFail:
+			errs = append(errs, &Error{Field: key, Err: ErrRemoved})
+		}
+	}
+	return joinErrors(errs)
+}
+
+// removals captures the removal state of the load that produced values
+// through l.cache. The caller holds l.mu.
+func (l *Loader[T]) removals(values map[string]any) *removalState {
+	c := &l.cache
+	state := &removalState{
+		set:    maps.Clone(c.set),
+		kept:   maps.Clone(c.kept),
+		values: make(map[string]any),
+		origin: make(map[string]string),
+	}
+	for key, field := range removalFields[T](prepareOptions[T](l.opts).prefix) {
+		if v, ok := values[key]; ok && v != nil && field.Tag.Get("onRemove") == removeKeep {
+			state.values[key] = v
+			state.origin[key] = c.origin[key]
+		}
+	}
+	return state
+}
+
+// noteKept records the keys the last load kept in the state it was checked
+// against, so the warning is logged once: keeping a key leaves the values
+// unchanged, which ends the reload early. The caller holds l.mu.
+func (l *Loader[T]) noteKept() {
+	if l.removalState != nil {
+		maps.Copy(l.removalState.kept, l.cache.kept)
+	}
+}
+
+// removalFields maps the keys of T's fields tagged onRemove to the fields.
+func removalFields[T any](prefix string) map[string]reflect.StructField {
+	t, err := resolveStructType[T]()
+	if err != nil {
+		return nil
+	}
+	fields := make(map[string]reflect.StructField)
+	collectRemovals(fields, t, "")
+	if prefix == "" {
+		return fields
+	}
+	prefixed := make(map[string]reflect.StructField, len(fields))
+	for key, field := range fields {
+		prefixed[prefix+"_"+key] = field
+	}
+	return prefixed
+}
+
+func collectRemovals(out map[string]reflect.StructField, t reflect.Type, path string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if isSection(field.Type) {
+			collectRemovals(out, field.Type, path+fieldName(field)+"_")
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			collectRemovals(out, field.Type.Elem(), path+fieldName(field)+"_")
+			continue
+		}
+
+		if field.Tag.Get("onRemove") != "" {
+			out[path+fieldName(field)] = field
+		}
+	}
+}
+
+// checkRemovalTags reports onRemove tags with an unknown mode.
+func checkRemovalTags(t reflect.Type, path string, invalid func(option, format string, args ...any)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if isSection(field.Type) {
+			checkRemovalTags(field.Type, path+fieldName(field)+"_", invalid)
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			checkRemovalTags(field.Type.Elem(), path+fieldName(field)+"_", invalid)
+			continue
+		}
+		switch mode := field.Tag.Get("onRemove"); mode {
+		case "", removeDefault, removeKeep, removeFail:
+		default:
+			invalid("onRemove", "%s: unknown mode %q, want default, keep or fail", path+fieldName(field), mode)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package envx
+
+import (
+	"os"
+	"runtime"
+)
+
+type runtimeProvider struct{}
+
+// Runtime returns a provider of values computed from the running process:
+// HOSTNAME, NUM_CPU, GOMAXPROCS and PID. Register it in LayerDefaults so
+// explicit settings still win, and bind fields with the env tag, e.g.
+// WorkerCount int `env:"NUM_CPU"`.
+func Runtime() Provider {
+	return &runtimeProvider{}
+}
+
+func (p *runtimeProvider) Name() string { return "runtime" }
+
+func (p *runtimeProvider) Values() (map[string]any, error) {
+	values := map[string]any{
+		"NUM_CPU":    runtime.NumCPU(),
+		"GOMAXPROCS": runtime.GOMAXPROCS(0),
+		"PID":        os.Getpid(),
+	}
+	if host, err := os.Hostname(); err == nil {
+		values["HOSTNAME"] = host
+	}
+	return values, nil
+}
@@ -0,0 +1,259 @@
+package envx
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeOfDay is a wall-clock time parsed from "15:04" or "15:04:05".
+type TimeOfDay struct {
+	Hour   int
+	Minute int
+	Second int
+}
+
+var timeOfDayType = reflect.TypeOf(TimeOfDay{})
+
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return TimeOfDay{}, fmt.Errorf("invalid time of day %q, want HH:MM or HH:MM:SS", s)
+	}
+
+	limits := []int{23, 59, 59}
+	var fields [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || len(p) != 2 || n < 0 || n > limits[i] {
+			return TimeOfDay{}, fmt.Errorf("invalid time of day %q, want HH:MM or HH:MM:SS", s)
+		}
+		fields[i] = n
+	}
+	return TimeOfDay{Hour: fields[0], Minute: fields[1], Second: fields[2]}, nil
+}
+
+func (t TimeOfDay) String() string {
+	if t.Second != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	}
+	return fmt.Sprintf("%02d:%02d", t.Hour, t.Minute)
+}
+
+// MarshalText encodes the time in the same form it is parsed from.
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// On returns the instant at this time of day on day's date, in day's
+// location.
+func (t TimeOfDay) On(day time.Time) time.Time {
+	y, m, d := day.Date()
+	return time.Date(y, m, d, t.Hour, t.Minute, t.Second, 0, day.Location())
+}
+
+// CronSchedule is a validated cron spec in the syntax of robfig/cron's
+// standard parser: five fields (minute, hour, day of month, month, day of
+// week) with lists, ranges, steps and month/day names, or a descriptor such
+// as @daily or @every 90m.
+type CronSchedule struct {
+	spec  string
+	every time.Duration
+	// bit sets of the allowed values of each field
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+var cronScheduleType = reflect.TypeOf(CronSchedule{})
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = []cronField{
+	{min: 0, max: 59},
+	{min: 0, max: 23},
+	{min: 1, max: 31},
+	{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{min: 0, max: 6, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+func ParseCronSchedule(spec string) (CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	s := CronSchedule{spec: spec}
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d < time.Second {
+			return CronSchedule{}, fmt.Errorf("invalid cron spec %q: @every needs a duration of at least 1s", spec)
+		}
+		s.every = d
+		return s, nil
+	}
+
+	expr := spec
+	if strings.HasPrefix(spec, "@") {
+		var ok bool
+		if expr, ok = cronDescriptors[strings.ToLower(spec)]; !ok {
+			return CronSchedule{}, fmt.Errorf("invalid cron spec %q: unknown descriptor", spec)
+		}
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return CronSchedule{}, fmt.Errorf("invalid cron spec %q: want 5 fields, got %d", spec, len(fields))
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, f := range fields {
+		bits, err := parseCronField(f, cronFields[i])
+		if err != nil {
+			return CronSchedule{}, fmt.Errorf("invalid cron spec %q: %v", spec, err)
+		}
+		sets[i] = bits
+	}
+	s.minute, s.hour, s.dom, s.month, s.dow = sets[0], sets[1], sets[2], sets[3], sets[4]
+	s.domStar = fields[2] == "*" || fields[2] == "?"
+	s.dowStar = fields[4] == "*" || fields[4] == "?"
+	return s, nil
+}
+
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := spec.min, spec.max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = cronValue(a, spec); err != nil {
+				return 0, err
+			}
+			if hi, err = cronValue(b, spec); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			n, err := cronValue(rangePart, spec)
+			if err != nil {
+				return 0, err
+			}
+			lo = n
+			if !hasStep {
+				hi = n
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s string, spec cronField) (int, error) {
+	if n, ok := spec.names[strings.ToLower(s)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < spec.min || n > spec.max {
+		return 0, fmt.Errorf("value %q out of range %d-%d", s, spec.min, spec.max)
+	}
+	return n, nil
+}
+
+func (s CronSchedule) String() string { return s.spec }
+
+// MarshalText encodes the schedule as its original spec.
+func (s CronSchedule) MarshalText() ([]byte, error) {
+	return []byte(s.spec), nil
+}
+
+// Next returns the first activation strictly after t, or the zero time if
+// the schedule is empty or never fires (e.g. "0 0 30 2 *").
+func (s CronSchedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every - time.Duration(t.Nanosecond()))
+	}
+	if s.minute == 0 {
+		return time.Time{}
+	}
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's rule that a restricted day of month and day of
+// week match when either does.
+func (s CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func setTimeOfDay(fv reflect.Value, val any) error {
+	t, err := ParseTimeOfDay(fmt.Sprint(val))
+	if err != nil {
+		return err
+	}
+	fv.Set(reflect.ValueOf(t))
+	return nil
+}
+
+func setCronSchedule(fv reflect.Value, val any) error {
+	s, err := ParseCronSchedule(fmt.Sprint(val))
+	if err != nil {
+		return err
+	}
+	fv.Set(reflect.ValueOf(s))
+	return nil
+}
@@ -0,0 +1,399 @@
+package envx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchema is the subset of JSON Schema understood by
+// WithSchemaValidation.
+type jsonSchema struct {
+	Type                 schemaTypes            `json:"type"`
+	Enum                 []any                  `json:"enum"`
+	Const                json.RawMessage        `json:"const"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+	Items                *jsonSchema            `json:"items"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties"`
+	AllOf                []*jsonSchema          `json:"allOf"`
+	AnyOf                []*jsonSchema          `json:"anyOf"`
+	OneOf                []*jsonSchema          `json:"oneOf"`
+	Not                  *jsonSchema            `json:"not"`
+
+	pattern *regexp.Regexp
+	never   bool // the boolean schema false
+}
+
+// schemaTypes accepts "type" as a string or a list of strings.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*t = schemaTypes{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+// UnmarshalJSON also accepts the boolean schemas true and false.
+func (s *jsonSchema) UnmarshalJSON(b []byte) error {
+	var flag bool
+	if err := json.Unmarshal(b, &flag); err == nil {
+		*s = jsonSchema{never: !flag}
+		return nil
+	}
+	type plain jsonSchema
+	return json.Unmarshal(b, (*plain)(s))
+}
+
+// WithSchemaValidation validates the merged raw values against a JSON
+// Schema before they are parsed into the struct. The schema describes an
+// object keyed by variable names without the prefix, covering the keys T
+// reads and those named in properties, so unrelated environment variables
+// are ignored. Since most sources only carry strings, "integer", "number",
+// "boolean" and "array" accept values that parse as such (arrays as
+// comma-separated lists). Violations are ErrValidation errors whose Field is
+// a JSON pointer such as "/PORT".
+//
+// Supported keywords: type, enum, const, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, minLength, maxLength, pattern (RE2), minItems, maxItems,
+// items, required, properties, additionalProperties, allOf, anyOf, oneOf and
+// not.
+func WithSchemaValidation(schema []byte) Option {
+	return func(o *options) {
+		var s jsonSchema
+		err := json.Unmarshal(schema, &s)
+		if err == nil {
+			err = s.compile()
+		}
+		if err != nil {
+			o.errs = append(o.errs, &Error{Field: "WithSchemaValidation", Err: fmt.Errorf("%w: invalid schema: %v", ErrInvalidOptions, err)})
+			return
+		}
+		o.schema = &s
+	}
+}
+
+func (s *jsonSchema) compile() error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = re
+	}
+	children := []*jsonSchema{s.Items, s.AdditionalProperties, s.Not}
+	children = append(children, s.AllOf...)
+	children = append(children, s.AnyOf...)
+	children = append(children, s.OneOf...)
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	for _, c := range children {
+		if err := c.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSchema validates the values of the keys t reads, and of those the
+// schema names, against o.schema.
+func checkSchema(t reflect.Type, values map[string]any, o *options) error {
+	if o.schema == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	keys := configKeys(t, "")
+	for k := range o.schema.Properties {
+		keys = append(keys, k)
+	}
+	doc := make(map[string]any)
+	for _, k := range keys {
+		full := k
+		if o.prefix != "" {
+			full = o.prefix + "_" + k
+		}
+		if v, ok := values[full]; ok && v != nil {
+			doc[k] = v
+		}
+	}
+
+	var errs []error
+	for _, v := range o.schema.validate(doc, "") {
+		errs = append(errs, &Error{Field: v.path, Err: fmt.Errorf("%w: %s", ErrValidation, v.msg)})
+	}
+	return joinErrors(errs)
+}
+
+type schemaViolation struct {
+	path string
+	msg  string
+}
+
+func (s *jsonSchema) validate(v any, path string) []schemaViolation {
+	if s == nil {
+		return nil
+	}
+	fail := func(format string, args ...any) []schemaViolation {
+		p := path
+		if p == "" {
+			p = "/"
+		}
+		return []schemaViolation{{path: p, msg: fmt.Sprintf(format, args...)}}
+	}
+	if s.never {
+		return fail("not allowed")
+	}
+
+	if len(s.Type) > 0 {
+		matched := false
+		for _, typ := range s.Type {
+			if _, ok := schemaCoerce(v, typ); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fail("%s is not of type %s", schemaString(v), strings.Join(s.Type, " or "))
+		}
+	}
+
+	var out []schemaViolation
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if schemaString(e) == schemaString(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			items := make([]string, len(s.Enum))
+			for i, e := range s.Enum {
+				items[i] = schemaString(e)
+			}
+			out = append(out, fail("%s is not one of %s", schemaString(v), strings.Join(items, ", "))...)
+		}
+	}
+	if len(s.Const) > 0 {
+		var c any
+		if json.Unmarshal(s.Const, &c) == nil && schemaString(c) != schemaString(v) {
+			out = append(out, fail("%s must be %s", schemaString(v), schemaString(c))...)
+		}
+	}
+
+	if n, ok := schemaCoerce(v, "number"); ok {
+		f := n.(float64)
+		if s.Minimum != nil && f < *s.Minimum {
+			out = append(out, fail("%v is less than %v", f, *s.Minimum)...)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			out = append(out, fail("%v is greater than %v", f, *s.Maximum)...)
+		}
+		if s.ExclusiveMinimum != nil && f <= *s.ExclusiveMinimum {
+			out = append(out, fail("%v must be greater than %v", f, *s.ExclusiveMinimum)...)
+		}
+		if s.ExclusiveMaximum != nil && f >= *s.ExclusiveMaximum {
+			out = append(out, fail("%v must be less than %v", f, *s.ExclusiveMaximum)...)
+		}
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		out = append(out, s.validateObject(val, path)...)
+	case []any:
+	default:
+		str := schemaString(val)
+		n := utf8.RuneCountInString(str)
+		if s.MinLength != nil && n < *s.MinLength {
+			out = append(out, fail("length %d is less than %d", n, *s.MinLength)...)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			out = append(out, fail("length %d is greater than %d", n, *s.MaxLength)...)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			out = append(out, fail("%q does not match %s", str, s.Pattern)...)
+		}
+	}
+
+	// Strings only count as lists where the schema expects an array.
+	if items, ok := schemaCoerce(v, "array"); ok && (s.allows("array") || isSchemaList(v)) {
+		list := items.([]any)
+		if s.MinItems != nil && len(list) < *s.MinItems {
+			out = append(out, fail("%d items, want at least %d", len(list), *s.MinItems)...)
+		}
+		if s.MaxItems != nil && len(list) > *s.MaxItems {
+			out = append(out, fail("%d items, want at most %d", len(list), *s.MaxItems)...)
+		}
+		for i, item := range list {
+			out = append(out, s.Items.validate(item, path+"/"+strconv.Itoa(i))...)
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		out = append(out, sub.validate(v, path)...)
+	}
+	if len(s.AnyOf) > 0 && countValid(s.AnyOf, v, path) == 0 {
+		out = append(out, fail("does not match any schema in anyOf")...)
+	}
+	if len(s.OneOf) > 0 {
+		if n := countValid(s.OneOf, v, path); n != 1 {
+			out = append(out, fail("matches %d schemas in oneOf, want exactly 1", n)...)
+		}
+	}
+	if s.Not != nil && len(s.Not.validate(v, path)) == 0 {
+		out = append(out, fail("must not match the schema in not")...)
+	}
+	return out
+}
+
+func (s *jsonSchema) validateObject(m map[string]any, path string) []schemaViolation {
+	var out []schemaViolation
+	for _, k := range s.Required {
+		if _, ok := m[k]; !ok {
+			out = append(out, schemaViolation{path: path + "/" + escapePointer(k), msg: "is required"})
+		}
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		child := path + "/" + escapePointer(k)
+		if p, ok := s.Properties[k]; ok {
+			out = append(out, p.validate(m[k], child)...)
+		} else if s.AdditionalProperties != nil {
+			out = append(out, s.AdditionalProperties.validate(m[k], child)...)
+		}
+	}
+	return out
+}
+
+func (s *jsonSchema) allows(typ string) bool {
+	for _, t := range s.Type {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func countValid(schemas []*jsonSchema, v any, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(sub.validate(v, path)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func isSchemaList(v any) bool {
+	_, ok := v.([]any)
+	return ok
+}
+
+// schemaCoerce interprets v as the JSON Schema type typ, accepting strings
+// that parse as it.
+func schemaCoerce(v any, typ string) (any, bool) {
+	switch typ {
+	case "string":
+		switch v.(type) {
+		case map[string]any, []any, nil:
+			return nil, false
+		}
+		return schemaString(v), true
+	case "number", "integer":
+		var f float64
+		switch n := v.(type) {
+		case float64:
+			f = n
+		case float32:
+			f = float64(n)
+		case int, int8, int16, int32, int64:
+			f = float64(reflect.ValueOf(n).Int())
+		case uint, uint8, uint16, uint32, uint64:
+			f = float64(reflect.ValueOf(n).Uint())
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+			if err != nil {
+				return nil, false
+			}
+			f = parsed
+		default:
+			return nil, false
+		}
+		if typ == "integer" && f != math.Trunc(f) {
+			return nil, false
+		}
+		return f, true
+	case "boolean":
+		switch b := v.(type) {
+		case bool:
+			return b, true
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			return parsed, err == nil
+		}
+		return nil, false
+	case "array":
+		switch l := v.(type) {
+		case []any:
+			return l, true
+		case string:
+			var items []any
+			for _, item := range SplitCSV(l) {
+				items = append(items, item)
+			}
+			return items, true
+		}
+		return nil, false
+	case "object":
+		m, ok := v.(map[string]any)
+		return m, ok
+	case "null":
+		return nil, v == nil
+	}
+	return nil, false
+}
+
+func schemaString(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
@@ -0,0 +1,197 @@
+package envx
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Schema returns a JSON Schema (draft 2020-12) for the values T reads: an
+// object keyed by variable names without the prefix, the shape
+// WithSchemaValidation expects, so platform tooling can check config files
+// in CI before deploy. Properties carry the field's type, default, desc tag
+// and its min, max, oneof and pattern constraints. Required fields and
+// requiredAny groups are required unless they sit in an optional section or
+// one gated by an Enabled field; required tags naming profiles are not.
+func Schema[T any]() ([]byte, error) {
+	t, err := resolveStructType[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	g := schemaGen{properties: make(map[string]any), groups: make(map[string][]string)}
+	g.collect(t, "", false)
+
+	s := map[string]any{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"type":       "object",
+		"properties": g.properties,
+	}
+	if len(g.required) > 0 {
+		s["required"] = g.required
+	}
+	var allOf []any
+	for _, name := range g.groupOrder {
+		var anyOf []any
+		for _, key := range g.groups[name] {
+			anyOf = append(anyOf, map[string]any{"required": []string{key}})
+		}
+		allOf = append(allOf, map[string]any{"anyOf": anyOf})
+	}
+	if len(allOf) > 0 {
+		s["allOf"] = allOf
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+type schemaGen struct {
+	properties map[string]any
+	required   []string
+	groups     map[string][]string
+	groupOrder []string
+}
+
+// collect adds the fields of t; conditional marks fields of sections that
+// may be absent or disabled, whose requirements do not always apply.
+func (g *schemaGen) collect(t reflect.Type, path string, conditional bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if isSection(field.Type) {
+			g.collect(field.Type, path+fieldName(field)+"_", conditional || hasEnabledField(field.Type))
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			g.collect(field.Type.Elem(), path+fieldName(field)+"_", true)
+			continue
+		}
+
+		key := path + fieldName(field)
+		g.properties[key] = fieldSchema(field)
+		if conditional {
+			continue
+		}
+		if field.Tag.Get("required") == "true" {
+			g.required = append(g.required, key)
+		}
+		if name := field.Tag.Get("requiredAny"); name != "" {
+			if _, ok := g.groups[name]; !ok {
+				g.groupOrder = append(g.groupOrder, name)
+			}
+			g.groups[name] = append(g.groups[name], key)
+		}
+	}
+}
+
+func hasEnabledField(t reflect.Type) bool {
+	f, ok := t.FieldByName("Enabled")
+	return ok && f.Type.Kind() == reflect.Bool
+}
+
+func fieldSchema(field reflect.StructField) map[string]any {
+	s := typeSchema(field.Type)
+	if desc := field.Tag.Get("desc"); desc != "" {
+		s["description"] = desc
+	}
+	if def, ok := field.Tag.Lookup("default"); ok {
+		s["default"] = schemaValue(s, def)
+	}
+	if oneof := field.Tag.Get("oneof"); oneof != "" {
+		target := s
+		if items, ok := s["items"].(map[string]any); ok {
+			target = items
+		}
+		var enum []any
+		for _, v := range strings.Fields(oneof) {
+			enum = append(enum, schemaValue(target, v))
+		}
+		target["enum"] = enum
+	}
+	if pattern := field.Tag.Get("pattern"); pattern != "" && s["type"] == "string" {
+		s["pattern"] = pattern
+	}
+
+	for _, bound := range []struct{ tag, number, length, items string }{
+		{"min", "minimum", "minLength", "minItems"},
+		{"max", "maximum", "maxLength", "maxItems"},
+	} {
+		tag := field.Tag.Get(bound.tag)
+		if tag == "" {
+			continue
+		}
+		switch s["type"] {
+		case "integer", "number":
+			if n, err := strconv.ParseFloat(tag, 64); err == nil {
+				s[bound.number] = n
+			}
+		case "string":
+			if n, err := strconv.Atoi(tag); err == nil && field.Type != durationType {
+				s[bound.length] = n
+			}
+		case "array":
+			if n, err := strconv.Atoi(tag); err == nil {
+				s[bound.items] = n
+			}
+		}
+	}
+	return s
+}
+
+// typeSchema maps t to a JSON type as the parser reads it: value structs,
+// durations and text unmarshalers are strings, slices are arrays.
+func typeSchema(t reflect.Type) map[string]any {
+	if t == urlType || t == urlPtrType || t == urlListType || t == durationType ||
+		isTextUnmarshaler(t) || t.Kind() == reflect.Pointer && t.Implements(textUnmarshalerType) {
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	}
+	return map[string]any{"type": "string"}
+}
+
+// schemaValue converts a tag value to the JSON type of s, leaving it a
+// string when it does not parse.
+func schemaValue(s map[string]any, v string) any {
+	switch s["type"] {
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	case "array":
+		items, _ := s["items"].(map[string]any)
+		out := []any{}
+		for _, item := range splitCSV(v) {
+			out = append(out, schemaValue(items, strings.TrimSpace(item)))
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,390 @@
+//go:build !envx_minimal && !envx_no_secretsmanager
+
+package envx
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	registerSubsystem("secretsmanager")
+	RegisterResolver("awssecrets", resolveSecretsManagerRef)
+	RegisterResolver("awsssm", resolveSSMRef)
+}
+
+type secretsManagerProvider struct {
+	ids      []string
+	region   string
+	endpoint string
+	creds    *awsCredentials
+	chain    *awsCredentialChain
+	client   *http.Client
+
+	mu       sync.Mutex
+	versions map[string]string
+}
+
+// SecretsManagerOption configures a SecretsManager provider.
+type SecretsManagerOption func(*secretsManagerProvider)
+
+// SecretsManagerRegion sets the AWS region. It defaults to AWS_REGION or
+// AWS_DEFAULT_REGION.
+func SecretsManagerRegion(region string) SecretsManagerOption {
+	return func(p *secretsManagerProvider) {
+		p.region = region
+	}
+}
+
+// SecretsManagerCredentials sets static credentials. Without it, each
+// request looks credentials up again: AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN, then the ECS container
+// endpoint, then the EC2 instance role.
+func SecretsManagerCredentials(accessKey, secretKey, sessionToken string) SecretsManagerOption {
+	return func(p *secretsManagerProvider) {
+		p.creds = &awsCredentials{accessKey: accessKey, secretKey: secretKey, sessionToken: sessionToken}
+	}
+}
+
+// SecretsManagerEndpoint replaces the regional endpoint, e.g. for a VPC
+// endpoint or LocalStack.
+func SecretsManagerEndpoint(endpoint string) SecretsManagerOption {
+	return func(p *secretsManagerProvider) {
+		p.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// SecretsManagerHTTPClient sets the HTTP client used to call the API. The
+// default client times out after 30 seconds.
+func SecretsManagerHTTPClient(c *http.Client) SecretsManagerOption {
+	return func(p *secretsManagerProvider) {
+		p.client = c
+	}
+}
+
+// SecretsManager returns a provider that reads secrets holding JSON objects
+// from AWS Secrets Manager and flattens them like a JSON file; later secrets
+// override earlier ones. Requests are signed with SigV4. The provider
+// implements ChangeDetector by comparing version IDs, so WithWatchProvider
+// picks up rotations; it asks DescribeSecret for them and never downloads
+// the secret values to do so.
+func SecretsManager(secretIDs []string, opts ...SecretsManagerOption) Provider {
+	p := &secretsManagerProvider{
+		ids:    secretIDs,
+		region: os.Getenv("AWS_REGION"),
+		chain:  &awsCredentialChain{client: &http.Client{Timeout: 5 * time.Second}},
+		client: &http.Client{Timeout: remoteTimeout},
+	}
+	if p.region == "" {
+		p.region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *secretsManagerProvider) Name() string { return "secretsmanager" }
+
+func (p *secretsManagerProvider) StrictKeys() bool { return true }
+
+func (p *secretsManagerProvider) Describe() ProviderInfo {
+	return ProviderInfo{Source: strings.Join(p.ids, ","), Secret: true}
+}
+
+func (p *secretsManagerProvider) Values() (map[string]any, error) {
+	return p.ValuesContext(context.Background())
+}
+
+func (p *secretsManagerProvider) ValuesContext(ctx context.Context) (map[string]any, error) {
+	values := make(map[string]any)
+	versions := make(map[string]string, len(p.ids))
+	for _, id := range p.ids {
+		var secret secretValue
+		if err := p.call(ctx, "GetSecretValue", id, &secret); err != nil {
+			return nil, err
+		}
+		var raw map[string]any
+		if err := json.Unmarshal([]byte(secret.SecretString), &raw); err != nil {
+			return nil, fmt.Errorf("envx: secretsmanager %s: secret is not a JSON object: %w", id, err)
+		}
+		flattenMap("", raw, values)
+		versions[id] = secret.VersionID
+	}
+
+	p.mu.Lock()
+	p.versions = versions
+	p.mu.Unlock()
+	return values, nil
+}
+
+// Changed reports whether any secret has a new current version since the
+// last Values call.
+func (p *secretsManagerProvider) Changed() (bool, error) {
+	return p.ChangedContext(context.Background())
+}
+
+func (p *secretsManagerProvider) ChangedContext(ctx context.Context) (bool, error) {
+	p.mu.Lock()
+	versions := p.versions
+	p.mu.Unlock()
+
+	for _, id := range p.ids {
+		var desc struct {
+			VersionIDsToStages map[string][]string `json:"VersionIdsToStages"`
+		}
+		if err := p.call(ctx, "DescribeSecret", id, &desc); err != nil {
+			return false, err
+		}
+		current := ""
+		for version, stages := range desc.VersionIDsToStages {
+			if slices.Contains(stages, "AWSCURRENT") {
+				current = version
+			}
+		}
+		if current != versions[id] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type secretValue struct {
+	SecretString string `json:"SecretString"`
+	VersionID    string `json:"VersionId"`
+}
+
+// credentials returns the static credentials, or looks them up again.
+func (p *secretsManagerProvider) credentials(ctx context.Context) (awsCredentials, error) {
+	if p.creds != nil {
+		return *p.creds, nil
+	}
+	return p.chain.retrieve(ctx)
+}
+
+// call invokes the Secrets Manager action on the secret id and decodes the
+// response into out.
+func (p *secretsManagerProvider) call(ctx context.Context, action, id string, out any) error {
+	if p.region == "" && p.endpoint == "" {
+		return fmt.Errorf("envx: secretsmanager: no region")
+	}
+	creds, err := p.credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("envx: secretsmanager %s: %w", id, err)
+	}
+	endpoint := p.endpoint
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + p.region + ".amazonaws.com"
+	}
+
+	body, _ := json.Marshal(map[string]string{"SecretId": id})
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager."+action)
+	signV4(req, body, creds, p.region, "secretsmanager", time.Now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("envx: secretsmanager %s: %w", id, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("envx: secretsmanager %s: %w", id, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Type != "" {
+			return fmt.Errorf("envx: secretsmanager %s: %s: %s", id, apiErr.Type, apiErr.Message)
+		}
+		return fmt.Errorf("envx: secretsmanager %s: unexpected status %s", id, resp.Status)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("envx: secretsmanager %s: %w", id, err)
+	}
+	return nil
+}
+
+// signV4 signs req with AWS Signature Version 4, covering the host, the
+// x-amz-* headers and the content type.
+func signV4(req *http.Request, body []byte, creds awsCredentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, vals := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(vals, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+creds.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		vals := append([]string(nil), q[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape percent-encodes everything but the RFC 3986 unreserved
+// characters, as SigV4 requires.
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// resolveSecretsManagerRef reads ref+awssecrets://<secret-id>, with the
+// region and credentials SecretsManager defaults to.
+func resolveSecretsManagerRef(ref *url.URL) (string, error) {
+	id := strings.TrimPrefix(ref.Host+ref.Path, "/")
+	p := SecretsManager([]string{id}, awsRefOptions(ref)...).(*secretsManagerProvider)
+	var secret secretValue
+	err := p.call(context.Background(), "GetSecretValue", id, &secret)
+	return secret.SecretString, err
+}
+
+// resolveSSMRef reads the SecureString or String parameter named by
+// ref+awsssm:///<name>, decrypted.
+func resolveSSMRef(ref *url.URL) (string, error) {
+	name := ref.Path
+	if ref.Host != "" {
+		name = ref.Host + ref.Path
+	}
+	p := SecretsManager(nil, awsRefOptions(ref)...).(*secretsManagerProvider)
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_SSM")
+	if endpoint == "" {
+		if p.region == "" {
+			return "", fmt.Errorf("awsssm: no region")
+		}
+		endpoint = "https://ssm." + p.region + ".amazonaws.com"
+	}
+
+	creds, err := p.credentials(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("awsssm: %w", err)
+	}
+	body, _ := json.Marshal(map[string]any{"Name": name, "WithDecryption": true})
+	req, err := http.NewRequest("POST", strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AmazonSSM.GetParameter")
+	signV4(req, body, creds, p.region, "ssm", time.Now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("awsssm: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("awsssm: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("awsssm: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	var out struct {
+		Parameter struct {
+			Value string `json:"Value"`
+		} `json:"Parameter"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("awsssm: %w", err)
+	}
+	return out.Parameter.Value, nil
+}
+
+// awsRefOptions reads a ?region= query parameter and the standard
+// AWS_ENDPOINT_URL_SECRETS_MANAGER override.
+func awsRefOptions(ref *url.URL) []SecretsManagerOption {
+	var opts []SecretsManagerOption
+	if region := ref.Query().Get("region"); region != "" {
+		opts = append(opts, SecretsManagerRegion(region))
+	}
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL_SECRETS_MANAGER"); endpoint != "" {
+		opts = append(opts, SecretsManagerEndpoint(endpoint))
+	}
+	return opts
+}
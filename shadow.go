@@ -0,0 +1,28 @@
+package envx
+
+// Shadow loads the configuration from the providers in opts instead of the
+// Loader's own and returns how it differs from the live configuration,
+// without applying it. The Loader's other options, such as WithPrefix and
+// validators, and its runtime overrides still apply, so a migration (e.g.
+// from environment variables to a config service) can be checked against
+// production before switching: no changes means the new chain is a drop-in
+// replacement. Shadow does not block loads and reloads while it runs.
+func (l *Loader[T]) Shadow(opts ...Option) ([]Change, error) {
+	l.mu.RLock()
+	live := l.config
+	base := append([]Option(nil), l.opts...)
+	overrides := l.loadOptions()[len(l.opts):]
+	l.mu.RUnlock()
+
+	if live == nil {
+		return nil, ErrNotLoaded
+	}
+
+	base = append(base, func(o *options) { o.providers = nil })
+	base = append(base, opts...)
+	_, cfg, err := loadInternal[T](append(base, overrides...)...)
+	if err != nil {
+		return nil, err
+	}
+	return diffConfigs(live, cfg), nil
+}
@@ -0,0 +1,65 @@
+package envx
+
+import (
+	"maps"
+	"path/filepath"
+)
+
+// Sources reports where each value of the current configuration came
+// from, keyed by variable name without the prefix as Print shows it:
+// "default" for struct defaults, the base name of a file such as ".env" or
+// "config.json", "env", "override", or another provider's name followed by
+// the source it describes, e.g. "http https://cfg.internal/app.json". Keys
+// no source set are left out. Pass it to PrintSources to annotate Print.
+func (l *Loader[T]) Sources() map[string]string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return maps.Clone(l.sources)
+}
+
+// captureSources returns the source labels of the last load for the keys
+// T reads. The caller holds l.mu.
+func (l *Loader[T]) captureSources() map[string]string {
+	t, err := resolveStructType[T]()
+	if err != nil {
+		return nil
+	}
+	prefix := prepareOptions[T](l.opts).prefix
+	sources := make(map[string]string)
+	for _, key := range configKeys(t, "") {
+		full := key
+		if prefix != "" {
+			full = prefix + "_" + key
+		}
+		if src, ok := l.cache.origin[full]; ok {
+			sources[key] = src
+		}
+	}
+	return sources
+}
+
+// sourceLabel names p for Loader.Sources: files by base name, struct
+// defaults as "default" and other providers by name, followed by the source
+// they describe, if any.
+func sourceLabel(p Provider) string {
+	if isDefaultsProvider(p) {
+		return "default"
+	}
+	name := providerName(p)
+	info := describeProvider(p)
+	switch {
+	case info.Source == "":
+		return name
+	case isFileProvider(p):
+		return filepath.Base(info.Source)
+	}
+	return name + " " + info.Source
+}
+
+func isFileProvider(p Provider) bool {
+	if _, ok := providerAs[*fileProvider](p); ok {
+		return true
+	}
+	_, ok := providerAs[*envFileProvider](p)
+	return ok
+}
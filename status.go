@@ -0,0 +1,65 @@
+package envx
+
+import "time"
+
+// WatchStatus reports the state of one watched source.
+type WatchStatus struct {
+	// Source is the watched file's path, or the provider's described
+	// source and name, e.g. "oci registry.example.com/app:prod".
+	Source      string
+	Interval    time.Duration
+	LastChecked time.Time
+	LastChanged time.Time
+	// Err is the error of the last check, if it failed.
+	Err error
+}
+
+type watchState struct {
+	status WatchStatus
+}
+
+// Status reports every source watched since StartWatching, files first, in
+// registration order. It is empty when nothing is watched.
+func (l *Loader[T]) Status() []WatchStatus {
+	l.statusMu.Lock()
+	defer l.statusMu.Unlock()
+
+	if len(l.status) == 0 {
+		return nil
+	}
+	out := make([]WatchStatus, len(l.status))
+	for i, s := range l.status {
+		out[i] = s.status
+	}
+	return out
+}
+
+func watchSource(p Provider) string {
+	info := describeProvider(p)
+	if info.Source == "" {
+		return info.Name
+	}
+	return info.Name + " " + info.Source
+}
+
+func (l *Loader[T]) trackWatch(source string, interval time.Duration) *watchState {
+	state := &watchState{status: WatchStatus{Source: source, Interval: interval}}
+	l.statusMu.Lock()
+	l.status = append(l.status, state)
+	l.statusMu.Unlock()
+	return state
+}
+
+// recordCheck notes a check of a watched source. A change is recorded
+// before the reload it triggers runs.
+func (l *Loader[T]) recordCheck(state *watchState, changed bool, err error) {
+	now := time.Now()
+	l.statusMu.Lock()
+	defer l.statusMu.Unlock()
+
+	state.status.LastChecked = now
+	state.status.Err = err
+	if changed {
+		state.status.LastChanged = now
+	}
+}
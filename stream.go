@@ -0,0 +1,196 @@
+package envx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+// streamThreshold is the file size above which JSON files are decoded token
+// by token, so the raw document and its decoded tree are never held in
+// memory together.
+var streamThreshold int64 = 4 << 20
+
+// streamJSONFile decodes a JSON file straight into flattened keys, applying
+// the same encoding checks as a file read whole.
+func streamJSONFile(path string) (map[string]any, []string, error) {
+	f, err := os.Open(path)
+	if err != nil && os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	head, _ := br.Peek(4)
+	if err := checkByteOrderMark(head); err != nil {
+		return nil, nil, &Error{Field: path, Err: err}
+	}
+	var notes []string
+	if bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}) {
+		br.Discard(3)
+		notes = append(notes, path+": stripped UTF-8 byte order mark")
+	}
+
+	dec := json.NewDecoder(&textReader{r: br})
+	values := make(map[string]any)
+	err = streamObject(dec, values)
+	if err == nil {
+		if _, tokErr := dec.Token(); tokErr != io.EOF {
+			err = errors.New("invalid character after top-level value")
+			if tokErr != nil {
+				err = tokErr
+			}
+		}
+	}
+	if errors.Is(err, ErrEncoding) {
+		return nil, nil, &Error{Field: path, Err: err}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return values, notes, nil
+}
+
+// streamObject flattens the top-level object in dec into out as flattenMap
+// does.
+func streamObject(dec *json.Decoder, out map[string]any) error {
+	tok, err := dec.Token()
+	if err != nil || tok == nil {
+		return err
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("json: cannot unmarshal %v into a map", tok)
+	}
+	return streamMembers(dec, "", out)
+}
+
+// streamMembers flattens the members of an object whose opening brace has
+// already been read.
+func streamMembers(dec *json.Decoder, prefix string, out map[string]any) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := toScreamingSnake(tok.(string))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok == json.Delim('{') {
+			if err := streamMembers(dec, key, out); err != nil {
+				return err
+			}
+			continue
+		}
+		val, err := tokenValue(dec, tok)
+		if err != nil {
+			return err
+		}
+		out[key] = val
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// tokenValue decodes the value starting with tok into the types
+// json.Unmarshal produces for an any.
+func tokenValue(dec *json.Decoder, tok json.Token) (any, error) {
+	switch tok {
+	case json.Delim('['):
+		items := []any{}
+		for dec.More() {
+			next, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			item, err := tokenValue(dec, next)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err := dec.Token()
+		return items, err
+	case json.Delim('{'):
+		obj := make(map[string]any)
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			next, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			val, err := tokenValue(dec, next)
+			if err != nil {
+				return nil, err
+			}
+			obj[key.(string)] = val
+		}
+		_, err := dec.Token()
+		return obj, err
+	}
+	return tok, nil
+}
+
+// textReader rejects NUL bytes and invalid UTF-8 as they are read, carrying
+// a rune split across reads over to the next one.
+type textReader struct {
+	r       io.Reader
+	offset  int64
+	pending []byte
+}
+
+func (t *textReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if n > 0 {
+		if checkErr := t.check(p[:n]); checkErr != nil {
+			return 0, checkErr
+		}
+	}
+	if err == io.EOF && len(t.pending) > 0 {
+		return n, fmt.Errorf("%w: invalid UTF-8 at offset %d", ErrEncoding, t.offset-int64(len(t.pending)))
+	}
+	return n, err
+}
+
+func (t *textReader) check(chunk []byte) error {
+	start := t.offset - int64(len(t.pending))
+	data := append(t.pending, chunk...)
+	t.offset += int64(len(chunk))
+
+	i := 0
+	for i < len(data) {
+		if data[i] < utf8.RuneSelf {
+			if data[i] == 0 {
+				return fmt.Errorf("%w: NUL byte at offset %d, file looks binary", ErrEncoding, start+int64(i))
+			}
+			i++
+			continue
+		}
+		if !utf8.FullRune(data[i:]) {
+			break
+		}
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size == 1 {
+			return fmt.Errorf("%w: invalid UTF-8 at offset %d", ErrEncoding, start+int64(i))
+		}
+		i += size
+	}
+	t.pending = append(t.pending[:0], data[i:]...)
+	return nil
+}
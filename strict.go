@@ -0,0 +1,139 @@
+package envx
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// strictKeys is implemented by providers whose keys WithStrict checks.
+// The environment and defaults always carry keys the configuration does not
+// read, so only document-like sources, such as File, Map and the remote
+// providers, declare StrictKeys.
+type strictKeys interface {
+	StrictKeys() bool
+}
+
+func checksKeys(p Provider) bool {
+	s, ok := providerAs[strictKeys](p)
+	return ok && s.StrictKeys()
+}
+
+// WithStrict rejects keys from document-like providers, those whose
+// StrictKeys method returns true, that do not map to any field, so a typo
+// such as DATABSE_URL in config.json fails the load instead of silently
+// doing nothing.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithOnUnknownKey calls fn for every key a document-like provider sets
+// that does not map to any field. Without WithStrict the load continues, so fn
+// can be used to warn about such keys.
+func WithOnUnknownKey(fn func(key, source string)) Option {
+	return func(o *options) {
+		o.onUnknownKey = fn
+	}
+}
+
+// knownKeys lists the keys T reads, including those set through shared
+// blocks named by inherit tags. It is nil unless unknown keys are checked.
+func knownKeys[T any](o *options, inherits map[string][]string) map[string]bool {
+	if !o.strict && o.onUnknownKey == nil {
+		return nil
+	}
+	t, err := resolveStructType[T]()
+	if err != nil {
+		return nil
+	}
+
+	prefix := o.prefix
+	known := make(map[string]bool)
+	for _, k := range configKeys(t, "") {
+		if prefix != "" {
+			k = prefix + "_" + k
+		}
+		known[k] = true
+	}
+	for common, instances := range inherits {
+		for _, instance := range instances {
+			for k := range known {
+				if suffix, ok := strings.CutPrefix(k, instance); ok {
+					known[common+suffix] = true
+				}
+			}
+		}
+	}
+	return known
+}
+
+// checkUnknownKeys reports the keys of values that are not in known.
+func checkUnknownKeys(values map[string]any, known map[string]bool, source string, o *options) []error {
+	if known == nil {
+		return nil
+	}
+
+	var unknown []string
+	for k := range values {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+
+	var errs []error
+	for _, k := range unknown {
+		if o.onUnknownKey != nil {
+			var panicErr error
+			func() {
+				defer recoverPanic("OnUnknownKey", &panicErr)
+				o.onUnknownKey(k, source)
+			}()
+			if panicErr != nil {
+				errs = append(errs, panicErr)
+			}
+		}
+		if !o.strict {
+			continue
+		}
+		msg := fmt.Sprintf("%s sets a key that maps to no field", source)
+		if near := closestKey(k, known); near != "" {
+			msg += fmt.Sprintf(", did you mean %s?", near)
+		}
+		errs = append(errs, &Error{Field: k, Err: fmt.Errorf("%w: %s", ErrUnknownKey, msg)})
+	}
+	return errs
+}
+
+// closestKey returns the known key within two edits of key, if any.
+func closestKey(key string, known map[string]bool) string {
+	best, bestDist := "", 3
+	for k := range known {
+		if d := editDistance(key, k); d < bestDist || d == bestDist && k < best {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
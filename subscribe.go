@@ -0,0 +1,58 @@
+package envx
+
+// ChangeEvent describes one applied reload.
+type ChangeEvent[T any] struct {
+	Old     *T
+	New     *T
+	Version int64
+}
+
+// subscriberBuffer is how many events a subscriber may fall behind by
+// before the oldest are dropped.
+const subscriberBuffer = 8
+
+// Subscribe returns a channel that receives an event for every reload the
+// Loader applies, after the WithOnReload callback is started, and a function
+// that cancels the subscription and closes the channel. Reloads never wait
+// for subscribers: one that falls more than a few events behind loses the
+// oldest ones, so compare Version to detect gaps.
+func (l *Loader[T]) Subscribe() (<-chan ChangeEvent[T], func()) {
+	ch := make(chan ChangeEvent[T], subscriberBuffer)
+
+	l.subMu.Lock()
+	if l.subscribers == nil {
+		l.subscribers = make(map[chan ChangeEvent[T]]struct{})
+	}
+	l.subscribers[ch] = struct{}{}
+	l.subMu.Unlock()
+
+	cancel := func() {
+		l.subMu.Lock()
+		defer l.subMu.Unlock()
+		if _, ok := l.subscribers[ch]; ok {
+			delete(l.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel
+}
+
+func (l *Loader[T]) publish(ev ChangeEvent[T]) {
+	l.subMu.Lock()
+	defer l.subMu.Unlock()
+
+	for ch := range l.subscribers {
+		select {
+		case ch <- ev:
+			continue
+		default:
+		}
+		// Full: drop the oldest event. Only publish sends, under subMu,
+		// so there is room afterwards.
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- ev
+	}
+}
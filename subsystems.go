@@ -0,0 +1,31 @@
+package envx
+
+import "sort"
+
+var subsystems []string
+
+func registerSubsystem(name string) {
+	subsystems = append(subsystems, name)
+}
+
+// Subsystems lists the optional integrations compiled into this binary:
+// "blob", "etcd", "fleet", "git", "http", "metadata", "oci", "opa",
+// "secretsmanager" and "vault".
+// Each can be left out with the build tag envx_no_<name>, or all of them with
+// envx_minimal, for binaries that must not carry network clients; code using
+// a missing one fails to compile.
+func Subsystems() []string {
+	out := append([]string(nil), subsystems...)
+	sort.Strings(out)
+	return out
+}
+
+// HasSubsystem reports whether the named integration is compiled in.
+func HasSubsystem(name string) bool {
+	for _, s := range subsystems {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package envx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WithStartupSummary logs, after the first successful load, one logfmt
+// line per section instead of the full configuration:
+//
+//	envx: config section=DATABASE fields=3 non_default=2 overridden=DATABASE_HOST,DATABASE_PORT sources=defaults,env fingerprint=5d41402abc4b2a76
+//
+// overridden lists the keys whose value differs from their default tag (or
+// the zero value) and sources the providers that set a key of the section.
+// Values are never logged, so the lines are safe to ship to a log
+// aggregator. The fingerprint covers the section's own fields, with secrets
+// contributing their key only, and changes whenever one of them does.
+// Reloads are not summarized.
+func WithStartupSummary(logger Logger) Option {
+	return func(o *options) {
+		o.summaryLogger = logger
+	}
+}
+
+// summarize marks a load as the first one of a Load call or a Loader, the
+// one WithStartupSummary reports.
+func summarize(o *options) {
+	o.summarize = true
+}
+
+type sectionSummary struct {
+	name       string
+	fields     int
+	overridden []string
+	sources    []string
+	kvs        []keyValue
+}
+
+// logStartupSummary writes the WithStartupSummary lines for cfg. sources
+// maps each loaded key to the name of the provider that set it.
+func logStartupSummary[T any](o *options, cfg *T, sources map[string]string) {
+	var defaults T
+	dv := reflect.ValueOf(&defaults).Elem()
+	applyTagDefaults(dv, dv.Type(), "")
+
+	v := reflect.ValueOf(cfg).Elem()
+	var sections []*sectionSummary
+	summarizeSection(&sections, "root", v, dv, v.Type(), "", o, sources)
+
+	for _, s := range sections {
+		if s.fields == 0 {
+			continue
+		}
+		line := "envx: config section=" + s.name + " fields=" + strconv.Itoa(s.fields) +
+			" non_default=" + strconv.Itoa(len(s.overridden))
+		if len(s.overridden) > 0 {
+			line += " overridden=" + strings.Join(s.overridden, ",")
+		}
+		if len(s.sources) > 0 {
+			line += " sources=" + strings.Join(s.sources, ",")
+		}
+		o.summaryLogger.Printf("%s fingerprint=%s\n", line, sectionFingerprint(s.kvs))
+	}
+}
+
+func summarizeSection(out *[]*sectionSummary, name string, v, def reflect.Value, t reflect.Type, path string, o *options, sources map[string]string) {
+	s := &sectionSummary{name: name}
+	*out = append(*out, s)
+
+	providerRank := make(map[string]int, len(o.providers))
+	for i, p := range o.providers {
+		if _, ok := providerRank[providerName(p)]; !ok {
+			providerRank[providerName(p)] = i
+		}
+	}
+	used := make(map[string]bool)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv, dfv := v.Field(i), def.Field(i)
+
+		if isSection(field.Type) {
+			summarizeSection(out, path+fieldName(field), fv, dfv, field.Type, path+fieldName(field)+"_", o, sources)
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			if fv.IsNil() {
+				continue
+			}
+			section := reflect.New(field.Type.Elem()).Elem()
+			applyTagDefaults(section, field.Type.Elem(), "")
+			summarizeSection(out, path+fieldName(field), fv.Elem(), section, field.Type.Elem(), path+fieldName(field)+"_", o, sources)
+			continue
+		}
+
+		key := path + fieldName(field)
+		kv := keyValue{key: key, value: formatValue(fv), field: field}
+		s.kvs = append(s.kvs, kv)
+		s.fields++
+		if kv.value != formatValue(dfv) {
+			s.overridden = append(s.overridden, key)
+		}
+
+		sourceKey := key
+		if o.prefix != "" {
+			sourceKey = o.prefix + "_" + key
+		}
+		if name, ok := sources[sourceKey]; ok && !used[name] {
+			used[name] = true
+			s.sources = append(s.sources, name)
+		}
+	}
+
+	sort.SliceStable(s.sources, func(i, j int) bool {
+		return providerRank[s.sources[i]] < providerRank[s.sources[j]]
+	})
+}
+
+func sectionFingerprint(kvs []keyValue) string {
+	h := sha256.New()
+	for _, kv := range kvs {
+		val := kv.value
+		if isSecret(kv.field) {
+			val = "<secret>"
+		}
+		h.Write([]byte(kv.key + "=" + val + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,187 @@
+package envx
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// validateTags enforces the min, max, oneof and pattern tags. Zero values no
+// source set are skipped so optional fields may stay unset; combine with
+// required to demand a value. An explicit WORKERS=0 or LEVEL= is checked.
+func validateTags(cfg any, values map[string]any, prefix string) error {
+	v := reflect.ValueOf(cfg).Elem()
+	return checkTags(v, v.Type(), "", values, prefix)
+}
+
+func checkTags(v reflect.Value, t reflect.Type, path string, values map[string]any, prefix string) error {
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+
+		if isSection(field.Type) {
+			errs = appendErrors(errs, checkTags(fv, field.Type, path+fieldName(field)+"_", values, prefix))
+			continue
+		}
+		if isOptionalSection(field.Type) {
+			if fv.IsNil() {
+				continue
+			}
+			errs = appendErrors(errs, checkTags(fv.Elem(), field.Type.Elem(), path+fieldName(field)+"_", values, prefix))
+			continue
+		}
+
+		if isZero(fv) && !valueSet(values, prefix, path+fieldName(field)) {
+			continue
+		}
+		if err := checkFieldTags(field, fv); err != nil {
+			errs = append(errs, &Error{Field: path + fieldName(field), Err: fmt.Errorf("%w: %v", ErrValidation, err)})
+		}
+	}
+	return joinErrors(errs)
+}
+
+// valueSet reports whether a source provided the key at path.
+func valueSet(values map[string]any, prefix, path string) bool {
+	if prefix != "" {
+		path = prefix + "_" + path
+	}
+	return values[path] != nil
+}
+
+func checkFieldTags(field reflect.StructField, fv reflect.Value) error {
+	secret := isSecret(field)
+	if tag := field.Tag.Get("min"); tag != "" {
+		if err := checkBound(fv, tag, true, secret); err != nil {
+			return err
+		}
+	}
+	if tag := field.Tag.Get("max"); tag != "" {
+		if err := checkBound(fv, tag, false, secret); err != nil {
+			return err
+		}
+	}
+
+	items := []reflect.Value{fv}
+	if fv.Kind() == reflect.Slice && !isTextUnmarshaler(fv.Type()) {
+		items = items[:0]
+		for i := 0; i < fv.Len(); i++ {
+			items = append(items, fv.Index(i))
+		}
+	}
+
+	if tag := field.Tag.Get("oneof"); tag != "" {
+		allowed := strings.Fields(tag)
+		for _, item := range items {
+			s := formatValue(item)
+			ok := false
+			for _, a := range allowed {
+				if s == a {
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				return fmt.Errorf("%s is not one of %s", shownValue(s, true, secret), strings.Join(allowed, ", "))
+			}
+		}
+	}
+
+	if tag := field.Tag.Get("pattern"); tag != "" {
+		re, err := compilePattern(tag)
+		if err != nil {
+			return fmt.Errorf("invalid pattern tag %q: %v", tag, err)
+		}
+		for _, item := range items {
+			if s := formatValue(item); !re.MatchString(s) {
+				return fmt.Errorf("%s does not match %s", shownValue(s, true, secret), tag)
+			}
+		}
+	}
+	return nil
+}
+
+// checkBound compares numbers and durations by value, and strings, slices
+// and maps by length.
+func checkBound(fv reflect.Value, tag string, isMin, secret bool) error {
+	name, cmp := "max", func(a, b float64) bool { return a <= b }
+	if isMin {
+		name, cmp = "min", func(a, b float64) bool { return a >= b }
+	}
+
+	var got, limit float64
+	var err error
+	switch {
+	case fv.Type() == reflect.TypeOf(time.Duration(0)):
+		var d time.Duration
+		d, err = time.ParseDuration(tag)
+		got, limit = float64(fv.Int()), float64(d)
+	case fv.CanInt():
+		got = float64(fv.Int())
+		limit, err = strconv.ParseFloat(tag, 64)
+	case fv.CanUint():
+		got = float64(fv.Uint())
+		limit, err = strconv.ParseFloat(tag, 64)
+	case fv.CanFloat():
+		got = fv.Float()
+		limit, err = strconv.ParseFloat(tag, 64)
+	case fv.Kind() == reflect.String:
+		got = float64(utf8.RuneCountInString(fv.String()))
+		limit, err = strconv.ParseFloat(tag, 64)
+	case fv.Kind() == reflect.Slice || fv.Kind() == reflect.Map:
+		got = float64(fv.Len())
+		limit, err = strconv.ParseFloat(tag, 64)
+	default:
+		return fmt.Errorf("%s tag not supported for %s", name, fv.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s tag %q: %v", name, tag, err)
+	}
+	if cmp(got, limit) {
+		return nil
+	}
+
+	if fv.Kind() == reflect.String || fv.Kind() == reflect.Slice || fv.Kind() == reflect.Map {
+		if isMin {
+			return fmt.Errorf("length %v is less than %s", got, tag)
+		}
+		return fmt.Errorf("length %v is greater than %s", got, tag)
+	}
+	val := shownValue(formatValue(fv), false, secret)
+	if isMin {
+		return fmt.Errorf("%s is less than %s", val, tag)
+	}
+	return fmt.Errorf("%s is greater than %s", val, tag)
+}
+
+// shownValue is s as a tag message shows it. A secret reads as "value", as
+// messages reach logs, the event log and the admin page.
+func shownValue(s string, quoted, secret bool) string {
+	switch {
+	case secret:
+		return "value"
+	case quoted:
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+var patternCache sync.Map
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,83 @@
+package envx
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Template writes a commented .env.example for T: every variable it reads,
+// set to its default, under comments with its desc tag and whether it is
+// required. Options such as WithPrefix shape the keys as they would for a
+// load. Generating the file keeps it in sync with the struct.
+func Template[T any](w io.Writer, opts ...Option) error {
+	t, err := resolveStructType[T]()
+	if err != nil {
+		return err
+	}
+	o := prepareOptions[T](opts)
+
+	var buf bytes.Buffer
+	writeTemplate(&buf, t, "", o.prefix)
+	_, err = w.Write(append(bytes.TrimRight(buf.Bytes(), "\n"), '\n'))
+	return err
+}
+
+func writeTemplate(buf *bytes.Buffer, t reflect.Type, path, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if isSection(field.Type) || isOptionalSection(field.Type) {
+			st := field.Type
+			title := field.Name
+			if isOptionalSection(st) {
+				st = st.Elem()
+				title += " (optional section)"
+			}
+			buf.WriteString("# " + title + "\n")
+			writeComment(buf, field.Tag.Get("desc"))
+			buf.WriteString("\n")
+			writeTemplate(buf, st, path+fieldName(field)+"_", prefix)
+			continue
+		}
+
+		key := path + fieldName(field)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		writeComment(buf, field.Tag.Get("desc"))
+		if note := requiredNote(field); note != "" {
+			buf.WriteString("# " + note + "\n")
+		}
+		buf.WriteString(key + "=" + quoteDotEnvValue(field.Tag.Get("default")) + "\n\n")
+	}
+}
+
+func writeComment(buf *bytes.Buffer, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		buf.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+	}
+}
+
+// requiredNote describes the required and requiredAny tags of field.
+func requiredNote(field reflect.StructField) string {
+	var notes []string
+	switch tag := field.Tag.Get("required"); tag {
+	case "", "false":
+	case "true":
+		notes = append(notes, "required")
+	default:
+		notes = append(notes, "required in "+strings.Join(splitTagList(tag), ", "))
+	}
+	if group := field.Tag.Get("requiredAny"); group != "" {
+		notes = append(notes, "set at least one of the "+group+" group")
+	}
+	return strings.Join(notes, "; ")
+}
@@ -0,0 +1,119 @@
+package envx
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// URLList is a comma-separated list of absolute URLs. Restrict the allowed
+// schemes with a schemes tag, e.g. `schemes:"http,https"`, which also
+// applies to url.URL and *url.URL fields.
+type URLList []*url.URL
+
+var (
+	urlListType = reflect.TypeOf(URLList(nil))
+	urlType     = reflect.TypeOf(url.URL{})
+	urlPtrType  = reflect.TypeOf((*url.URL)(nil))
+)
+
+func (l URLList) String() string {
+	items := make([]string, len(l))
+	for i, u := range l {
+		items[i] = u.String()
+	}
+	return strings.Join(items, ",")
+}
+
+// MarshalText encodes the list in the same comma-separated form it is
+// parsed from.
+func (l URLList) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func setURLList(fv reflect.Value, val any) error {
+	items, err := normalizeSliceInput(val)
+	if err != nil {
+		return err
+	}
+
+	list := make(URLList, 0, len(items))
+	for _, item := range items {
+		s := strings.TrimSpace(fmt.Sprint(item))
+		if s == "" {
+			continue
+		}
+		u, err := parseAbsURL(s)
+		if err != nil {
+			return err
+		}
+		list = append(list, u)
+	}
+	fv.Set(reflect.ValueOf(list))
+	return nil
+}
+
+// setURL decodes a url.URL or *url.URL field.
+func setURL(fv reflect.Value, val any) error {
+	u, err := parseAbsURL(strings.TrimSpace(fmt.Sprint(val)))
+	if err != nil {
+		return err
+	}
+	if fv.Type() == urlPtrType {
+		fv.Set(reflect.ValueOf(u))
+	} else {
+		fv.Set(reflect.ValueOf(*u))
+	}
+	return nil
+}
+
+func parseAbsURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsAbs() || (u.Host == "" && u.Opaque == "" && u.Path == "") {
+		return nil, fmt.Errorf("%q is not an absolute URL", s)
+	}
+	return u, nil
+}
+
+// fieldURLs returns the URLs held by a URLList, url.URL or *url.URL field.
+func fieldURLs(fv reflect.Value) []*url.URL {
+	switch fv.Type() {
+	case urlListType:
+		return fv.Interface().(URLList)
+	case urlType:
+		u := fv.Interface().(url.URL)
+		return []*url.URL{&u}
+	case urlPtrType:
+		if !fv.IsNil() {
+			return []*url.URL{fv.Interface().(*url.URL)}
+		}
+	}
+	return nil
+}
+
+// checkSchemes enforces the schemes tag on URL fields.
+func checkSchemes(field reflect.StructField, fv reflect.Value) error {
+	tag := field.Tag.Get("schemes")
+	if tag == "" {
+		return nil
+	}
+
+	allowed := splitTagList(tag)
+	for _, u := range fieldURLs(fv) {
+		ok := false
+		for _, scheme := range allowed {
+			if strings.EqualFold(u.Scheme, scheme) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s: scheme %q not in %s", ErrValidation, u, u.Scheme, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
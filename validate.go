@@ -0,0 +1,98 @@
+package envx
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Validate checks the loader's options for inconsistencies that would
+// otherwise only surface at runtime, such as a watch path that no File
+// provider reads or callbacks registered for a different config type. It
+// reports every problem found; each wraps ErrInvalidOptions.
+func (l *Loader[T]) Validate() error {
+	return validateOptions[T](prepareOptions[T](l.opts))
+}
+
+func validateOptions[T any](o *options) error {
+	errs := append([]error(nil), o.errs...)
+	invalid := func(option, format string, args ...any) {
+		errs = append(errs, &Error{Field: option, Err: fmt.Errorf("%w: "+format, append([]any{ErrInvalidOptions}, args...)...)})
+	}
+
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	if o.onReloadType != nil && o.onReloadType != target {
+		invalid("WithOnReload", "callback expects %s, loader loads %s", o.onReloadType, target)
+	}
+	if o.validatorType != nil && o.validatorType != target {
+		invalid("WithValidator", "validator expects %s, loader loads %s", o.validatorType, target)
+	}
+	for _, fc := range o.fieldChanges {
+		if fc.typ != target {
+			invalid("WithOnFieldChange", "selector expects %s, loader loads %s", fc.typ, target)
+		}
+	}
+
+	for _, w := range o.watches {
+		if w.interval <= 0 {
+			invalid("WithWatch", "interval must be greater than zero, got %s", w.interval)
+		}
+		if problem := watchPathProblem(o, w.path); problem != "" {
+			invalid("WithWatch", "%s", problem)
+		}
+	}
+
+	for _, w := range o.watchProviders {
+		if w.interval <= 0 {
+			invalid("WithWatchProvider", "interval must be greater than zero, got %s", w.interval)
+		}
+	}
+
+	if target.Kind() == reflect.Struct {
+		checkRemovalTags(target, "", invalid)
+	}
+
+	if o.reloadDebounce < 0 {
+		invalid("WithReloadDebounce", "window must not be negative, got %s", o.reloadDebounce)
+	}
+
+	if o.reloadLimit > 0 && o.reloadPer <= 0 {
+		invalid("WithMaxReloadRate", "period must be greater than zero, got %s", o.reloadPer)
+	}
+
+	names := make(map[string]bool, len(o.providers))
+	for _, p := range o.providers {
+		names[providerName(p)] = true
+	}
+	for _, name := range o.precedence {
+		if !names[name] {
+			invalid("WithPrecedence", "no provider named %q", name)
+		}
+	}
+
+	return joinErrors(errs)
+}
+
+// watchPathProblem describes why path is watched but not read by any File
+// provider, or returns "" if one reads it.
+func watchPathProblem(o *options, path string) string {
+	watched, err := filepath.Abs(path)
+	if err != nil {
+		watched = filepath.Clean(path)
+	}
+
+	var paths []string
+	for _, p := range o.providers {
+		if fp, ok := providerAs[*fileProvider](p); ok {
+			if fp.path == watched {
+				return ""
+			}
+			paths = append(paths, fp.path)
+		}
+	}
+	if len(paths) == 0 {
+		return fmt.Sprintf("%s is watched but no File provider is registered", path)
+	}
+	return fmt.Sprintf("%s is watched but File providers read %s", path, strings.Join(paths, ", "))
+}
@@ -0,0 +1,64 @@
+//go:build !envx_minimal && !envx_no_vault
+
+package envx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerSubsystem("vault")
+	RegisterResolver("vault", resolveVaultRef)
+}
+
+var vaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// resolveVaultRef reads ref+vault://<mount>/<path> from the Vault server at
+// VAULT_ADDR with VAULT_TOKEN (and VAULT_NAMESPACE, if set). KV version 2
+// secrets are unwrapped, so #key selects a field of the secret itself.
+func resolveVaultRef(ref *url.URL) (string, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		addr = "http://127.0.0.1:8200"
+	}
+	req, err := http.NewRequest("GET", strings.TrimSuffix(addr, "/")+"/v1/"+ref.Host+ref.Path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	resp, err := vaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault: unexpected status %s", resp.Status)
+	}
+
+	var secret struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	if inner, ok := secret.Data["data"]; ok && secret.Data["metadata"] != nil {
+		return string(inner), nil
+	}
+	data, err := json.Marshal(secret.Data)
+	return string(data), err
+}
@@ -0,0 +1,85 @@
+package envx
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// reloadWindow is a daily wall-clock interval in a fixed location. An end
+// before the start wraps past midnight.
+type reloadWindow struct {
+	start TimeOfDay
+	end   TimeOfDay
+	loc   *time.Location
+}
+
+// WithReloadWindow only applies hot reloads during the daily window, given
+// as "02:00-04:00" in loc (time.Local if nil); "22:00-02:00" spans midnight.
+// Changes detected outside the window are queued and applied, with the
+// latest values, when it next opens. The initial Load is not restricted.
+func WithReloadWindow(window string, loc *time.Location) Option {
+	return func(o *options) {
+		w, err := parseReloadWindow(window, loc)
+		if err != nil {
+			o.errs = append(o.errs, &Error{Field: "WithReloadWindow", Err: fmt.Errorf("%w: %v", ErrInvalidOptions, err)})
+			return
+		}
+		o.reloadWindow = w
+	}
+}
+
+func parseReloadWindow(window string, loc *time.Location) (*reloadWindow, error) {
+	from, to, ok := strings.Cut(window, "-")
+	if !ok {
+		return nil, fmt.Errorf("window %q, want HH:MM-HH:MM", window)
+	}
+	start, err := ParseTimeOfDay(strings.TrimSpace(from))
+	if err != nil {
+		return nil, err
+	}
+	end, err := ParseTimeOfDay(strings.TrimSpace(to))
+	if err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, fmt.Errorf("window %q is empty", window)
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return &reloadWindow{start: start, end: end, loc: loc}, nil
+}
+
+// delay returns how long until the window opens, or zero if now is inside
+// it. Boundaries follow the wall clock across DST changes.
+func (w *reloadWindow) delay(now time.Time) time.Duration {
+	t := now.In(w.loc)
+	start, end := wallTime(w.start, t), wallTime(w.end, t)
+
+	if end.After(start) {
+		if !t.Before(start) && t.Before(end) {
+			return 0
+		}
+	} else if !t.Before(start) || t.Before(end) {
+		return 0
+	}
+
+	next := start
+	if !t.Before(start) {
+		next = wallTime(w.start, t.AddDate(0, 0, 1))
+	}
+	return next.Sub(now)
+}
+
+// wallTime is tod on day's date. A time skipped by a DST gap, which
+// time.Date resolves to before the gap, is moved forward by the gap.
+func wallTime(tod TimeOfDay, day time.Time) time.Time {
+	t := tod.On(day)
+	want := time.Duration(tod.Hour)*time.Hour + time.Duration(tod.Minute)*time.Minute + time.Duration(tod.Second)*time.Second
+	got := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if got < want {
+		t = t.Add(want - got)
+	}
+	return t
+}